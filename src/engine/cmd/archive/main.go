@@ -0,0 +1,91 @@
+// archive queries the game archive written by the engine (ENGINE_ARCHIVE_FILE)
+// and prints JSON, or serves the same query API over HTTP.
+//
+//	go run ./cmd/archive -db games.db -winner mafia -players 7
+//	go run ./cmd/archive -db games.db -model bot:random -from 2025-01-01 -limit 10
+//	go run ./cmd/archive -db games.db -game game-abc123 [-events]
+//	go run ./cmd/archive -db games.db -serve :8090
+//
+// bbolt locks the file: while an engine has the archive open, query the
+// engine's ENGINE_ARCHIVE_ADDR instead.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"log"
+	"net/http"
+	"net/url"
+	"os"
+	"strconv"
+	"time"
+
+	"mafia-engine/internal/archive"
+)
+
+func main() {
+	db := flag.String("db", os.Getenv("ENGINE_ARCHIVE_FILE"), "archive file (default $ENGINE_ARCHIVE_FILE)")
+	serve := flag.String("serve", "", "serve the HTTP query API on this address instead of printing")
+	gameID := flag.String("game", "", "print this game")
+	withEvents := flag.Bool("events", false, "with -game, print its events instead")
+	winner := flag.String("winner", "", "only games won by this faction")
+	players := flag.Int("players", 0, "only games with this many players")
+	model := flag.String("model", "", "only games with a seat played by this model profile")
+	from := flag.String("from", "", "only games started at or after this date or RFC 3339 time")
+	to := flag.String("to", "", "only games started before this date or RFC 3339 time")
+	limit := flag.Int("limit", 0, "at most this many games, newest first")
+	flag.Parse()
+
+	if *db == "" {
+		fatalf("no archive file, use -db or ENGINE_ARCHIVE_FILE")
+	}
+	store, err := archive.Open(*db)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	defer store.Close()
+
+	if *serve != "" {
+		server := &http.Server{Addr: *serve, Handler: archive.Handler(store), ReadHeaderTimeout: 5 * time.Second}
+		log.Printf("serving %s on %s", *db, *serve)
+		if err := server.ListenAndServe(); err != nil {
+			store.Close()
+			fatalf("%v", err)
+		}
+		return
+	}
+
+	var out any
+	switch {
+	case *gameID != "" && *withEvents:
+		out, err = store.Events(*gameID)
+	case *gameID != "":
+		out, err = store.Get(*gameID)
+	default:
+		// the same parsing as the HTTP API
+		values := url.Values{"winner": {*winner}, "model": {*model}, "from": {*from}, "to": {*to}}
+		values.Set("players", strconv.Itoa(*players))
+		values.Set("limit", strconv.Itoa(*limit))
+		var q archive.Query
+		if q, err = archive.ParseQuery(values); err == nil {
+			out, err = store.Find(q)
+		}
+	}
+	if err != nil {
+		store.Close()
+		fatalf("%v", err)
+	}
+
+	encoder := json.NewEncoder(os.Stdout)
+	encoder.SetIndent("", "  ")
+	if err := encoder.Encode(out); err != nil {
+		store.Close()
+		fatalf("%v", err)
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "archive: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,127 @@
+// balance searches role distributions for fair games and writes them as a ruleset.
+//
+//	go run ./cmd/balance -games 300 -out rulesets/balanced.json
+//
+// Every player count in ENGINE_GAME_MIN_PLAYERS..ENGINE_GAME_MAX_PLAYERS is
+// analyzed; load the output with ENGINE_RULESET_FILE.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"sort"
+	"strings"
+
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/sim"
+)
+
+// rulesetFile is the ruleset plus the evidence behind it.
+// ParseRuleset ignores the extra field, so the file loads as is.
+type rulesetFile struct {
+	*domain.Ruleset
+	Balance map[int]*sim.Setup `json:"balance"`
+}
+
+func main() {
+	games := flag.Int("games", 200, "games per candidate setup")
+	seed := flag.Int64("seed", 1, "seed of the first game of every candidate")
+	minPlayers := flag.Int("min", 0, "smallest player count (0 = ENGINE_GAME_MIN_PLAYERS)")
+	maxPlayers := flag.Int("max", 0, "largest player count (0 = ENGINE_GAME_MAX_PLAYERS)")
+	strategies := flag.String("strategies", "", "comma separated reference bots (default ENGINE_BOT_STRATEGIES)")
+	name := flag.String("name", "balanced", "name of the generated ruleset")
+	out := flag.String("out", "", "write the ruleset to this file (default stdout)")
+	all := flag.Bool("all", false, "print every candidate, not just the recommendation")
+	verbose := flag.Bool("v", false, "keep engine logs")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("failed to load config: %v", err)
+	}
+	if *minPlayers == 0 {
+		*minPlayers = cfg.GameMinPlayers
+	}
+	if *maxPlayers == 0 {
+		*maxPlayers = cfg.GameMaxPlayers
+	}
+	strategyNames := cfg.BotStrategies
+	if *strategies != "" {
+		strategyNames = strings.Split(*strategies, ",")
+	}
+
+	report, err := sim.AnalyzeBalance(cfg, sim.BalanceOptions{
+		MinPlayers:    *minPlayers,
+		MaxPlayers:    *maxPlayers,
+		GamesPerSetup: *games,
+		Seed:          *seed,
+		Strategies:    strategyNames,
+	})
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	printReport(os.Stderr, report, *all)
+
+	ruleset := report.Ruleset(*name)
+	if err := ruleset.Validate(); err != nil {
+		fatalf("generated ruleset is invalid: %v", err)
+	}
+	data, err := json.MarshalIndent(rulesetFile{Ruleset: ruleset, Balance: report.Recommended}, "", "  ")
+	if err != nil {
+		fatalf("failed to encode ruleset: %v", err)
+	}
+	data = append(data, '\n')
+
+	if *out == "" {
+		_, _ = os.Stdout.Write(data)
+		return
+	}
+	if err := os.WriteFile(*out, data, 0o644); err != nil {
+		fatalf("failed to write ruleset: %v", err)
+	}
+	fmt.Fprintf(os.Stderr, "ruleset written to %s\n", *out)
+}
+
+func printReport(w io.Writer, report *sim.BalanceReport, all bool) {
+	counts := make([]int, 0, len(report.Candidates))
+	for n := range report.Candidates {
+		counts = append(counts, n)
+	}
+	sort.Ints(counts)
+
+	current := domain.GetRoleDistribution
+	for _, n := range counts {
+		def := current(n)
+		fmt.Fprintf(w, "%2d players (current: %d mafia, %d doctor, %d sheriff)\n",
+			n, def[domain.RoleMafia], def[domain.RoleDoctor], def[domain.RoleSheriff])
+
+		for _, setup := range report.Candidates[n] {
+			recommended := setup == report.Recommended[n]
+			if !all && !recommended {
+				continue
+			}
+			marker := " "
+			if recommended {
+				marker = "*"
+			}
+			fmt.Fprintf(w, "  %s %d mafia, %d doctor, %d sheriff: mafia wins %5.1f%% [%5.1f%%, %5.1f%%] over %d games\n",
+				marker, setup.Roles.Mafia, setup.Roles.Doctor, setup.Roles.Sheriff,
+				100*setup.MafiaWinRate, 100*setup.Low, 100*setup.High, setup.Completed)
+		}
+	}
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "balance: "+format+"\n", args...)
+	os.Exit(1)
+}
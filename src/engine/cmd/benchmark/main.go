@@ -0,0 +1,118 @@
+// benchmark plays a benchmark spec (see benchmarks/): the same seeds with
+// the baseline models and with one candidate model swapped into one seat,
+// and reports the paired differences with their significance.
+//
+// Benchmarks of built-in bots are played headlessly:
+//
+//	go run ./cmd/benchmark benchmarks/sheriff-follower-vs-random.json
+//	go run ./cmd/benchmark -json report.json benchmarks/sheriff-follower-vs-random.json
+//
+// Benchmarks with agents (e.g. LLM model profiles) are played on live engines.
+// -plan prints the engine settings of every game, one line per game; each
+// seat is reserved for its model profile. Archive the games
+// (ENGINE_ARCHIVE_FILE) and compare them with -db:
+//
+//	go run ./cmd/benchmark -plan benchmarks/llama-vs-random.json
+//	go run ./cmd/benchmark -db games.db benchmarks/llama-vs-random.json
+//
+// Engine settings (name pack, traits, ...) come from the usual ENGINE_* environment.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/benchmark"
+	"mafia-engine/internal/config"
+)
+
+func main() {
+	out := flag.String("json", "", "write the JSON report, with every pair, to this file (- for stdout)")
+	plan := flag.Bool("plan", false, "print the engine settings of every game instead of playing")
+	db := flag.String("db", "", "compare the games archived in this file instead of playing")
+	verbose := flag.Bool("v", false, "keep engine logs")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: benchmark [flags] spec.json\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() != 1 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("failed to load config: %v", err)
+	}
+	spec, err := benchmark.Load(flag.Arg(0))
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	if *plan {
+		games, err := benchmark.Plan(spec)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		for _, game := range games {
+			env := game.Env()
+			if spec.RulesetFile != "" {
+				env = append(env, "ENGINE_RULESET_FILE="+spec.RulesetFile)
+			}
+			fmt.Println(strings.Join(env, " "))
+		}
+		return
+	}
+
+	var report *benchmark.Report
+	if *db != "" {
+		store, err := archive.Open(*db)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		report, err = benchmark.Score(store, spec)
+		store.Close()
+		if err != nil {
+			fatalf("%v", err)
+		}
+	} else if report, err = benchmark.Run(cfg, spec); err != nil {
+		fatalf("%v", err)
+	}
+	if *out != "-" {
+		report.WriteText(os.Stdout)
+	}
+	if *out != "" {
+		if err := writeJSON(*out, report); err != nil {
+			fatalf("failed to write report: %v", err)
+		}
+	}
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "benchmark: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,223 @@
+// branch forks a recorded game at one of its commands and plays out the
+// alternative with bots: "what if the doctor had saved Alice on night 2".
+//
+//	go run ./cmd/branch -db games.db -game game-abc123 -list
+//	go run ./cmd/branch -db games.db -game game-abc123 -at 41 \
+//	    -override '{"kind":"night_action","player_id":"player-3","role":"doctor","target":"player-5"}'
+//	go run ./cmd/branch -recording game.jsonl -at 57 -insert -override '{"kind":"vote","player_id":"player-2","target":"player-6"}'
+//
+// The journal comes from the archive (the engine keeps it with every game) or
+// is rebuilt by replaying a recording (ENGINE_RECORD_FILE). -list prints the
+// numbered commands to pick a fork point from. Overrides are journal entries
+// (see engine.JournalEntry); repeat -override to change several decisions.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/replay"
+	"mafia-engine/internal/sim"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var overrides stringList
+	db := flag.String("db", os.Getenv("ENGINE_ARCHIVE_FILE"), "archive file (default $ENGINE_ARCHIVE_FILE)")
+	gameID := flag.String("game", "", "archived game to fork")
+	recording := flag.String("recording", "", "fork a recorded game instead of an archived one")
+	list := flag.Bool("list", false, "print the game's commands and exit")
+	at := flag.Int("at", -1, "index of the command to change (see -list)")
+	insert := flag.Bool("insert", false, "apply the overrides before the command at -at instead of replacing it")
+	runs := flag.Int("runs", 100, "games to play for the branch and for the recorded game")
+	seed := flag.Int64("seed", 1, "bot seed of the first run, run i uses seed+i")
+	strategies := flag.String("strategies", "", "comma separated bot strategies (default ENGINE_BOT_STRATEGIES)")
+	maxRounds := flag.Int("max-rounds", sim.DefaultMaxRounds, "stop games that last longer than this")
+	out := flag.String("out", "", "write the JSON report to this file (- for stdout)")
+	verbose := flag.Bool("v", false, "keep engine logs")
+	flag.Var(&overrides, "override", "journal entry (JSON) to apply at -at, repeatable")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	journal, err := loadJournal(*db, *gameID, *recording)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	if *list {
+		if err := printCommands(os.Stdout, journal); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
+
+	if *at < 0 || len(overrides) == 0 {
+		fatalf("pick a fork point with -at and at least one -override (see -list)")
+	}
+	override := make([]engine.Command, 0, len(overrides))
+	for _, raw := range overrides {
+		var entry engine.JournalEntry
+		if err := json.Unmarshal([]byte(raw), &entry); err != nil {
+			fatalf("invalid -override %s: %v", raw, err)
+		}
+		cmd, err := entry.Command()
+		if err != nil {
+			fatalf("invalid -override %s: %v", raw, err)
+		}
+		override = append(override, cmd)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("failed to load config: %v", err)
+	}
+	strategyNames := cfg.BotStrategies
+	if *strategies != "" {
+		strategyNames = strings.Split(*strategies, ",")
+	}
+
+	report, err := sim.CompareBranch(sim.Branch{
+		Config:     cfg,
+		Journal:    journal,
+		At:         *at,
+		Override:   override,
+		Insert:     *insert,
+		Strategies: strategyNames,
+		Runs:       *runs,
+		Seed:       *seed,
+		MaxRounds:  *maxRounds,
+	})
+	if err != nil {
+		fatalf("%v", err)
+	}
+	report.WriteText(os.Stdout)
+
+	if *out != "" {
+		if err := writeJSON(*out, report); err != nil {
+			fatalf("failed to write report: %v", err)
+		}
+	}
+}
+
+// loadJournal reads the game's journal from the archive, or rebuilds it from a recording.
+func loadJournal(db, gameID, recording string) (*engine.Journal, error) {
+	if recording != "" {
+		rec, err := replay.Load(recording)
+		if err != nil {
+			return nil, err
+		}
+		result, err := replay.Replay(rec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", recording, err)
+		}
+		if !result.OK() {
+			// the journal follows the current engine, not the recorded one
+			fmt.Fprintf(os.Stderr, "branch: warning: %s replays with %d mismatches\n", recording, len(result.Mismatches))
+		}
+		return result.Journal, nil
+	}
+
+	if db == "" || gameID == "" {
+		return nil, fmt.Errorf("pick a game with -db and -game, or a -recording")
+	}
+	store, err := archive.Open(db)
+	if err != nil {
+		return nil, err
+	}
+	defer store.Close()
+
+	journal, err := store.Journal(gameID)
+	if err != nil {
+		return nil, fmt.Errorf("journal of game %s: %w", gameID, err)
+	}
+	return journal, nil
+}
+
+// printCommands lists the journal with the round and phase each command was applied in.
+func printCommands(w io.Writer, journal *engine.Journal) error {
+	entries, err := journal.Entries()
+	if err != nil {
+		return err
+	}
+	state := journal.Initial()
+	if state == nil {
+		return fmt.Errorf("journal has no initial state")
+	}
+
+	for i, cmd := range journal.Commands() {
+		fmt.Fprintf(w, "%4d  %-7s round %-2d %s\n", i, state.Phase, state.Round, describe(entries[i], state))
+		if _, err := cmd.Apply(state); err != nil {
+			return fmt.Errorf("replaying command %d (%T): %w", i, cmd, err)
+		}
+	}
+	return nil
+}
+
+// describe prints an entry with player names next to their IDs.
+func describe(entry engine.JournalEntry, state *domain.GameState) string {
+	who := func(id string) string {
+		if player := state.GetPlayer(id); player != nil {
+			return fmt.Sprintf("%s (%s)", player.Name, id)
+		}
+		return id
+	}
+
+	parts := []string{entry.Kind}
+	switch {
+	case entry.Player != nil:
+		parts = append(parts, fmt.Sprintf("%s (%s)", entry.Player.Name, entry.Player.ID))
+	case entry.PlayerID != "":
+		parts = append(parts, who(entry.PlayerID))
+	}
+	if entry.Role != "" {
+		parts = append(parts, "as "+entry.Role)
+	}
+	if entry.TargetID != "" {
+		parts = append(parts, "-> "+who(entry.TargetID))
+	}
+	if entry.Phase != "" {
+		parts = append(parts, "-> "+entry.Phase)
+	}
+	if entry.Text != "" {
+		parts = append(parts, fmt.Sprintf("%q", entry.Text))
+	}
+	return strings.Join(parts, " ")
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "branch: "+format+"\n", args...)
+	os.Exit(1)
+}
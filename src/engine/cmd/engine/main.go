@@ -2,17 +2,24 @@ package main
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"log"
+	"net/http"
 	"os"
 	"os/signal"
 	"syscall"
 	"time"
 
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/clock"
 	"mafia-engine/internal/config"
 	"mafia-engine/internal/domain"
 	"mafia-engine/internal/engine"
 	"mafia-engine/internal/kafka"
+	"mafia-engine/internal/replay"
+	"mafia-engine/internal/telemetry"
 )
 
 func main() {
@@ -29,16 +36,19 @@ func main() {
 	log.Printf("Starting Mafia Engine with config: brokers=%v, topic=%s, groupID=%s, maxPlayers=%d",
 		cfg.KafkaBrokers, kafka.PlayerActionsTopic, cfg.KafkaGroupID, cfg.GameMaxPlayers)
 
+	// Select the transport: a real Kafka cluster or the in-process broker
+	transport := newTransport(cfg)
+	log.Printf("Transport: %s", cfg.KafkaTransport)
+
 	// Create Kafka producer for publishing authoritative events
-	producer, err := kafka.NewKafkaProducer(cfg.KafkaBrokers, cfg.KafkaClientID)
+	producer, err := transport.newProducer()
 	if err != nil {
 		log.Fatalf("Failed to create Kafka producer: %v", err)
 	}
 	log.Printf("Kafka producer created for topic: %s", kafka.EngineEventsTopic)
 
 	// Create Kafka consumer for receiving player actions
-	consumer, err := kafka.NewKafkaConsumer(
-		cfg.KafkaBrokers,
+	consumer, err := transport.newConsumer(
 		// kafka-go limitation: a consumer can only subscribe to a single topic
 		// alternative in kafka-go is to use 'GroupTopics' (read more about this)
 		// a good practice IS to use a single consumer for a single topic anyway
@@ -55,22 +65,101 @@ func main() {
 
 	// Initialize game state with configuration
 	// Start in Waiting phase (players can join)
+	// A fixed ENGINE_GAME_SEED makes roles and traits reproducible
+	seed := cfg.GameSeed
+	if seed == 0 {
+		seed = domain.NewSeed()
+	}
 	gameState := &domain.GameState{
 		ID:      domain.CreateGameID(cfg.GameIDPrefix),
+		Seed:    seed,
 		Phase:   domain.PhaseWaiting,
 		Round:   0,
 		Winner:  domain.WinnerNone,
 		Players: make(map[string]*domain.Player),
 		Votes:   make(map[string]string),
 	}
-	log.Printf("Game state initialized: id=%s, phase=%s", gameState.ID, gameState.Phase)
+	log.Printf("Game state initialized: id=%s, phase=%s, seed=%d", gameState.ID, gameState.Phase, gameState.Seed)
+
+	// Optionally record the game for cmd/replay. The engine's producer and
+	// message handler are tapped; a nil recorder leaves them untouched.
+	var recorder *replay.Recorder
+	var recordFile *os.File
+	if cfg.RecordFile != "" {
+		recordFile, err = os.Create(cfg.RecordFile)
+		if err != nil {
+			log.Fatalf("Failed to create record file: %v", err)
+		}
+		recorder, err = replay.NewRecorder(recordFile, gameState, cfg, clock.Real{})
+		if err != nil {
+			log.Fatalf("Failed to start recording: %v", err)
+		}
+		log.Printf("Recording game to %s", cfg.RecordFile)
+	}
+
+	// Optionally archive the finished game for analysis. Like the recorder,
+	// the archiver taps the engine's producer; a nil archiver leaves it untouched.
+	var archiver *archive.Archiver
+	var archiveStore *archive.Store
+	var archiveServer *http.Server
+	if cfg.ArchiveFile != "" {
+		archiveStore, err = archive.Open(cfg.ArchiveFile)
+		if err != nil {
+			log.Fatalf("Failed to open archive: %v", err)
+		}
+		archiver, err = archive.NewArchiver(archiveStore, gameState, cfg)
+		if err != nil {
+			log.Fatalf("Failed to start archiving: %v", err)
+		}
+		log.Printf("Archiving game to %s", cfg.ArchiveFile)
+
+		// bbolt locks the file, so the archive is queried through this process
+		if cfg.ArchiveAddr != "" {
+			archiveServer = &http.Server{
+				Addr:              cfg.ArchiveAddr,
+				Handler:           archive.Handler(archiveStore),
+				ReadHeaderTimeout: cfg.HTTPTimeout,
+			}
+			go func() {
+				if err := archiveServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+					log.Printf("Archive server error: %v", err)
+				}
+			}()
+			log.Printf("Archive query API listening on %s", cfg.ArchiveAddr)
+		}
+	}
+
+	// Agent telemetry goes to the archive and, optionally, a metrics endpoint
+	usage := telemetry.NewRecorder()
+	archiver.AddTelemetry(usage)
+	var metricsServer *http.Server
+	if cfg.MetricsAddr != "" {
+		mux := http.NewServeMux()
+		mux.Handle("/metrics", usage.Handler())
+		metricsServer = &http.Server{
+			Addr:              cfg.MetricsAddr,
+			Handler:           mux,
+			ReadHeaderTimeout: cfg.HTTPTimeout,
+		}
+		go func() {
+			if err := metricsServer.ListenAndServe(); err != nil && !errors.Is(err, http.ErrServerClosed) {
+				log.Printf("Metrics server error: %v", err)
+			}
+		}()
+		log.Printf("Metrics listening on %s/metrics", cfg.MetricsAddr)
+	}
+
+	// The command journal goes to the archive, so the game can be forked later (cmd/branch)
+	journal := engine.NewJournal()
+	archiver.AddJournal(journal)
 
 	// Create the game engine
 	// Note: We inject the producer but NOT the consumer.
 	// The Engine is a reactive component that acts when 'HandleMessage' is called.
 	// This "Push" architecture decouples the engine from the transport layer (Kafka),
 	// making it easier to test and swap implementations.
-	eng, err := engine.NewEngine(gameState, producer, cfg)
+	eng, err := engine.NewEngine(gameState, archiver.Producer(recorder.Producer(producer)), cfg,
+		engine.WithTelemetry(usage), engine.WithJournal(journal))
 	// catch error and close interfaces if the engine creation fails
 	if err != nil {
 		if closeErr := consumer.Close(); closeErr != nil {
@@ -83,6 +172,51 @@ func main() {
 	}
 	log.Println("Game engine created")
 
+	// In mock mode the players are in-process strategy bots. Seats reserved
+	// for a bot profile (ENGINE_SEAT_PROFILES) are played by that bot in any
+	// mode, the other reserved seats by agents.
+	// Bots read the engine events with their own consumer group and publish
+	// actions to the player actions topic like any other agent.
+	if cfg.AgentMode == "mock" && len(cfg.BotStrategies) == 0 {
+		log.Fatalf("ENGINE_BOT_STRATEGIES must not be empty in mock mode")
+	}
+	botStrategies := make([]string, cfg.GameMinPlayers) // per seat, "" = played by an agent
+	for i := range botStrategies {
+		switch {
+		case i < len(cfg.SeatProfiles):
+			botStrategies[i], _ = bots.StrategyOf(cfg.SeatProfiles[i])
+		case cfg.AgentMode == "mock":
+			botStrategies[i] = cfg.BotStrategies[i%len(cfg.BotStrategies)]
+		}
+	}
+	strategies := make([]bots.Strategy, len(botStrategies))
+	botSeats := 0
+	for i, name := range botStrategies {
+		if name == "" {
+			continue
+		}
+		strategy, err := bots.NewStrategy(name)
+		if err != nil {
+			log.Fatalf("Failed to create the bot of seat %d: %v", i+1, err)
+		}
+		strategies[i] = strategy
+		botSeats++
+	}
+
+	var botConsumer kafka.Consumer
+	var botRuntime *bots.Runtime
+	if botSeats > 0 {
+		botRuntime, err = bots.NewRuntime(gameState.ID, producer, strategies, gameState.Seed)
+		if err != nil {
+			log.Fatalf("Failed to create bot runtime: %v", err)
+		}
+		botConsumer, err = transport.newConsumer(kafka.EngineEventsTopic, kafka.BotsConsumerGroup)
+		if err != nil {
+			log.Fatalf("Failed to create bot consumer: %v", err)
+		}
+		log.Printf("Bots created: %d seats, strategies by seat=%q", botSeats, botStrategies)
+	}
+
 	// -----------------
 	// Start engine
 	// -----------------
@@ -99,16 +233,30 @@ func main() {
 	// In K8s, the Operator will see this state and spin up the corresponding pods.
 	log.Printf("Bootstrap: Pre-populating game with %d players...", cfg.GameMinPlayers)
 	for i := 0; i < cfg.GameMinPlayers; i++ {
-		if err := eng.AddPlayer(); err != nil {
+		// bots play the seats they are dealt
+		var modelProfile string
+		if botStrategies[i] != "" {
+			modelProfile = bots.Profile(botStrategies[i])
+		}
+		add := eng.AddPlayerWithProfile
+		if i < len(cfg.SeatProfiles) {
+			modelProfile, add = cfg.SeatProfiles[i], eng.AddPlayerWithPinnedProfile
+		}
+		if err := add(modelProfile); err != nil {
 			log.Fatalf("Bootstrap Failed: could not add player %d: %v", i, err)
 		}
+		recorder.AddPlayer()
 	}
 	log.Printf("Bootstrap: Added %d players.", cfg.GameMinPlayers)
 
 	log.Println("Bootstrap: Starting game...")
+	if len(cfg.PinnedRoles) > 0 {
+		log.Printf("Bootstrap: Pinned roles: %v", cfg.PinnedRoles)
+	}
 	if err := eng.StartGame(); err != nil {
 		log.Fatalf("Bootstrap Failed: could not start game: %v", err)
 	}
+	recorder.StartGame()
 	log.Println("Bootstrap: Game started successfully! Check Kafka topics for events.")
 
 	// Create context for coordinating shutdown
@@ -119,12 +267,22 @@ func main() {
 	// This runs in a goroutine and blocks until context is canceled
 	go func() {
 		log.Println("Starting consumer loop...")
-		if err := consumer.Consume(ctx, eng.HandleMessage); err != nil {
+		if err := consumer.Consume(ctx, recorder.Handler(eng.HandleMessage)); err != nil {
 			log.Printf("Consumer error: %v", err)
 			cancel() // Signal shutdown on consumer error
 		}
 	}()
 
+	if botConsumer != nil {
+		go func() {
+			log.Println("Starting bots consumer loop...")
+			if err := botConsumer.Consume(ctx, botRuntime.HandleMessage); err != nil {
+				log.Printf("Bots consumer error: %v", err)
+				cancel()
+			}
+		}()
+	}
+
 	// -----------------
 	// End game
 	// -----------------
@@ -154,11 +312,88 @@ func main() {
 		log.Printf("Error closing consumer: %v", err)
 	}
 
+	if botConsumer != nil {
+		log.Println("Closing bots consumer...")
+		if err := botConsumer.Close(); err != nil {
+			log.Printf("Error closing bots consumer: %v", err)
+		}
+	}
+
 	log.Println("Closing Kafka producer...")
 	if err := producer.Close(); err != nil {
 		log.Printf("Error closing producer: %v", err)
 	}
 
+	if err := transport.close(); err != nil {
+		log.Printf("Error closing transport: %v", err)
+	}
+
+	if recordFile != nil {
+		if err := recorder.Err(); err != nil {
+			log.Printf("Recording incomplete: %v", err)
+		}
+		if err := recordFile.Close(); err != nil {
+			log.Printf("Error closing record file: %v", err)
+		}
+	}
+
+	if metricsServer != nil {
+		if err := metricsServer.Close(); err != nil {
+			log.Printf("Error closing metrics server: %v", err)
+		}
+	}
+
+	if archiveStore != nil {
+		if archiveServer != nil {
+			if err := archiveServer.Close(); err != nil {
+				log.Printf("Error closing archive server: %v", err)
+			}
+		}
+		if err := archiver.Err(); err != nil {
+			log.Printf("Archiving failed: %v", err)
+		}
+		if err := archiveStore.Close(); err != nil {
+			log.Printf("Error closing archive: %v", err)
+		}
+	}
+
 	log.Println("Shutdown complete")
 	fmt.Println("Mafia Engine stopped successfully")
 }
+
+// transport creates producers and consumers for the configured ENGINE_KAFKA_TRANSPORT.
+// With "memory" all of them share one in-process broker, so the engine and the
+// bots talk to each other without a Kafka cluster.
+type transport struct {
+	cfg    *config.Config
+	broker *kafka.MemoryBroker // nil for "kafka"
+}
+
+func newTransport(cfg *config.Config) *transport {
+	t := &transport{cfg: cfg}
+	if cfg.KafkaTransport == "memory" {
+		t.broker = kafka.NewMemoryBroker(kafka.DefaultMemoryPartitions)
+	}
+	return t
+}
+
+func (t *transport) newProducer() (kafka.Producer, error) {
+	if t.broker != nil {
+		return kafka.NewMemoryProducer(t.broker)
+	}
+	return kafka.NewKafkaProducer(t.cfg.KafkaBrokers, t.cfg.KafkaClientID)
+}
+
+func (t *transport) newConsumer(topic, groupID string) (kafka.Consumer, error) {
+	if t.broker != nil {
+		return kafka.NewMemoryConsumer(t.broker, topic, groupID)
+	}
+	return kafka.NewKafkaConsumer(t.cfg.KafkaBrokers, topic, groupID)
+}
+
+func (t *transport) close() error {
+	if t.broker != nil {
+		return t.broker.Close()
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+// export writes per-player training episodes from the game archive
+// (ENGINE_ARCHIVE_FILE) as JSONL, one episode per line: what the player
+// observed, in order and filtered by the live visibility rules, what they did
+// and thought, and the outcome and reward of their faction.
+//
+//	go run ./cmd/export -db games.db -out episodes.jsonl
+//	go run ./cmd/export -db games.db -model bot:sheriff-follower -won -out -
+//
+// The game filters are the archive's (see cmd/archive); -model also keeps
+// only the episodes of that model's seats. bbolt locks the file, so stop the
+// engine writing to it first.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/training"
+)
+
+func main() {
+	db := flag.String("db", os.Getenv("ENGINE_ARCHIVE_FILE"), "archive file (default $ENGINE_ARCHIVE_FILE)")
+	out := flag.String("out", "-", "write the episodes to this file (- for stdout)")
+	winner := flag.String("winner", "", "only games won by this faction")
+	players := flag.Int("players", 0, "only games with this many players")
+	model := flag.String("model", "", "only episodes of seats played by this model profile")
+	from := flag.String("from", "", "only games started at or after this date or RFC 3339 time")
+	to := flag.String("to", "", "only games started before this date or RFC 3339 time")
+	limit := flag.Int("limit", 0, "only the newest games")
+	faction := flag.String("faction", "", "only episodes of players of this faction")
+	won := flag.Bool("won", false, "only episodes of players whose faction won")
+	flag.Parse()
+
+	if *db == "" {
+		fatalf("no archive file, use -db or ENGINE_ARCHIVE_FILE")
+	}
+	values := url.Values{"winner": {*winner}, "model": {*model}, "from": {*from}, "to": {*to}}
+	values.Set("players", strconv.Itoa(*players))
+	values.Set("limit", strconv.Itoa(*limit))
+	q, err := archive.ParseQuery(values)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	filter := training.Filter{Model: *model, Faction: *faction, WonOnly: *won}
+
+	store, err := archive.Open(*db)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	defer store.Close()
+
+	if *out == "-" {
+		if _, err := training.Export(os.Stdout, store, q, filter); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
+	file, err := os.Create(*out) // #nosec G304 -- the output file is chosen by the user
+	if err != nil {
+		fatalf("%v", err)
+	}
+	n, err := training.Export(file, store, q, filter)
+	if closeErr := file.Close(); err == nil {
+		err = closeErr
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+	fmt.Fprintf(os.Stderr, "wrote %d episodes to %s\n", n, *out)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "export: "+format+"\n", args...)
+	os.Exit(1)
+}
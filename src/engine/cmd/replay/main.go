@@ -0,0 +1,109 @@
+// replay plays recorded games (ENGINE_RECORD_FILE) against the current engine
+// and reports where its output differs from the recording.
+//
+//	go run ./cmd/replay game.jsonl
+//	go run ./cmd/replay -max 3 recordings/
+//
+// Directories replay every *.jsonl file in them. The engine configuration comes
+// from the recording, not the environment; a name pack file it used must still
+// exist. Exits with status 1 if any replay differs.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"mafia-engine/internal/replay"
+)
+
+func main() {
+	verbose := flag.Bool("v", false, "keep engine logs")
+	maxShown := flag.Int("max", 5, "mismatches to print per recording (0 = all)")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: replay [-v] [-max n] file-or-dir...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	paths, err := expand(flag.Args())
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	failed := 0
+	for _, path := range paths {
+		rec, err := replay.Load(path)
+		if err != nil {
+			fmt.Printf("ERROR %s\n  %v\n", path, err)
+			failed++
+			continue
+		}
+
+		result, err := replay.Replay(rec)
+		if err != nil {
+			fmt.Printf("ERROR %s\n  %v\n", path, err)
+			failed++
+			continue
+		}
+		if result.OK() {
+			fmt.Printf("ok    %s (%d actions, %d events)\n", path, result.Inbound, result.Recorded)
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL  %s (%d events recorded, %d replayed, %d differ)\n",
+			path, result.Recorded, result.Replayed, len(result.Mismatches))
+		for i, mismatch := range result.Mismatches {
+			if *maxShown > 0 && i == *maxShown {
+				fmt.Printf("  ... %d more\n", len(result.Mismatches)-i)
+				break
+			}
+			fmt.Printf("  %s\n", mismatch)
+		}
+	}
+
+	fmt.Printf("%d recordings, %d failed\n", len(paths), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// expand replaces directories with the recordings in them.
+func expand(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*.jsonl"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "replay: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,107 @@
+// scenario runs scripted game files against the engine and reports failed expectations.
+//
+//	go run ./cmd/scenario scenarios/
+//	go run ./cmd/scenario scenarios/doctor-save.json
+//
+// Directories run every *.json file in them. Phase timeouts come from the
+// usual ENGINE_* environment. Exits with status 1 if any scenario fails.
+package main
+
+import (
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/scenario"
+)
+
+func main() {
+	verbose := flag.Bool("v", false, "keep engine logs")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: scenario [-v] file-or-dir...\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+	if flag.NArg() == 0 {
+		flag.Usage()
+		os.Exit(2)
+	}
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("failed to load config: %v", err)
+	}
+
+	paths, err := expand(flag.Args())
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	failed := 0
+	for _, path := range paths {
+		sc, err := scenario.Load(path)
+		if err != nil {
+			fmt.Printf("ERROR %s\n  %v\n", path, err)
+			failed++
+			continue
+		}
+
+		outcome, err := scenario.Run(cfg, sc)
+		if err != nil {
+			fmt.Printf("ERROR %s (%s)\n  %v\n", sc.Name, path, err)
+			failed++
+			continue
+		}
+		if outcome.Passed() {
+			fmt.Printf("ok    %s (%d events)\n", sc.Name, outcome.Events)
+			continue
+		}
+
+		failed++
+		fmt.Printf("FAIL  %s (%s)\n", sc.Name, path)
+		for _, failure := range outcome.Failures {
+			fmt.Printf("  %s\n", failure)
+		}
+	}
+
+	fmt.Printf("%d scenarios, %d failed\n", len(paths), failed)
+	if failed > 0 {
+		os.Exit(1)
+	}
+}
+
+// expand replaces directories with the scenario files in them.
+func expand(args []string) ([]string, error) {
+	var paths []string
+	for _, arg := range args {
+		info, err := os.Stat(arg)
+		if err != nil {
+			return nil, err
+		}
+		if !info.IsDir() {
+			paths = append(paths, arg)
+			continue
+		}
+		matches, err := filepath.Glob(filepath.Join(arg, "*.json"))
+		if err != nil {
+			return nil, err
+		}
+		sort.Strings(matches)
+		paths = append(paths, matches...)
+	}
+	return paths, nil
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "scenario: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,139 @@
+// simulate plays many games headlessly with the built-in bots and reports outcomes.
+//
+//	go run ./cmd/simulate -games 1000 -ruleset rules/a.json -ruleset rules/b.json -out results.json
+//	go run ./cmd/simulate -games 200 -archive games.db    # then: go run ./cmd/stats -db games.db
+//
+// Engine settings (name pack, traits, ...) come from the usual ENGINE_* environment.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"os"
+	"strings"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/sim"
+)
+
+// stringList is a repeatable string flag.
+type stringList []string
+
+func (l *stringList) String() string { return strings.Join(*l, ",") }
+
+func (l *stringList) Set(value string) error {
+	*l = append(*l, value)
+	return nil
+}
+
+func main() {
+	var rulesets stringList
+	games := flag.Int("games", 1000, "number of games to play")
+	seed := flag.Int64("seed", 0, "seed of the first game, game i uses seed+i (0 = random)")
+	players := flag.Int("players", 0, "players per game (0 = ENGINE_GAME_MIN_PLAYERS)")
+	strategies := flag.String("strategies", "", "comma separated bot strategies (default ENGINE_BOT_STRATEGIES)")
+	maxRounds := flag.Int("max-rounds", sim.DefaultMaxRounds, "stop games that last longer than this")
+	out := flag.String("out", "", "write the JSON results to this file (- for stdout)")
+	perGame := flag.Bool("per-game", false, "include every game's result in the JSON output")
+	archiveFile := flag.String("archive", "", "also store every game in this archive file")
+	verbose := flag.Bool("v", false, "keep engine logs")
+	flag.Var(&rulesets, "ruleset", "ruleset file, repeat to compare rulesets (games are split round-robin)")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("failed to load config: %v", err)
+	}
+	if *players == 0 {
+		*players = cfg.GameMinPlayers
+	}
+	strategyNames := cfg.BotStrategies
+	if *strategies != "" {
+		strategyNames = strings.Split(*strategies, ",")
+	}
+	if *seed == 0 {
+		*seed = domain.NewSeed()
+	}
+	if len(rulesets) == 0 {
+		rulesets = stringList{cfg.RulesetFile} // ENGINE_RULESET_FILE, or default rules if unset
+	}
+
+	var store *archive.Store
+	if *archiveFile != "" {
+		if store, err = archive.Open(*archiveFile); err != nil {
+			fatalf("%v", err)
+		}
+		defer store.Close()
+	}
+
+	results := make([]*sim.Result, 0, *games)
+	for i := 0; i < *games; i++ {
+		result, err := sim.Run(cfg, sim.Game{
+			Seed:        *seed + int64(i),
+			Players:     *players,
+			RulesetFile: rulesets[i%len(rulesets)],
+			Strategies:  strategyNames,
+			MaxRounds:   *maxRounds,
+		})
+		if err != nil {
+			fatalf("game %d (seed %d): %v", i, *seed+int64(i), err)
+		}
+		results = append(results, result)
+
+		if store != nil && result.Completed {
+			game := archive.NewGame(result.State, result.Rules, result.Events)
+			if err := store.Put(game, result.Events); err != nil {
+				store.Close()
+				fatalf("failed to archive game %d: %v", i, err)
+			}
+			if err := store.PutJournal(game.ID, result.Journal); err != nil {
+				store.Close()
+				fatalf("failed to archive game %d: %v", i, err)
+			}
+		}
+	}
+
+	summary := sim.SummarizeByRuleset(results)
+	fmt.Printf("seeds %d..%d, %d players, strategies %s\n", *seed, *seed+int64(*games)-1, *players, strings.Join(strategyNames, ","))
+	summary.WriteText(os.Stdout)
+
+	if *out != "" {
+		output := struct {
+			*sim.Summary
+			Games []*sim.Result `json:"games,omitempty"`
+		}{Summary: summary}
+		if *perGame {
+			output.Games = results
+		}
+		if err := writeJSON(*out, output); err != nil {
+			fatalf("failed to write results: %v", err)
+		}
+	}
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "simulate: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,83 @@
+// stats reports cross-game statistics from the game archive
+// (ENGINE_ARCHIVE_FILE): win rates per faction, role and model profile,
+// lynch accuracy, doctor save rate, sheriff hit rate and survival by seat
+// and trait.
+//
+//	go run ./cmd/stats -db games.db
+//	go run ./cmd/stats -db games.db -players 7 -from 2025-01-01 -json report.json
+//
+// The filters are the archive's (see cmd/archive). bbolt locks the file, so
+// stop the engine writing to it first.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/stats"
+)
+
+func main() {
+	db := flag.String("db", os.Getenv("ENGINE_ARCHIVE_FILE"), "archive file (default $ENGINE_ARCHIVE_FILE)")
+	out := flag.String("json", "", "write the JSON report to this file (- for stdout, instead of the text report)")
+	winner := flag.String("winner", "", "only games won by this faction")
+	players := flag.Int("players", 0, "only games with this many players")
+	model := flag.String("model", "", "only games with a seat played by this model profile")
+	from := flag.String("from", "", "only games started at or after this date or RFC 3339 time")
+	to := flag.String("to", "", "only games started before this date or RFC 3339 time")
+	limit := flag.Int("limit", 0, "only the newest games")
+	flag.Parse()
+
+	if *db == "" {
+		fatalf("no archive file, use -db or ENGINE_ARCHIVE_FILE")
+	}
+	values := url.Values{"winner": {*winner}, "model": {*model}, "from": {*from}, "to": {*to}}
+	values.Set("players", strconv.Itoa(*players))
+	values.Set("limit", strconv.Itoa(*limit))
+	q, err := archive.ParseQuery(values)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	store, err := archive.Open(*db)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	report, err := stats.Build(store, q)
+	store.Close()
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	if *out != "-" {
+		report.WriteText(os.Stdout)
+	}
+	if *out != "" {
+		if err := writeJSON(*out, report); err != nil {
+			fatalf("failed to write report: %v", err)
+		}
+	}
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "stats: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -0,0 +1,222 @@
+// tournament ranks agents by model profile with Elo ratings, kept apart for
+// mafia and village play. Standings are printed after every game.
+//
+// A bot tournament plays the built-in strategies against each other, rotating
+// seats and roles (see tournament.Schedule):
+//
+//	go run ./cmd/tournament -strategies random,sheriff-follower,aggressive-accuser -games 120
+//	go run ./cmd/tournament -games 60 -archive games.db -json ratings.json
+//
+// -schedule plans the same rotation for any model profiles (e.g. LLM agents)
+// without playing: it prints the engine settings of every game, one line per
+// game, to start a live engine with (see tournament.Pairing.Env). Each seat is
+// reserved for its profile; archive the games and rate them with -db:
+//
+//	go run ./cmd/tournament -schedule -profiles llama3.2:1b,llama3.2:3b,bot:random -games 12
+//
+// With -db the games in the archive are rated instead, oldest first: games
+// played by real agents, which declare their model profile when they join
+// (player_joined), ranked the same way:
+//
+//	go run ./cmd/tournament -db games.db -from 2025-01-01
+//
+// Engine settings (name pack, traits, ...) come from the usual ENGINE_* environment.
+package main
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"io"
+	"log"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/sim"
+	"mafia-engine/internal/tournament"
+)
+
+func main() {
+	strategies := flag.String("strategies", "", "comma separated competing bot strategies (default ENGINE_BOT_STRATEGIES)")
+	games := flag.Int("games", 60, "number of games to play")
+	players := flag.Int("players", 0, "players per game (0 = ENGINE_GAME_MIN_PLAYERS)")
+	seed := flag.Int64("seed", 0, "seed of the first game, game i uses seed+i (0 = random)")
+	rulesetFile := flag.String("ruleset", "", "ruleset file (default ENGINE_RULESET_FILE)")
+	maxRounds := flag.Int("max-rounds", sim.DefaultMaxRounds, "stop games that last longer than this")
+	k := flag.Float64("k", tournament.DefaultK, "Elo K factor")
+	archiveFile := flag.String("archive", "", "also store every game in this archive file")
+	schedule := flag.Bool("schedule", false, "print the engine settings of every game instead of playing")
+	profiles := flag.String("profiles", "", "with -schedule, comma separated model profiles (default the bot profiles of -strategies)")
+	db := flag.String("db", "", "rate the games in this archive instead of playing")
+	model := flag.String("model", "", "with -db, only games with a seat played by this model profile")
+	dbPlayers := flag.Int("db-players", 0, "with -db, only games with this many players")
+	from := flag.String("from", "", "with -db, only games started at or after this date or RFC 3339 time")
+	to := flag.String("to", "", "with -db, only games started before this date or RFC 3339 time")
+	out := flag.String("json", "", "write the final ratings as JSON to this file (- for stdout)")
+	quiet := flag.Bool("q", false, "only print the final standings")
+	verbose := flag.Bool("v", false, "keep engine logs")
+	flag.Parse()
+
+	if !*verbose {
+		log.SetOutput(io.Discard)
+	}
+
+	if *schedule {
+		printSchedule(*profiles, *strategies, *games, *players, *seed, *rulesetFile)
+		return
+	}
+
+	var ratings *tournament.Ratings
+	if *db != "" {
+		values := url.Values{"model": {*model}, "from": {*from}, "to": {*to}}
+		values.Set("players", strconv.Itoa(*dbPlayers))
+		q, err := archive.ParseQuery(values)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		store, err := archive.Open(*db)
+		if err != nil {
+			fatalf("%v", err)
+		}
+		ratings, err = tournament.RateArchive(store, q, *k)
+		store.Close()
+		if err != nil {
+			fatalf("%v", err)
+		}
+		ratings.WriteText(os.Stdout)
+	} else {
+		ratings = play(*strategies, *games, *players, *seed, *rulesetFile, *maxRounds, *k, *archiveFile, *quiet)
+	}
+
+	if *out != "" {
+		if err := writeJSON(*out, ratings); err != nil {
+			fatalf("failed to write ratings: %v", err)
+		}
+	}
+}
+
+func play(strategies string, games, players int, seed int64, rulesetFile string, maxRounds int, k float64, archiveFile string, quiet bool) *tournament.Ratings {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("failed to load config: %v", err)
+	}
+	if players == 0 {
+		players = cfg.GameMinPlayers
+	}
+	names := cfg.BotStrategies
+	if strategies != "" {
+		names = strings.Split(strategies, ",")
+	}
+	if seed == 0 {
+		seed = domain.NewSeed()
+	}
+	if rulesetFile == "" {
+		rulesetFile = cfg.RulesetFile
+	}
+
+	var store *archive.Store
+	if archiveFile != "" {
+		if store, err = archive.Open(archiveFile); err != nil {
+			fatalf("%v", err)
+		}
+		defer store.Close()
+	}
+
+	fmt.Printf("seeds %d..%d, %d players, strategies %s\n", seed, seed+int64(games)-1, players, strings.Join(names, ","))
+	ratings, err := tournament.Run(cfg, tournament.Tournament{
+		Strategies:  names,
+		Players:     players,
+		Games:       games,
+		Seed:        seed,
+		RulesetFile: rulesetFile,
+		MaxRounds:   maxRounds,
+		K:           k,
+		Store:       store,
+		AfterGame: func(pairing tournament.Pairing, game *archive.Game, ratings *tournament.Ratings) {
+			if quiet {
+				return
+			}
+			fmt.Printf("\ngame %d (seed %d): %s wins\n", pairing.Game+1, pairing.Seed, game.Winner)
+			ratings.WriteText(os.Stdout)
+		},
+	})
+	if err != nil {
+		if store != nil {
+			store.Close()
+		}
+		fatalf("%v", err)
+	}
+	if quiet {
+		ratings.WriteText(os.Stdout)
+	}
+	return ratings
+}
+
+// printSchedule prints one line of engine settings per scheduled game.
+func printSchedule(profiles, strategies string, games, players int, seed int64, rulesetFile string) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		fatalf("failed to load config: %v", err)
+	}
+	if players == 0 {
+		players = cfg.GameMinPlayers
+	}
+	if seed == 0 {
+		seed = domain.NewSeed()
+	}
+	if rulesetFile == "" {
+		rulesetFile = cfg.RulesetFile
+	}
+	var ruleset *domain.Ruleset
+	if rulesetFile != "" {
+		if ruleset, err = engine.LoadRuleset(rulesetFile); err != nil {
+			fatalf("%v", err)
+		}
+	}
+
+	var names []string
+	if profiles != "" {
+		names = strings.Split(profiles, ",")
+	} else {
+		strategyNames := cfg.BotStrategies
+		if strategies != "" {
+			strategyNames = strings.Split(strategies, ",")
+		}
+		for _, strategy := range strategyNames {
+			names = append(names, bots.Profile(strategy))
+		}
+	}
+
+	pairings, err := tournament.Schedule(names, players, ruleset, games, seed)
+	if err != nil {
+		fatalf("%v", err)
+	}
+	for _, pairing := range pairings {
+		fmt.Println(strings.Join(pairing.Env(), " "))
+	}
+}
+
+func writeJSON(path string, v any) error {
+	data, err := json.MarshalIndent(v, "", "  ")
+	if err != nil {
+		return err
+	}
+	data = append(data, '\n')
+	if path == "-" {
+		_, err = os.Stdout.Write(data)
+		return err
+	}
+	return os.WriteFile(path, data, 0o644)
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "tournament: "+format+"\n", args...)
+	os.Exit(1)
+}
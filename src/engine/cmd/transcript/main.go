@@ -0,0 +1,220 @@
+// transcript renders a game as readable Markdown or HTML, from a JSONL dump of
+// the engine events topic, a replay recording (ENGINE_RECORD_FILE), or live
+// from Kafka.
+//
+//	go run ./cmd/transcript game.jsonl                    # omniscient, Markdown
+//	go run ./cmd/transcript -player Alice game.jsonl      # what Alice could see
+//	go run ./cmd/transcript -format html -dir out game.jsonl
+//	go run ./cmd/transcript -kafka -game game-abc123      # until the game ends
+//
+// With -dir the omniscient view and every player's view are written as
+// separate files. -kafka reads ENGINE_KAFKA_BROKERS and stops when the game
+// ends (or on Ctrl+C).
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+	"mafia-engine/internal/transcript"
+)
+
+func main() {
+	format := flag.String("format", transcript.FormatMarkdown, "output format: markdown or html")
+	player := flag.String("player", "", "render what this player (name or ID) could see")
+	dir := flag.String("dir", "", "write the omniscient and every player's view into this directory")
+	gameID := flag.String("game", "", "game to render when the input has several")
+	fromKafka := flag.Bool("kafka", false, "read the engine events topic instead of a file")
+	flag.Usage = func() {
+		fmt.Fprintf(flag.CommandLine.Output(), "usage: transcript [flags] [file.jsonl]\n")
+		flag.PrintDefaults()
+	}
+	flag.Parse()
+
+	var evs []events.Event
+	var err error
+	switch {
+	case *fromKafka:
+		evs, err = consume(*gameID)
+	case flag.NArg() == 1:
+		evs, err = readFile(flag.Arg(0))
+	default:
+		flag.Usage()
+		os.Exit(2)
+	}
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	game, err := pickGame(evs, *gameID)
+	if err != nil {
+		fatalf("%v", err)
+	}
+
+	if *dir != "" {
+		if err := writeAll(game, *dir, *format); err != nil {
+			fatalf("%v", err)
+		}
+		return
+	}
+
+	viewer := events.Viewer{Omniscient: true}
+	if *player != "" {
+		viewer, err = transcript.ViewerFor(game, *player)
+		if err != nil {
+			fatalf("%v", err)
+		}
+	}
+	if err := transcript.Render(os.Stdout, transcript.Build(game, viewer), *format); err != nil {
+		fatalf("%v", err)
+	}
+}
+
+func readFile(path string) ([]events.Event, error) {
+	file, err := os.Open(path) // #nosec G304 -- the input file is chosen by the user
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+
+	evs, err := transcript.Read(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return evs, nil
+}
+
+// pickGame returns the events of the requested game, or of the only game in the input.
+func pickGame(evs []events.Event, gameID string) ([]events.Event, error) {
+	games := transcript.Games(evs)
+	switch {
+	case len(games) == 0:
+		return nil, fmt.Errorf("no events")
+	case gameID != "":
+		game := transcript.ForGame(evs, gameID)
+		if len(game) == 0 {
+			return nil, fmt.Errorf("game %s not found (have %s)", gameID, strings.Join(games, ", "))
+		}
+		return game, nil
+	case len(games) > 1:
+		return nil, fmt.Errorf("input has several games, pick one with -game: %s", strings.Join(games, ", "))
+	default:
+		return evs, nil
+	}
+}
+
+// writeAll writes the omniscient view and one view per player.
+func writeAll(game []events.Event, dir, format string) error {
+	if err := os.MkdirAll(dir, 0o750); err != nil {
+		return err
+	}
+
+	views := map[string]events.Viewer{transcript.ViewOmniscient: {Omniscient: true}}
+	for _, entry := range transcript.Players(game) {
+		viewer, err := transcript.ViewerFor(game, entry.ID)
+		if err != nil {
+			return err
+		}
+		views[entry.Name] = viewer
+	}
+
+	for name, viewer := range views {
+		path := filepath.Join(dir, fileName(name)+"."+transcript.Extension(format))
+		if err := writeView(path, game, viewer, format); err != nil {
+			return err
+		}
+		fmt.Println(path)
+	}
+	return nil
+}
+
+func writeView(path string, game []events.Event, viewer events.Viewer, format string) error {
+	file, err := os.Create(path) // #nosec G304 -- the output directory is chosen by the user
+	if err != nil {
+		return err
+	}
+	if err := transcript.Render(file, transcript.Build(game, viewer), format); err != nil {
+		file.Close()
+		return err
+	}
+	return file.Close()
+}
+
+// fileName turns a player name into a file name ("Dorothy Bird" -> "dorothy-bird").
+func fileName(name string) string {
+	return strings.ToLower(strings.Join(strings.FieldsFunc(name, func(r rune) bool {
+		return !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}), "-"))
+}
+
+// consume reads the engine events topic from the start until the game ends.
+// Without a game ID it follows the first game it sees.
+func consume(gameID string) ([]events.Event, error) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		return nil, fmt.Errorf("failed to load config: %w", err)
+	}
+
+	// a fresh group, so the topic is read from the first offset
+	group := fmt.Sprintf("mafia-transcript-%d", time.Now().UnixNano())
+	consumer, err := kafka.NewKafkaConsumer(cfg.KafkaBrokers, kafka.EngineEventsTopic, group)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	ctx, stop := signal.NotifyContext(context.Background(), os.Interrupt)
+	defer stop()
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	var mu sync.Mutex
+	var evs []events.Event
+	err = consumer.Consume(ctx, func(_ context.Context, msg kafka.Message) error {
+		decoded, err := events.DeserializeEngineEvent(msg.Value)
+		if err != nil {
+			return nil // not ours to judge, skip it
+		}
+		ev, ok := decoded.(events.Event)
+		if !ok {
+			return nil
+		}
+
+		mu.Lock()
+		defer mu.Unlock()
+		if gameID == "" {
+			gameID = ev.Header().GameID
+			fmt.Fprintf(os.Stderr, "following game %s\n", gameID)
+		}
+		if ev.Header().GameID != gameID {
+			return nil
+		}
+		evs = append(evs, ev)
+		if _, ended := ev.(*events.GameEnded); ended {
+			cancel()
+		}
+		return nil
+	})
+	if err != nil && ctx.Err() == nil {
+		return nil, err
+	}
+
+	mu.Lock()
+	defer mu.Unlock()
+	return evs, nil
+}
+
+func fatalf(format string, args ...any) {
+	fmt.Fprintf(os.Stderr, "transcript: "+format+"\n", args...)
+	os.Exit(1)
+}
@@ -4,11 +4,15 @@ go 1.23
 
 toolchain go1.24.11
 
-require github.com/xyproto/randomstring v1.2.0
+require (
+	github.com/caarlos0/env/v11 v11.3.1
+	github.com/segmentio/kafka-go v0.4.49
+	github.com/xyproto/randomstring v1.2.0
+	go.etcd.io/bbolt v1.4.3
+)
 
 require (
-	github.com/caarlos0/env/v11 v11.3.1 // indirect
 	github.com/klauspost/compress v1.15.9 // indirect
 	github.com/pierrec/lz4/v4 v4.1.15 // indirect
-	github.com/segmentio/kafka-go v0.4.49 // indirect
+	golang.org/x/sys v0.29.0 // indirect
 )
@@ -0,0 +1,351 @@
+// Package archive keeps completed games in an embedded bbolt database:
+// ruleset, roster, roles, seed, every published event, the outcome and, when
+// the engine kept one, the command journal (to fork the game, see sim.Branch).
+//
+// The engine writes a game when it ends (see Archiver); analysis reads it back
+// with Find and Get, or over HTTP (see Handler and cmd/archive). bbolt locks the
+// file, so only one process can have the archive open at a time.
+package archive
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/telemetry"
+)
+
+// DefaultRuleset names games played without a ruleset file.
+const DefaultRuleset = "default"
+
+// ErrNotFound is returned for game IDs that are not in the archive.
+var ErrNotFound = errors.New("game not found")
+
+var (
+	gamesBucket    = []byte("games")    // game ID -> Game JSON
+	eventsBucket   = []byte("events")   // game ID -> bucket of seq -> event JSON
+	journalsBucket = []byte("journals") // game ID -> engine.Journal JSON
+)
+
+// Game is one archived game. Events are stored separately, see Store.Events.
+type Game struct {
+	ID        string          `json:"id"`
+	Seed      int64           `json:"seed"`
+	Ruleset   string          `json:"ruleset"`
+	Rules     *domain.Ruleset `json:"rules,omitempty"` // the rules in effect, see NewGame
+	Roles     map[string]int  `json:"roles"`           // role -> players dealt it
+	Pinned    bool            `json:"pinned,omitempty"`
+	StartedAt int64           `json:"started_at"` // Unix ms
+	EndedAt   int64           `json:"ended_at"`   // Unix ms
+	Rounds    int             `json:"rounds"`
+	Winner    string          `json:"winner"`
+	Players   []Player        `json:"players"` // seat order
+
+	// agent usage and response times, if the engine recorded them
+	Telemetry *telemetry.Report `json:"telemetry,omitempty"`
+}
+
+// Player is one seat of an archived game.
+type Player struct {
+	ID     string `json:"id"`
+	Name   string `json:"name"`
+	Seat   int    `json:"seat"`
+	Role   string `json:"role"`
+	Trait  string `json:"trait,omitempty"`
+	Model  string `json:"model,omitempty"`
+	Alive  bool   `json:"alive"`
+	Pinned bool   `json:"pinned,omitempty"`
+}
+
+// Query selects archived games. Zero fields match everything.
+type Query struct {
+	Winner  string
+	From    time.Time // started at or after
+	To      time.Time // started before
+	Players int       // exact player count
+	Model   string    // any seat played by this model profile
+	Limit   int       // newest first
+}
+
+// Matches returns true if the game fits the query.
+func (q Query) Matches(game *Game) bool {
+	started := time.UnixMilli(game.StartedAt)
+	switch {
+	case q.Winner != "" && game.Winner != q.Winner:
+		return false
+	case !q.From.IsZero() && started.Before(q.From):
+		return false
+	case !q.To.IsZero() && !started.Before(q.To):
+		return false
+	case q.Players > 0 && len(game.Players) != q.Players:
+		return false
+	}
+	if q.Model == "" {
+		return true
+	}
+	for _, player := range game.Players {
+		if player.Model == q.Model {
+			return true
+		}
+	}
+	return false
+}
+
+// NewGame builds the archive record of a game from its final state and the
+// events it published. Start and end times come from the events.
+//
+// ruleset is the one the engine ran with (nil for the default rules). The
+// game keeps a copy with the role counts actually dealt for its player count,
+// pinned roles included, so it can be replayed under the same rules.
+func NewGame(state *domain.GameState, ruleset *domain.Ruleset, evs []json.RawMessage) *Game {
+	game := &Game{
+		ID:      state.ID,
+		Seed:    state.Seed,
+		Ruleset: DefaultRuleset,
+		Roles:   make(map[string]int),
+		Rounds:  state.Round,
+		Winner:  state.Winner.String(),
+		Pinned:  state.HasPinnedRoles(),
+	}
+	if ruleset != nil && ruleset.Name != "" {
+		game.Ruleset = ruleset.Name
+	}
+
+	seated := state.GetSeatedPlayers()
+	game.Rules = effectiveRuleset(game.Ruleset, ruleset, seated)
+	for _, player := range seated {
+		game.Roles[player.Role.String()]++
+		game.Players = append(game.Players, Player{
+			ID:     player.ID,
+			Name:   player.Name,
+			Seat:   player.Seat,
+			Role:   player.Role.String(),
+			Trait:  player.Trait,
+			Model:  player.ModelProfile,
+			Alive:  player.Alive,
+			Pinned: player.RolePinned,
+		})
+	}
+
+	for _, raw := range evs {
+		var header events.BaseEvent
+		if err := json.Unmarshal(raw, &header); err != nil {
+			continue
+		}
+		switch header.Type {
+		case events.TypeGameStarted:
+			game.StartedAt = header.Timestamp
+		case events.TypeGameEnded:
+			game.EndedAt = header.Timestamp
+		}
+	}
+	return game
+}
+
+// effectiveRuleset copies ruleset (or starts an empty one named name) and sets
+// the distribution for the game's player count to the roles that were dealt.
+// Traits and durations are shared with ruleset, which the engine never changes.
+func effectiveRuleset(name string, ruleset *domain.Ruleset, seated []*domain.Player) *domain.Ruleset {
+	effective := &domain.Ruleset{Name: name}
+	if ruleset != nil {
+		*effective = *ruleset
+		effective.Name = name
+	}
+	if len(seated) == 0 {
+		return effective
+	}
+
+	roles := make([]domain.Role, len(seated))
+	for i, player := range seated {
+		roles[i] = player.Role
+	}
+	effective.Distributions = make(map[int]domain.RoleCounts, len(effective.Distributions)+1)
+	if ruleset != nil {
+		for n, c := range ruleset.Distributions {
+			effective.Distributions[n] = c
+		}
+	}
+	effective.Distributions[len(seated)] = domain.RoleCountsOf(roles)
+	return effective
+}
+
+// Store is an archive file.
+type Store struct {
+	db *bolt.DB
+}
+
+// Open opens (or creates) the archive at path.
+func Open(path string) (*Store, error) {
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open archive %s: %w", path, err)
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{gamesBucket, eventsBucket, journalsBucket} {
+			if _, err := tx.CreateBucketIfNotExists(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open archive %s: %w", path, err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Close closes the archive file.
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put stores a game and its events, replacing an earlier copy.
+func (s *Store) Put(game *Game, evs []json.RawMessage) error {
+	if game.ID == "" {
+		return errors.New("game ID must not be empty")
+	}
+	data, err := json.Marshal(game)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		if err := tx.Bucket(gamesBucket).Put([]byte(game.ID), data); err != nil {
+			return err
+		}
+
+		all := tx.Bucket(eventsBucket)
+		if all.Bucket([]byte(game.ID)) != nil {
+			if err := all.DeleteBucket([]byte(game.ID)); err != nil {
+				return err
+			}
+		}
+		bucket, err := all.CreateBucket([]byte(game.ID))
+		if err != nil {
+			return err
+		}
+		for i, raw := range evs {
+			if err := bucket.Put(indexKey(i), raw); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+// PutJournal stores the command journal of a game, replacing an earlier copy.
+func (s *Store) PutJournal(id string, journal *engine.Journal) error {
+	if id == "" {
+		return errors.New("game ID must not be empty")
+	}
+	data, err := json.Marshal(journal)
+	if err != nil {
+		return fmt.Errorf("failed to encode journal of game %s: %w", id, err)
+	}
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(journalsBucket).Put([]byte(id), data)
+	})
+}
+
+// Journal returns the command journal of a game.
+// Returns ErrNotFound if the game was archived without one.
+func (s *Store) Journal(id string) (*engine.Journal, error) {
+	journal := engine.NewJournal()
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(journalsBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		return json.Unmarshal(data, journal)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return journal, nil
+}
+
+// Get returns an archived game (without its events).
+func (s *Store) Get(id string) (*Game, error) {
+	var game *Game
+	err := s.db.View(func(tx *bolt.Tx) error {
+		data := tx.Bucket(gamesBucket).Get([]byte(id))
+		if data == nil {
+			return ErrNotFound
+		}
+		game = &Game{}
+		return json.Unmarshal(data, game)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return game, nil
+}
+
+// Events returns the events a game published, in order.
+func (s *Store) Events(id string) ([]json.RawMessage, error) {
+	var evs []json.RawMessage
+	err := s.db.View(func(tx *bolt.Tx) error {
+		bucket := tx.Bucket(eventsBucket).Bucket([]byte(id))
+		if bucket == nil {
+			return ErrNotFound
+		}
+		evs = make([]json.RawMessage, 0, bucket.Stats().KeyN)
+		return bucket.ForEach(func(_, value []byte) error {
+			// values are only valid inside the transaction
+			evs = append(evs, append(json.RawMessage(nil), value...))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+	return evs, nil
+}
+
+// Find returns the games matching the query, newest first.
+// It scans every game; archives are small enough for that.
+func (s *Store) Find(q Query) ([]*Game, error) {
+	var games []*Game
+	err := s.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(gamesBucket).ForEach(func(key, value []byte) error {
+			game := &Game{}
+			if err := json.Unmarshal(value, game); err != nil {
+				return fmt.Errorf("game %s: %w", key, err)
+			}
+			if q.Matches(game) {
+				games = append(games, game)
+			}
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	sort.SliceStable(games, func(i, j int) bool {
+		if games[i].StartedAt != games[j].StartedAt {
+			return games[i].StartedAt > games[j].StartedAt
+		}
+		return games[i].ID < games[j].ID
+	})
+	if q.Limit > 0 && len(games) > q.Limit {
+		games = games[:q.Limit]
+	}
+	return games, nil
+}
+
+// indexKey keeps events in publish order under bbolt's byte-sorted keys.
+func indexKey(i int) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, uint64(i))
+	return key
+}
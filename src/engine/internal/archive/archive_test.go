@@ -0,0 +1,275 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+	"mafia-engine/internal/telemetry"
+)
+
+func openStore(t *testing.T) *Store {
+	t.Helper()
+	store, err := Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatalf("Open failed: %v", err)
+	}
+	t.Cleanup(func() { store.Close() })
+	return store
+}
+
+func testGame(id, winner string, started time.Time, models ...string) *Game {
+	game := &Game{ID: id, Winner: winner, StartedAt: started.UnixMilli(), Ruleset: DefaultRuleset}
+	for i, model := range models {
+		game.Players = append(game.Players, Player{ID: id + "-p" + string(rune('1'+i)), Seat: i + 1, Model: model})
+	}
+	return game
+}
+
+func TestStore_PutGetFind(t *testing.T) {
+	store := openStore(t)
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	games := []*Game{
+		testGame("g1", "mafia", day, "gpt-4o", "bot:random", "bot:random"),
+		testGame("g2", "village", day.Add(24*time.Hour), "bot:random", "bot:random"),
+		testGame("g3", "village", day.Add(48*time.Hour), "claude", "gpt-4o", "bot:random"),
+	}
+	for _, game := range games {
+		evs := []json.RawMessage{json.RawMessage(`{"type":"game_started"}`), json.RawMessage(`{"type":"game_ended"}`)}
+		if err := store.Put(game, evs); err != nil {
+			t.Fatalf("Put failed: %v", err)
+		}
+	}
+
+	got, err := store.Get("g3")
+	if err != nil || got.Winner != "village" || len(got.Players) != 3 {
+		t.Fatalf("unexpected Get result %+v, %v", got, err)
+	}
+	if _, err := store.Get("nope"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound, got %v", err)
+	}
+	evs, err := store.Events("g1")
+	if err != nil || len(evs) != 2 || string(evs[1]) != `{"type":"game_ended"}` {
+		t.Errorf("unexpected events %s, %v", evs, err)
+	}
+
+	tests := []struct {
+		name  string
+		query Query
+		want  []string
+	}{
+		{"all, newest first", Query{}, []string{"g3", "g2", "g1"}},
+		{"winner", Query{Winner: "village"}, []string{"g3", "g2"}},
+		{"players", Query{Players: 3}, []string{"g3", "g1"}},
+		{"model", Query{Model: "gpt-4o"}, []string{"g3", "g1"}},
+		{"date range", Query{From: day.Add(time.Hour), To: day.Add(48 * time.Hour)}, []string{"g2"}},
+		{"limit", Query{Limit: 1}, []string{"g3"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			found, err := store.Find(tt.query)
+			if err != nil {
+				t.Fatalf("Find failed: %v", err)
+			}
+			var ids []string
+			for _, game := range found {
+				ids = append(ids, game.ID)
+			}
+			if strings.Join(ids, ",") != strings.Join(tt.want, ",") {
+				t.Errorf("got %v, want %v", ids, tt.want)
+			}
+		})
+	}
+
+	// a game stored again replaces the old events
+	if err := store.Put(games[0], []json.RawMessage{json.RawMessage(`{}`)}); err != nil {
+		t.Fatal(err)
+	}
+	if evs, _ := store.Events("g1"); len(evs) != 1 {
+		t.Errorf("expected the events to be replaced, got %d", len(evs))
+	}
+}
+
+type discard struct{}
+
+func (discard) Publish(context.Context, kafka.Message) error { return nil }
+func (discard) Close() error                                 { return nil }
+
+func TestArchiver_StoresGameOnEnd(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store := openStore(t)
+
+	state := domain.NewGameState("arch")
+	state.Seed = 42
+	for i, model := range []string{"gpt-4o", "bot:random", "bot:random", "bot:random"} {
+		role := []domain.Role{domain.RoleMafia, domain.RoleVillager, domain.RoleDoctor, domain.RoleSheriff}[i]
+		player, err := domain.NewPlayer(fmt.Sprintf("p%d", i+1), fmt.Sprintf("Player %d", i+1), role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		player.ModelProfile = model
+		player.Alive = i != 0
+		state.AddPlayer(player)
+	}
+	state.Round = 2
+	state.Winner = domain.WinnerVillage
+
+	archiver, err := NewArchiver(store, state, cfg)
+	if err != nil {
+		t.Fatalf("NewArchiver failed: %v", err)
+	}
+	usage := telemetry.NewRecorder()
+	usage.Record(telemetry.Sample{GameID: state.ID, PlayerID: "p1", Model: "gpt-4o", Usage: &events.Telemetry{PromptTokens: 120}})
+	archiver.AddTelemetry(usage)
+	journal := engine.NewJournal()
+	if err := json.Unmarshal([]byte(`{"initial":{"ID":"`+state.ID+`","Round":1},"entries":[{"kind":"vote","seq":7,"timestamp":4000,"player_id":"p2","target":"p1"}]}`), journal); err != nil {
+		t.Fatal(err)
+	}
+	archiver.AddJournal(journal)
+	producer := archiver.Producer(discard{})
+	publish := func(topic, value string) {
+		if err := producer.Publish(context.Background(), kafka.Message{Topic: topic, Value: []byte(value)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	publish(kafka.EngineEventsTopic, `{"game_id":"`+state.ID+`","type":"game_started","timestamp":1000}`)
+	publish(kafka.EngineEventsTopic, `{"game_id":"other","type":"game_started","timestamp":1}`)
+	publish(kafka.PlayerActionsTopic, `{"type":"vote_submitted"}`)
+	publish(kafka.ObserverEventsTopic, `{"game_id":"`+state.ID+`","type":"player_thoughts","sender":"p1","thought":"hm"}`)
+	if _, err := store.Get(state.ID); !errors.Is(err, ErrNotFound) {
+		t.Fatal("the game must not be stored before it ends")
+	}
+	publish(kafka.EngineEventsTopic, `{"game_id":"`+state.ID+`","type":"game_ended","timestamp":5000,"winner":"village"}`)
+
+	if err := archiver.Err(); err != nil {
+		t.Fatalf("archiving failed: %v", err)
+	}
+	game, err := store.Get(state.ID)
+	if err != nil {
+		t.Fatalf("game not archived: %v", err)
+	}
+	if game.Seed != 42 || game.Winner != "village" || game.Rounds != 2 || game.StartedAt != 1000 || game.EndedAt != 5000 {
+		t.Errorf("unexpected game %+v", game)
+	}
+	if len(game.Players) != 4 || game.Players[0].Model != "gpt-4o" || game.Players[0].Alive || game.Roles["mafia"] != 1 {
+		t.Errorf("unexpected roster %+v, roles %v", game.Players, game.Roles)
+	}
+	if game.Rules == nil || game.Rules.Name != DefaultRuleset || game.Rules.Distributions[4] != (domain.RoleCounts{Mafia: 1, Doctor: 1, Sheriff: 1}) {
+		t.Errorf("unexpected rules %+v", game.Rules)
+	}
+	if game.Telemetry == nil || game.Telemetry.Players["p1"].PromptTokens != 120 || game.Telemetry.Models["gpt-4o"].Actions != 1 {
+		t.Errorf("telemetry not archived: %+v", game.Telemetry)
+	}
+	if evs, _ := store.Events(state.ID); len(evs) != 3 {
+		t.Errorf("expected the game's 3 events, thoughts included, got %d", len(evs))
+	}
+	stored, err := store.Journal(state.ID)
+	if err != nil {
+		t.Fatalf("journal not archived: %v", err)
+	}
+	if entries, _ := stored.Entries(); len(entries) != 1 || entries[0].PlayerID != "p2" || entries[0].Seq != 7 || entries[0].Timestamp != 4000 {
+		t.Errorf("unexpected archived journal %+v", entries)
+	}
+	if _, err := store.Journal("other"); !errors.Is(err, ErrNotFound) {
+		t.Errorf("expected ErrNotFound without a journal, got %v", err)
+	}
+
+	var nilArchiver *Archiver
+	if nilArchiver.Producer(producer) != producer || nilArchiver.Err() != nil {
+		t.Error("a nil archiver must pass the producer through")
+	}
+}
+
+func TestNewGame_KeepsEffectiveRules(t *testing.T) {
+	state := domain.NewGameState("rules")
+	for i, role := range []domain.Role{domain.RoleMafia, domain.RoleMafia, domain.RoleVillager, domain.RoleVillager, domain.RoleVillager} {
+		player, err := domain.NewPlayer(fmt.Sprintf("p%d", i+1), fmt.Sprintf("Player %d", i+1), role)
+		if err != nil {
+			t.Fatal(err)
+		}
+		state.AddPlayer(player)
+	}
+	ruleset := &domain.Ruleset{
+		Name:          "fast",
+		Distributions: map[int]domain.RoleCounts{7: {Mafia: 2, Doctor: 1}},
+		Durations:     map[string]domain.DurationPolicy{"day": {Base: domain.Duration(time.Minute)}},
+	}
+
+	game := NewGame(state, ruleset, nil)
+	if game.Ruleset != "fast" || game.Rules.Name != "fast" {
+		t.Errorf("expected the fast rules, got %q %+v", game.Ruleset, game.Rules)
+	}
+	if game.Rules.Distributions[5] != (domain.RoleCounts{Mafia: 2}) || game.Rules.Distributions[7].Doctor != 1 {
+		t.Errorf("expected the dealt counts next to the ruleset's, got %v", game.Rules.Distributions)
+	}
+	if game.Rules.Durations["day"].Base != domain.Duration(time.Minute) {
+		t.Errorf("duration policies not kept: %v", game.Rules.Durations)
+	}
+	if _, changed := ruleset.Distributions[5]; changed {
+		t.Error("NewGame must not change the engine's ruleset")
+	}
+}
+
+func TestHandler(t *testing.T) {
+	store := openStore(t)
+	day := time.Date(2025, 3, 1, 12, 0, 0, 0, time.UTC)
+	for _, game := range []*Game{
+		testGame("g1", "mafia", day, "gpt-4o"),
+		testGame("g2", "village", day.Add(24*time.Hour), "claude"),
+	} {
+		if err := store.Put(game, []json.RawMessage{json.RawMessage(`{"type":"game_ended"}`)}); err != nil {
+			t.Fatal(err)
+		}
+	}
+	server := httptest.NewServer(Handler(store))
+	defer server.Close()
+
+	get := func(path string) (int, string) {
+		resp, err := http.Get(server.URL + path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return resp.StatusCode, string(body)
+	}
+
+	tests := []struct {
+		path   string
+		status int
+		want   string
+	}{
+		{"/games?winner=mafia", http.StatusOK, `"id":"g1"`},
+		{"/games?from=2025-03-02&model=claude", http.StatusOK, `"id":"g2"`},
+		{"/games?winner=nobody", http.StatusOK, `[]`},
+		{"/games?players=x", http.StatusBadRequest, `invalid players`},
+		{"/games?from=yesterday", http.StatusBadRequest, `invalid from`},
+		{"/games/g2", http.StatusOK, `"winner":"village"`},
+		{"/games/g2/events", http.StatusOK, `[{"type":"game_ended"}]`},
+		{"/games/nope", http.StatusNotFound, `game not found`},
+	}
+	for _, tt := range tests {
+		status, body := get(tt.path)
+		if status != tt.status || !strings.Contains(body, tt.want) {
+			t.Errorf("GET %s: got %d %s, want %d containing %s", tt.path, status, body, tt.status, tt.want)
+		}
+	}
+}
@@ -0,0 +1,132 @@
+package archive
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"sync"
+
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+	"mafia-engine/internal/telemetry"
+)
+
+// Archiver collects what the engine publishes, observer events (player
+// thoughts) included, and stores the game when it ends. A nil *Archiver
+// archives nothing, so callers don't need to check whether archiving is on.
+//
+// Store errors don't stop the game; the first one is kept for Err.
+type Archiver struct {
+	mu      sync.Mutex
+	store   *Store
+	state   *domain.GameState
+	ruleset *domain.Ruleset
+	events  []json.RawMessage
+	err     error
+
+	telemetry *telemetry.Recorder // optional
+	journal   *engine.Journal     // optional
+}
+
+// NewArchiver returns an archiver storing the game into store.
+func NewArchiver(store *Store, state *domain.GameState, cfg *config.Config) (*Archiver, error) {
+	if store == nil || state == nil || cfg == nil {
+		return nil, errors.New("store, state and config must not be nil")
+	}
+
+	var ruleset *domain.Ruleset
+	if cfg.RulesetFile != "" {
+		loaded, err := engine.LoadRuleset(cfg.RulesetFile)
+		if err != nil {
+			return nil, err
+		}
+		ruleset = loaded
+	}
+	return &Archiver{store: store, state: state, ruleset: ruleset}, nil
+}
+
+// AddTelemetry stores the game's telemetry from r with the game.
+func (a *Archiver) AddTelemetry(r *telemetry.Recorder) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.telemetry = r
+}
+
+// AddJournal stores the game's command journal with the game.
+// Give the same journal to the engine with engine.WithJournal.
+func (a *Archiver) AddJournal(j *engine.Journal) {
+	if a == nil {
+		return
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.journal = j
+}
+
+// Producer wraps the engine's producer so every published event is archived.
+// Give it to the engine only: the game is read when GameEnded is published,
+// which happens on the engine loop.
+func (a *Archiver) Producer(producer kafka.Producer) kafka.Producer {
+	if a == nil {
+		return producer
+	}
+	return &archivingProducer{Producer: producer, archiver: a}
+}
+
+// Err returns the first store error, if any.
+func (a *Archiver) Err() error {
+	if a == nil {
+		return nil
+	}
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	return a.err
+}
+
+func (a *Archiver) add(msg kafka.Message) {
+	if msg.Topic != kafka.EngineEventsTopic && msg.Topic != kafka.ObserverEventsTopic || !json.Valid(msg.Value) {
+		return
+	}
+	var header events.BaseEvent
+	if err := json.Unmarshal(msg.Value, &header); err != nil || header.GameID != a.state.ID {
+		return
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+	a.events = append(a.events, append(json.RawMessage(nil), msg.Value...))
+	if header.Type != events.TypeGameEnded {
+		return
+	}
+	game := NewGame(a.state, a.ruleset, a.events)
+	game.Telemetry = a.telemetry.Report(a.state.ID)
+	if err := a.store.Put(game, a.events); err != nil && a.err == nil {
+		a.err = err
+	}
+	if a.journal == nil {
+		return
+	}
+	if err := a.store.PutJournal(a.state.ID, a.journal); err != nil && a.err == nil {
+		a.err = err
+	}
+}
+
+// archivingProducer archives what the engine published successfully.
+type archivingProducer struct {
+	kafka.Producer
+	archiver *Archiver
+}
+
+func (p *archivingProducer) Publish(ctx context.Context, msg kafka.Message) error {
+	if err := p.Producer.Publish(ctx, msg); err != nil {
+		return err
+	}
+	p.archiver.add(msg)
+	return nil
+}
@@ -0,0 +1,127 @@
+package archive
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"time"
+)
+
+// Handler serves the archive as JSON:
+//
+//	GET /games?winner=mafia&players=7&model=gpt-4o&from=2025-01-01&to=2025-02-01&limit=20
+//	GET /games/{id}          the game record
+//	GET /games/{id}/events   every event the game published, in order
+//
+// from and to take a date or an RFC 3339 time and filter on the start time.
+func Handler(store *Store) http.Handler {
+	mux := http.NewServeMux()
+
+	mux.HandleFunc("GET /games", func(w http.ResponseWriter, r *http.Request) {
+		q, err := ParseQuery(r.URL.Query())
+		if err != nil {
+			writeError(w, http.StatusBadRequest, err)
+			return
+		}
+		games, err := store.Find(q)
+		if err != nil {
+			writeError(w, http.StatusInternalServerError, err)
+			return
+		}
+		if games == nil {
+			games = []*Game{}
+		}
+		writeJSON(w, games)
+	})
+
+	mux.HandleFunc("GET /games/{id}", func(w http.ResponseWriter, r *http.Request) {
+		game, err := store.Get(r.PathValue("id"))
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeJSON(w, game)
+	})
+
+	mux.HandleFunc("GET /games/{id}/events", func(w http.ResponseWriter, r *http.Request) {
+		evs, err := store.Events(r.PathValue("id"))
+		if err != nil {
+			writeError(w, statusFor(err), err)
+			return
+		}
+		writeJSON(w, evs)
+	})
+
+	return mux
+}
+
+// ParseQuery reads a Query from URL parameters (see Handler).
+func ParseQuery(values url.Values) (Query, error) {
+	q := Query{
+		Winner: values.Get("winner"),
+		Model:  values.Get("model"),
+	}
+
+	var err error
+	if q.From, err = parseTime(values.Get("from")); err != nil {
+		return Query{}, fmt.Errorf("invalid from: %w", err)
+	}
+	if q.To, err = parseTime(values.Get("to")); err != nil {
+		return Query{}, fmt.Errorf("invalid to: %w", err)
+	}
+	if q.Players, err = parseCount(values.Get("players")); err != nil {
+		return Query{}, fmt.Errorf("invalid players: %w", err)
+	}
+	if q.Limit, err = parseCount(values.Get("limit")); err != nil {
+		return Query{}, fmt.Errorf("invalid limit: %w", err)
+	}
+	return q, nil
+}
+
+// parseTime accepts "2006-01-02" (UTC) or RFC 3339; empty is the zero time.
+func parseTime(value string) (time.Time, error) {
+	if value == "" {
+		return time.Time{}, nil
+	}
+	if t, err := time.Parse(time.DateOnly, value); err == nil {
+		return t, nil
+	}
+	return time.Parse(time.RFC3339, value)
+}
+
+func parseCount(value string) (int, error) {
+	if value == "" {
+		return 0, nil
+	}
+	n, err := strconv.Atoi(value)
+	if err != nil {
+		return 0, err
+	}
+	if n < 0 {
+		return 0, errors.New("must not be negative")
+	}
+	return n, nil
+}
+
+func statusFor(err error) int {
+	if errors.Is(err, ErrNotFound) {
+		return http.StatusNotFound
+	}
+	return http.StatusInternalServerError
+}
+
+func writeJSON(w http.ResponseWriter, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		http.Error(w, err.Error(), http.StatusInternalServerError)
+	}
+}
+
+func writeError(w http.ResponseWriter, status int, err error) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": err.Error()})
+}
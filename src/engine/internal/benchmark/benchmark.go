@@ -0,0 +1,436 @@
+package benchmark
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/sim"
+	"mafia-engine/internal/tournament"
+)
+
+// SeatOutcome is how one seat did in one game.
+type SeatOutcome struct {
+	Won bool `json:"won"`
+	// SurvivalRounds is the round the seat was eliminated in, or the last round
+	SurvivalRounds int `json:"survival_rounds"`
+
+	// Votes the seat cast, and how many of them hit the mafia
+	Votes        int `json:"votes"`
+	VotesOnMafia int `json:"votes_on_mafia"`
+
+	// Village votes cast while the seat was alive, and how many hit the seat
+	VillageVotes int `json:"village_votes"`
+	VotesAgainst int `json:"votes_against"`
+}
+
+// voteAccuracy is the share of the seat's votes that hit the mafia.
+func (o SeatOutcome) voteAccuracy() (float64, bool) {
+	if o.Votes == 0 {
+		return 0, false
+	}
+	return float64(o.VotesOnMafia) / float64(o.Votes), true
+}
+
+// deception is the share of village votes that missed the seat.
+func (o SeatOutcome) deception() (float64, bool) {
+	if o.VillageVotes == 0 {
+		return 0, false
+	}
+	return 1 - float64(o.VotesAgainst)/float64(o.VillageVotes), true
+}
+
+// Pair is one seed with one seat swapped.
+type Pair struct {
+	Seed      int64       `json:"seed"`
+	Seat      int         `json:"seat"`
+	Role      string      `json:"role"`
+	Trait     string      `json:"trait,omitempty"`
+	Baseline  SeatOutcome `json:"baseline"`
+	Candidate SeatOutcome `json:"candidate"`
+}
+
+// ModelReport is the comparison of one candidate model against the baseline.
+type ModelReport struct {
+	Model string `json:"model"`
+
+	WinRate        Delta `json:"win_rate"`
+	SurvivalRounds Delta `json:"survival_rounds"`
+	// village seats only: share of their votes that hit the mafia
+	VoteAccuracy Delta `json:"vote_accuracy"`
+	// mafia seats only: share of village votes that missed them
+	DeceptionSuccess Delta `json:"deception_success"`
+
+	Pairs []Pair `json:"pairs"`
+}
+
+// Report is the result of a benchmark.
+type Report struct {
+	Name   string         `json:"name"`
+	Games  int            `json:"games"` // games played, baselines included
+	Models []*ModelReport `json:"models"`
+}
+
+// Game is one game of a benchmark: a seed and the model profile of every seat.
+type Game struct {
+	tournament.Pairing
+	Seat  int    `json:"seat,omitempty"`  // the seat a candidate was swapped into, 0 for the baseline
+	Model string `json:"model,omitempty"` // the candidate
+}
+
+// games lists the benchmark's games in the order they are played: for every
+// seed the baseline, then every candidate in every swapped seat.
+func (s *Spec) games() ([]Game, error) {
+	pinned, _, err := s.PinnedRoles()
+	if err != nil {
+		return nil, err
+	}
+	baseline := make([]string, len(s.Baseline))
+	for i, model := range s.Baseline {
+		baseline[i] = profile(model)
+	}
+
+	var games []Game
+	add := func(seed int64, seat int, model string) {
+		seats := append([]string(nil), baseline...)
+		if seat > 0 {
+			seats[seat-1] = profile(model)
+		}
+		games = append(games, Game{
+			Pairing: tournament.Pairing{Game: len(games), Seed: seed, Seats: seats, Roles: pinned},
+			Seat:    seat,
+			Model:   model,
+		})
+	}
+	for _, seed := range s.GameSeeds() {
+		add(seed, 0, "")
+		for _, seat := range s.SwapSeats() {
+			for _, model := range s.Candidates {
+				add(seed, seat, model)
+			}
+		}
+	}
+	return games, nil
+}
+
+// Plan lists the benchmark's games for live engines, which must run with the
+// spec's ruleset file and play each game with the settings of its Env. Pinned
+// roles must fit the ruleset's distribution, as live engines deal from it.
+// Archive the games and compare them with Score.
+func Plan(spec *Spec) ([]Game, error) {
+	if spec == nil {
+		return nil, errors.New("spec must not be nil")
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	ruleset, err := loadRuleset(spec.RulesetFile)
+	if err != nil {
+		return nil, err
+	}
+	pinned, _, err := spec.PinnedRoles()
+	if err != nil {
+		return nil, err
+	}
+	if err := domain.ValidatePinnedRoles(pinned, ruleset.RoleDistribution(len(spec.Baseline))); err != nil {
+		return nil, fmt.Errorf("roles don't fit the ruleset for %d players: %w", len(spec.Baseline), err)
+	}
+	return spec.games()
+}
+
+// Run plays a benchmark of built-in bots headlessly.
+func Run(cfg *config.Config, spec *Spec) (*Report, error) {
+	if cfg == nil || spec == nil {
+		return nil, errors.New("config and spec must not be nil")
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+
+	ruleset, err := loadRuleset(spec.RulesetFile)
+	if err != nil {
+		return nil, err
+	}
+	pinned, counts, err := spec.PinnedRoles()
+	if err != nil {
+		return nil, err
+	}
+	if counts != nil {
+		// the pins must fit the distribution the engine deals
+		pinnedRuleset := &domain.Ruleset{Name: spec.Name, Distributions: map[int]domain.RoleCounts{len(spec.Baseline): *counts}}
+		if ruleset != nil {
+			pinnedRuleset.Traits = ruleset.Traits
+		}
+		ruleset = pinnedRuleset
+	}
+
+	games, err := spec.games()
+	if err != nil {
+		return nil, err
+	}
+	for _, game := range games {
+		if _, err := strategies(game.Seats); err != nil {
+			return nil, err
+		}
+	}
+
+	return score(spec, games, func(game Game) (*archive.Game, []json.RawMessage, error) {
+		names, _ := strategies(game.Seats)
+		result, err := sim.Run(cfg, sim.Game{
+			Seed:        game.Seed,
+			Players:     len(game.Seats),
+			Ruleset:     ruleset,
+			Strategies:  names,
+			MaxRounds:   spec.MaxRounds,
+			PinnedRoles: pinned,
+		})
+		if err != nil {
+			return nil, nil, fmt.Errorf("seed %d: %w", game.Seed, err)
+		}
+		return archive.NewGame(result.State, ruleset, result.Events), result.Events, nil
+	})
+}
+
+// Score compares the benchmark's games played live (see Plan) from the
+// archive. Every game must be archived: the newest game with its seed and
+// the same model profile in every seat is taken.
+func Score(store *archive.Store, spec *Spec) (*Report, error) {
+	games, err := Plan(spec)
+	if err != nil {
+		return nil, err
+	}
+	archived, err := store.Find(archive.Query{Players: len(spec.Baseline)})
+	if err != nil {
+		return nil, err
+	}
+
+	// newest first, so the first game found for a setup wins
+	bySetup := make(map[string]*archive.Game)
+	for _, game := range archived {
+		seats := make([]string, len(game.Players))
+		for i, player := range game.Players {
+			seats[i] = player.Model
+		}
+		key := setupKey(game.Seed, seats)
+		if _, ok := bySetup[key]; !ok {
+			bySetup[key] = game
+		}
+	}
+
+	return score(spec, games, func(game Game) (*archive.Game, []json.RawMessage, error) {
+		played, ok := bySetup[setupKey(game.Seed, game.Seats)]
+		if !ok {
+			return nil, nil, fmt.Errorf("game %d (seed %d, %s) is not in the archive", game.Game+1, game.Seed, strings.Join(game.Seats, ","))
+		}
+		evs, err := store.Events(played.ID)
+		if err != nil {
+			return nil, nil, err
+		}
+		return played, evs, nil
+	})
+}
+
+// setupKey identifies the setup of a game: its seed and who played each seat.
+func setupKey(seed int64, seats []string) string {
+	return strconv.FormatInt(seed, 10) + "|" + strings.Join(seats, ",")
+}
+
+// loadRuleset reads the spec's ruleset file (nil for the default rules).
+func loadRuleset(path string) (*domain.Ruleset, error) {
+	if path == "" {
+		return nil, nil
+	}
+	return engine.LoadRuleset(path)
+}
+
+// score compares every candidate game with the baseline game of its seed.
+// played returns a game's record and events, in the order of games.
+func score(spec *Spec, games []Game, played func(Game) (*archive.Game, []json.RawMessage, error)) (*Report, error) {
+	report := &Report{Name: spec.Name}
+	models := make(map[string]*ModelReport, len(spec.Candidates))
+	for _, model := range spec.Candidates {
+		report.Models = append(report.Models, &ModelReport{Model: model})
+		models[model] = report.Models[len(report.Models)-1]
+	}
+
+	type record struct {
+		game   *archive.Game
+		events []json.RawMessage
+	}
+	baselines := make(map[int64]record)
+	for _, game := range games {
+		var rec record
+		var err error
+		if rec.game, rec.events, err = played(game); err != nil {
+			return nil, err
+		}
+		report.Games++
+		if game.Seat == 0 {
+			baselines[game.Seed] = rec
+			continue
+		}
+
+		baseline := baselines[game.Seed]
+		pair, err := comparePair(game.Seed, game.Seat, baseline.game, baseline.events, rec.game, rec.events)
+		if err != nil {
+			return nil, err
+		}
+		models[game.Model].Pairs = append(models[game.Model].Pairs, pair)
+	}
+
+	for _, model := range report.Models {
+		model.summarize()
+	}
+	return report, nil
+}
+
+// comparePair checks both games dealt the seat the same role and persona and scores it.
+func comparePair(seed int64, seat int, baseline *archive.Game, baselineEvents []json.RawMessage, candidate *archive.Game, candidateEvents []json.RawMessage) (Pair, error) {
+	base, player, err := seatOutcome(baseline, baselineEvents, seat)
+	if err != nil {
+		return Pair{}, err
+	}
+	cand, candidatePlayer, err := seatOutcome(candidate, candidateEvents, seat)
+	if err != nil {
+		return Pair{}, err
+	}
+	if player.Role != candidatePlayer.Role {
+		return Pair{}, fmt.Errorf("seed %d seat %d: role changed from %s to %s, games are not paired", seed, seat, player.Role, candidatePlayer.Role)
+	}
+	if player.Trait != candidatePlayer.Trait {
+		return Pair{}, fmt.Errorf("seed %d seat %d: trait changed from %q to %q, games are not paired", seed, seat, player.Trait, candidatePlayer.Trait)
+	}
+	return Pair{Seed: seed, Seat: seat, Role: player.Role, Trait: player.Trait, Baseline: base, Candidate: cand}, nil
+}
+
+// seatOutcome scores one seat from the game's record and events, and
+// returns the seat's player.
+func seatOutcome(game *archive.Game, evs []json.RawMessage, seat int) (SeatOutcome, archive.Player, error) {
+	var player archive.Player
+	found := false
+	factions := make(map[string]domain.Faction, len(game.Players))
+	for _, p := range game.Players {
+		role, _ := domain.ParseRole(p.Role)
+		factions[p.ID] = role.Faction()
+		if p.Seat == seat {
+			player, found = p, true
+		}
+	}
+	if !found {
+		return SeatOutcome{}, player, fmt.Errorf("seat %d is empty", seat)
+	}
+	faction := factions[player.ID]
+
+	outcome := SeatOutcome{
+		Won:            faction.String() == game.Winner,
+		SurvivalRounds: game.Rounds,
+	}
+	eliminated := false
+	for i, raw := range evs {
+		decoded, err := events.DeserializeEngineEvent(raw)
+		if err != nil {
+			return SeatOutcome{}, player, fmt.Errorf("event %d: %w", i, err)
+		}
+		switch ev := decoded.(type) {
+		case *events.PlayerEliminated:
+			if ev.PlayerID == player.ID {
+				eliminated = true
+				outcome.SurvivalRounds = ev.Round
+			}
+		case *events.VoteResult:
+			if eliminated {
+				continue
+			}
+			for _, ballot := range ev.Votes {
+				if ballot.VoterID == player.ID {
+					outcome.Votes++
+					if factions[ballot.TargetID] == domain.FactionMafia {
+						outcome.VotesOnMafia++
+					}
+				}
+				if factions[ballot.VoterID] == domain.FactionVillage {
+					outcome.VillageVotes++
+					if ballot.TargetID == player.ID {
+						outcome.VotesAgainst++
+					}
+				}
+			}
+		}
+	}
+	return outcome, player, nil
+}
+
+// summarize computes the paired deltas from the pairs.
+func (m *ModelReport) summarize() {
+	var winBase, winCand, roundsBase, roundsCand []float64
+	var accBase, accCand, decBase, decCand []float64
+	for _, pair := range m.Pairs {
+		winBase = append(winBase, boolValue(pair.Baseline.Won))
+		winCand = append(winCand, boolValue(pair.Candidate.Won))
+		roundsBase = append(roundsBase, float64(pair.Baseline.SurvivalRounds))
+		roundsCand = append(roundsCand, float64(pair.Candidate.SurvivalRounds))
+
+		role, _ := domain.ParseRole(pair.Role)
+		switch role.Faction() {
+		case domain.FactionVillage:
+			base, okBase := pair.Baseline.voteAccuracy()
+			cand, okCand := pair.Candidate.voteAccuracy()
+			if okBase && okCand {
+				accBase, accCand = append(accBase, base), append(accCand, cand)
+			}
+		case domain.FactionMafia:
+			base, okBase := pair.Baseline.deception()
+			cand, okCand := pair.Candidate.deception()
+			if okBase && okCand {
+				decBase, decCand = append(decBase, base), append(decCand, cand)
+			}
+		}
+	}
+	m.WinRate = pairedDelta(winBase, winCand)
+	m.SurvivalRounds = pairedDelta(roundsBase, roundsCand)
+	m.VoteAccuracy = pairedDelta(accBase, accCand)
+	m.DeceptionSuccess = pairedDelta(decBase, decCand)
+}
+
+// WriteText prints the comparison of every candidate with its significance.
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "== benchmark %s: %d games\n", r.Name, r.Games)
+	for _, model := range r.Models {
+		fmt.Fprintf(w, "\n%s vs baseline (%d pairs)\n", model.Model, len(model.Pairs))
+		fmt.Fprintf(w, "  %-18s %6s %9s %9s %9s %17s %13s %8s\n",
+			"metric", "pairs", "baseline", "candidate", "delta", "95% interval", "better/worse", "p")
+		for _, row := range []struct {
+			name  string
+			delta Delta
+		}{
+			{"win rate", model.WinRate},
+			{"survival rounds", model.SurvivalRounds},
+			{"vote accuracy", model.VoteAccuracy},
+			{"deception success", model.DeceptionSuccess},
+		} {
+			d := row.delta
+			mark := ""
+			if d.Significant {
+				mark = " *"
+			}
+			fmt.Fprintf(w, "  %-18s %6d %9.3f %9.3f %+9.3f [%+7.3f,%+7.3f] %6d/%-6d %8.4f%s\n",
+				row.name, d.Pairs, d.Baseline, d.Candidate, d.Delta, d.Low, d.High, d.Better, d.Worse, d.PValue, mark)
+		}
+	}
+	fmt.Fprintf(w, "\n* significant at p < %.2f (two-sided exact sign test on the pairs)\n", alpha)
+}
+
+func boolValue(b bool) float64 {
+	if b {
+		return 1
+	}
+	return 0
+}
@@ -0,0 +1,207 @@
+package benchmark
+
+import (
+	"bytes"
+	"fmt"
+	"math"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/sim"
+)
+
+func TestSignTest(t *testing.T) {
+	tests := []struct {
+		better, worse int
+		want          float64
+	}{
+		{0, 0, 1},
+		{3, 3, 1},
+		{5, 0, 0.0625}, // 2 * 0.5^5
+		{10, 2, 0.0386},
+		{200, 150, 0.0087},
+	}
+	for _, tt := range tests {
+		if got := SignTest(tt.better, tt.worse); math.Abs(got-tt.want) > 1e-3 {
+			t.Errorf("SignTest(%d, %d) = %.4f, want %.4f", tt.better, tt.worse, got, tt.want)
+		}
+	}
+}
+
+func TestPairedDelta(t *testing.T) {
+	d := pairedDelta([]float64{0, 0, 1, 1}, []float64{1, 1, 1, 0})
+	if d.Pairs != 4 || d.Baseline != 0.5 || d.Candidate != 0.75 || d.Delta != 0.25 {
+		t.Errorf("unexpected means %+v", d)
+	}
+	if d.Better != 2 || d.Worse != 1 || d.Significant {
+		t.Errorf("unexpected counts %+v", d)
+	}
+	if d.Low >= d.Delta || d.High <= d.Delta {
+		t.Errorf("interval should contain the delta: %+v", d)
+	}
+
+	if empty := pairedDelta(nil, nil); empty.PValue != 1 || empty.Significant {
+		t.Errorf("no pairs must not be significant: %+v", empty)
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":     `{"name":"x","seed":1,"games":1,"baseline":["random"],"candidates":["random"],"typo":1}`,
+		"no seeds":          `{"name":"x","baseline":["random"],"candidates":["random"]}`,
+		"empty model":       `{"name":"x","seeds":[1],"baseline":["random"],"candidates":[""]}`,
+		"model with comma":  `{"name":"x","seeds":[1],"baseline":["random"],"candidates":["a,b"]}`,
+		"unknown bot":       `{"name":"x","seeds":[1],"baseline":["random"],"candidates":["bot:gpt-9"]}`,
+		"seat out of range": `{"name":"x","seeds":[1],"baseline":["random","random"],"candidates":["random"],"seats":[3]}`,
+		"bad roles":         `{"name":"x","seeds":[1],"baseline":["random","random"],"candidates":["random"],"roles":["mafia","mafia"]}`,
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+const pinnedSpec = `{
+	"name": "test",
+	"seeds": [1, 2, 3],
+	"baseline": ["random", "random", "random", "random", "random", "random"],
+	"candidates": ["bot:aggressive-accuser"],
+	"seats": [2, 5],
+	"roles": ["villager", "mafia", "doctor", "sheriff", "villager", "mafia"]
+}`
+
+func TestRun_PairsSameSetup(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := Parse([]byte(pinnedSpec))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	report, err := Run(cfg, spec)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if report.Games != 3+3*2 {
+		t.Errorf("expected 9 games, got %d", report.Games)
+	}
+	model := report.Models[0]
+	if len(model.Pairs) != 6 || model.WinRate.Pairs != 6 {
+		t.Fatalf("expected 6 pairs, got %d", len(model.Pairs))
+	}
+	for _, pair := range model.Pairs {
+		want := map[int]string{2: "mafia", 5: "villager"}[pair.Seat]
+		if pair.Role != want {
+			t.Errorf("seed %d seat %d: role %s, want the pinned %s", pair.Seed, pair.Seat, pair.Role, want)
+		}
+		if pair.Trait == "" {
+			t.Errorf("seed %d seat %d: no persona recorded", pair.Seed, pair.Seat)
+		}
+	}
+	// only mafia seats count toward deception, only village seats toward vote accuracy
+	if model.DeceptionSuccess.Pairs > 3 || model.VoteAccuracy.Pairs > 3 {
+		t.Errorf("metrics counted the wrong seats: %+v %+v", model.DeceptionSuccess, model.VoteAccuracy)
+	}
+
+	// the same spec plays the same games
+	again, err := Run(cfg, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if again.Models[0].SurvivalRounds != model.SurvivalRounds {
+		t.Error("benchmark runs must be reproducible")
+	}
+
+	var buf bytes.Buffer
+	report.WriteText(&buf)
+	if !strings.Contains(buf.String(), "bot:aggressive-accuser vs baseline (6 pairs)") {
+		t.Errorf("unexpected text:\n%s", buf.String())
+	}
+}
+
+func TestPlan_AgentModels(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := Parse([]byte(`{"name":"x","seeds":[7],"baseline":["random","random","random","random","random","random"],"candidates":["llama3.2:1b"],"seats":[3]}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if _, err := Run(cfg, spec); err == nil {
+		t.Error("Run can't play an agent and must refuse")
+	}
+
+	games, err := Plan(spec)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	if len(games) != 2 || games[0].Seat != 0 || games[1].Seat != 3 {
+		t.Fatalf("expected the baseline and one swap, got %+v", games)
+	}
+	want := "ENGINE_SEAT_PROFILES=bot:random,bot:random,llama3.2:1b,bot:random,bot:random,bot:random"
+	if env := strings.Join(games[1].Env(), " "); !strings.Contains(env, "ENGINE_GAME_SEED=7") || !strings.Contains(env, want) {
+		t.Errorf("unexpected settings %s", env)
+	}
+}
+
+// Games played elsewhere and archived score as Run scores them.
+func TestScore_ArchivedGames(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	spec, err := Parse([]byte(pinnedSpec))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	store, err := archive.Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	if _, err := Score(store, spec); err == nil {
+		t.Error("expected error for games missing from the archive")
+	}
+
+	games, err := Plan(spec)
+	if err != nil {
+		t.Fatalf("Plan failed: %v", err)
+	}
+	for _, game := range games {
+		names := make([]string, len(game.Seats))
+		for i, profile := range game.Seats {
+			names[i], _ = bots.StrategyOf(profile)
+		}
+		result, err := sim.Run(cfg, sim.Game{Seed: game.Seed, Players: len(names), Strategies: names, PinnedRoles: game.Roles})
+		if err != nil {
+			t.Fatal(err)
+		}
+		archived := archive.NewGame(result.State, nil, result.Events)
+		archived.ID = fmt.Sprintf("live-%d", game.Game)
+		if err := store.Put(archived, result.Events); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	scored, err := Score(store, spec)
+	if err != nil {
+		t.Fatalf("Score failed: %v", err)
+	}
+	played, err := Run(cfg, spec)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if scored.Games != played.Games || !reflect.DeepEqual(scored.Models, played.Models) {
+		t.Errorf("archived games scored differently:\n%+v\n%+v", scored.Models[0], played.Models[0])
+	}
+}
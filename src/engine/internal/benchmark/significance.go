@@ -0,0 +1,96 @@
+package benchmark
+
+import (
+	"math"
+
+	"mafia-engine/internal/sim"
+)
+
+// alpha is the significance level of the summary.
+const alpha = 0.05
+
+// Delta compares one metric between the baseline and the candidate games,
+// pair by pair. Pairs where the metric doesn't apply (e.g. vote accuracy of
+// a mafia seat) are left out.
+type Delta struct {
+	Pairs     int     `json:"pairs"`
+	Baseline  float64 `json:"baseline"`  // mean over the pairs
+	Candidate float64 `json:"candidate"` // mean over the pairs
+	Delta     float64 `json:"delta"`     // mean of candidate - baseline
+	Low       float64 `json:"low"`       // 95% interval of the mean delta
+	High      float64 `json:"high"`
+
+	// pairs where the candidate did better or worse; ties are left out
+	Better int `json:"better"`
+	Worse  int `json:"worse"`
+
+	// PValue is the two-sided exact sign test on Better against Worse
+	// (for win rates that is McNemar's exact test)
+	PValue      float64 `json:"p_value"`
+	Significant bool    `json:"significant"` // PValue < 0.05
+}
+
+// pairedDelta builds the comparison from the paired values.
+func pairedDelta(baseline, candidate []float64) Delta {
+	d := Delta{Pairs: len(baseline)}
+	if d.Pairs == 0 {
+		d.PValue = 1
+		return d
+	}
+
+	diffs := make([]float64, d.Pairs)
+	for i := range baseline {
+		d.Baseline += baseline[i]
+		d.Candidate += candidate[i]
+		diffs[i] = candidate[i] - baseline[i]
+		switch {
+		case diffs[i] > 0:
+			d.Better++
+		case diffs[i] < 0:
+			d.Worse++
+		}
+	}
+	n := float64(d.Pairs)
+	d.Baseline /= n
+	d.Candidate /= n
+	d.Delta = d.Candidate - d.Baseline
+
+	// normal approximation for the interval; the sign test doesn't need it
+	var variance float64
+	for _, diff := range diffs {
+		variance += (diff - d.Delta) * (diff - d.Delta)
+	}
+	if d.Pairs > 1 {
+		variance /= n - 1
+	}
+	margin := sim.Z95 * math.Sqrt(variance/n)
+	d.Low, d.High = d.Delta-margin, d.Delta+margin
+
+	d.PValue = SignTest(d.Better, d.Worse)
+	d.Significant = d.PValue < alpha
+	return d
+}
+
+// SignTest returns the two-sided exact binomial p-value of seeing this split
+// of better and worse pairs if neither side is better (p = 0.5).
+func SignTest(better, worse int) float64 {
+	n := better + worse
+	if n == 0 {
+		return 1
+	}
+	k := min(better, worse)
+
+	// P(X <= k) for X ~ Binomial(n, 0.5), summed in log space for large n
+	var tail float64
+	for i := 0; i <= k; i++ {
+		tail += math.Exp(logChoose(n, i) - float64(n)*math.Ln2)
+	}
+	return math.Min(1, 2*tail)
+}
+
+func logChoose(n, k int) float64 {
+	a, _ := math.Lgamma(float64(n + 1))
+	b, _ := math.Lgamma(float64(k + 1))
+	c, _ := math.Lgamma(float64(n - k + 1))
+	return a - b - c
+}
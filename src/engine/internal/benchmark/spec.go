@@ -0,0 +1,196 @@
+// Package benchmark compares models on identical games. A spec fixes the
+// seeds, the seating and the baseline model of every seat; the runner plays
+// each seed once with the baseline and once more for every candidate model
+// swapped into one seat, everything else unchanged. The seed deals the same
+// names, roles and traits in both games, so the swapped seat's results can be
+// compared pair by pair (see cmd/benchmark and benchmarks/). Each pair checks
+// that the swapped seat kept its role and persona (its trait).
+//
+// Models are built-in bot strategies, named as strategies ("random") or as
+// the profiles the archive records ("bot:random"), or agents' model profiles
+// (e.g. "llama3.2:1b"). Run plays bot-only benchmarks headlessly through
+// package sim. Benchmarks with agents are played on live engines: Plan lists
+// every game with the engine settings that seat each profile in its reserved
+// seat (see tournament.Pairing.Env), and Score pairs the archived games.
+package benchmark
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/domain"
+)
+
+// Spec is one benchmark.
+type Spec struct {
+	Name        string `json:"name"`
+	Description string `json:"description,omitempty"`
+
+	// Seeds to play; or Games seeds starting at Seed
+	Seeds []int64 `json:"seeds,omitempty"`
+	Seed  int64   `json:"seed,omitempty"`
+	Games int     `json:"games,omitempty"`
+
+	RulesetFile string `json:"ruleset,omitempty"`
+	MaxRounds   int    `json:"max_rounds,omitempty"` // 0 = sim.DefaultMaxRounds
+
+	// Baseline is the model (bot strategy or "bot:" profile) of every seat, seat 1 first
+	Baseline []string `json:"baseline"`
+	// Candidates are swapped into one seat at a time
+	Candidates []string `json:"candidates"`
+	// Seats to swap, 1-based (default every seat)
+	Seats []int `json:"seats,omitempty"`
+	// Roles optionally pins the role of every seat instead of dealing from the seed
+	Roles []string `json:"roles,omitempty"`
+}
+
+// Load reads and validates a spec file.
+func Load(path string) (*Spec, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- spec files are chosen by the developer
+	if err != nil {
+		return nil, fmt.Errorf("failed to read benchmark %s: %w", path, err)
+	}
+	spec, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return spec, nil
+}
+
+// Parse decodes a JSON spec and validates it. Unknown fields are rejected,
+// so a typo doesn't silently change what is compared.
+func Parse(data []byte) (*Spec, error) {
+	var spec Spec
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&spec); err != nil {
+		return nil, fmt.Errorf("failed to parse benchmark: %w", err)
+	}
+	if err := spec.Validate(); err != nil {
+		return nil, err
+	}
+	return &spec, nil
+}
+
+// Validate checks the spec is complete and names known models.
+func (s *Spec) Validate() error {
+	if s.Name == "" {
+		return errors.New("benchmark name must not be empty")
+	}
+	if len(s.Seeds) == 0 && s.Games <= 0 {
+		return errors.New("benchmark needs seeds, or games and a first seed")
+	}
+	if len(s.Seeds) > 0 && s.Games > 0 {
+		return errors.New("set either seeds or games, not both")
+	}
+	if len(s.Baseline) == 0 {
+		return errors.New("benchmark needs a baseline model for every seat")
+	}
+	if len(s.Candidates) == 0 {
+		return errors.New("benchmark needs candidate models")
+	}
+
+	for _, model := range append(append([]string{}, s.Baseline...), s.Candidates...) {
+		if model == "" || strings.Contains(model, ",") {
+			return fmt.Errorf("model %q: models must not be empty or contain commas", model)
+		}
+		if name, ok := bots.StrategyOf(model); ok {
+			if _, err := bots.NewStrategy(name); err != nil {
+				return fmt.Errorf("model %q: %w", model, err)
+			}
+		}
+	}
+	for _, seat := range s.Seats {
+		if seat < 1 || seat > len(s.Baseline) {
+			return fmt.Errorf("seat %d is not in 1..%d", seat, len(s.Baseline))
+		}
+	}
+	if len(s.Roles) > 0 {
+		if _, _, err := s.PinnedRoles(); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// profile returns the model profile of a model: bot strategies may be named
+// without the bot profile prefix, anything else is an agent's profile.
+func profile(model string) string {
+	if _, ok := bots.StrategyOf(model); ok {
+		return model
+	}
+	if _, err := bots.NewStrategy(model); err == nil {
+		return bots.Profile(model)
+	}
+	return model
+}
+
+// strategies returns the bot strategies of the seats, or an error if an
+// agent plays one of them.
+func strategies(profiles []string) ([]string, error) {
+	names := make([]string, len(profiles))
+	for i, profile := range profiles {
+		name, ok := bots.StrategyOf(profile)
+		if !ok {
+			return nil, fmt.Errorf("%s is played by an agent: play the benchmark live (see Plan and Score)", profile)
+		}
+		names[i] = name
+	}
+	return names, nil
+}
+
+// GameSeeds returns the seeds to play.
+func (s *Spec) GameSeeds() []int64 {
+	if len(s.Seeds) > 0 {
+		return s.Seeds
+	}
+	seeds := make([]int64, s.Games)
+	for i := range seeds {
+		seeds[i] = s.Seed + int64(i)
+	}
+	return seeds
+}
+
+// SwapSeats returns the seats candidates are swapped into.
+func (s *Spec) SwapSeats() []int {
+	if len(s.Seats) > 0 {
+		return s.Seats
+	}
+	seats := make([]int, len(s.Baseline))
+	for i := range seats {
+		seats[i] = i + 1
+	}
+	return seats
+}
+
+// PinnedRoles returns the pinned roles by seat and the distribution they
+// deal, or nil if the seed deals the roles.
+func (s *Spec) PinnedRoles() (map[int]domain.Role, *domain.RoleCounts, error) {
+	if len(s.Roles) == 0 {
+		return nil, nil, nil
+	}
+	if len(s.Roles) != len(s.Baseline) {
+		return nil, nil, fmt.Errorf("roles lists %d seats, baseline %d", len(s.Roles), len(s.Baseline))
+	}
+
+	pinned := make(map[int]domain.Role, len(s.Roles))
+	roles := make([]domain.Role, len(s.Roles))
+	for i, name := range s.Roles {
+		role, ok := domain.ParseRole(name)
+		if !ok {
+			return nil, nil, fmt.Errorf("seat %d: unknown role %q", i+1, name)
+		}
+		pinned[i+1] = role
+		roles[i] = role
+	}
+	counts := domain.RoleCountsOf(roles)
+	if err := counts.Validate(len(s.Roles)); err != nil {
+		return nil, nil, err
+	}
+	return pinned, &counts, nil
+}
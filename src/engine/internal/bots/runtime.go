@@ -0,0 +1,177 @@
+package bots
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/rand/v2"
+	"sync"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
+// Bot is one in-process player: its strategy, knowledge and RNG.
+type Bot struct {
+	Strategy Strategy
+	View     *View
+	rng      *rand.Rand
+}
+
+// Runtime drives bots for a single game.
+// It consumes the engine event stream (HandleMessage is a kafka.HandlerFunc)
+// and publishes bot actions to PlayerActionsTopic, where the engine's consumer
+// picks them up like actions from any other player.
+type Runtime struct {
+	mu sync.Mutex
+
+	gameID     string
+	producer   kafka.Producer
+	strategies []Strategy
+	seed       int64
+
+	// bots by player ID, created when the game starts
+	bots  map[string]*Bot
+	order []string // seat order, for deterministic dispatch
+}
+
+// NewRuntime creates a bot runtime for the given game.
+// Strategies are dealt to seats round-robin; a nil strategy leaves its seats to
+// other agents. seed makes bot decisions reproducible.
+func NewRuntime(gameID string, producer kafka.Producer, strategies []Strategy, seed int64) (*Runtime, error) {
+	if gameID == "" {
+		return nil, errors.New("game ID must not be empty")
+	}
+	if producer == nil {
+		return nil, errors.New("producer must not be nil")
+	}
+	if len(strategies) == 0 {
+		return nil, errors.New("at least one strategy is required")
+	}
+
+	return &Runtime{
+		gameID:     gameID,
+		producer:   producer,
+		strategies: strategies,
+		seed:       seed,
+		bots:       make(map[string]*Bot),
+	}, nil
+}
+
+// Bots returns the bots in seat order (empty before the game started).
+func (r *Runtime) Bots() []*Bot {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	bots := make([]*Bot, 0, len(r.order))
+	for _, id := range r.order {
+		bots = append(bots, r.bots[id])
+	}
+	return bots
+}
+
+// HandleMessage processes one engine event and publishes the bots' reactions.
+// Events from other games are ignored.
+func (r *Runtime) HandleMessage(ctx context.Context, msg kafka.Message) error {
+	ev, err := events.DeserializeEngineEvent(msg.Value)
+	if err != nil {
+		return err
+	}
+
+	header, ok := ev.(events.Event)
+	if !ok || header.Header().GameID != r.gameID {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if started, ok := ev.(*events.GameStarted); ok && len(r.bots) == 0 {
+		r.seat(started.Players)
+	}
+
+	for _, id := range r.order {
+		bot := r.bots[id]
+		if !viewerOf(bot.View).CanSee(ev) {
+			continue
+		}
+
+		bot.View.observe(ev)
+		if bot.View.Over || !bot.View.IsAlive() {
+			continue
+		}
+
+		for _, action := range bot.Strategy.Act(bot.View, ev, bot.rng) {
+			if err := r.submit(ctx, bot.View, action); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// seat creates one bot per player, dealing strategies round-robin by seat.
+func (r *Runtime) seat(playerIDs []string) {
+	for i, id := range playerIDs {
+		strategy := r.strategies[i%len(r.strategies)]
+		if strategy == nil {
+			continue // played by an agent
+		}
+		r.bots[id] = &Bot{
+			Strategy: strategy,
+			View:     newView(r.gameID, id),
+			rng:      rand.New(rand.NewPCG(uint64(r.seed), uint64(i)+1)),
+		}
+		r.order = append(r.order, id)
+	}
+}
+
+// submit converts an action into a player event and publishes it.
+func (r *Runtime) submit(ctx context.Context, view *View, action Action) error {
+	base := events.BaseEvent{GameID: view.GameID}
+
+	var event any
+	switch action.Kind {
+	case ActionVote:
+		base.Type = events.TypeVoteSubmitted
+		event = &events.VoteSubmitted{BaseEvent: base, VoterID: view.PlayerID, TargetID: action.Target}
+	case ActionNight:
+		base.Type = events.TypeNightAction
+		event = &events.NightAction{BaseEvent: base, Role: view.Role.String(), ActorID: view.PlayerID, TargetID: action.Target}
+	case ActionChat:
+		base.Type = events.TypeAllChatMessage
+		event = &events.AllChatMessage{BaseEvent: base, SenderID: view.PlayerID, Message: action.Message}
+	case ActionMafiaChat:
+		base.Type = events.TypeMafiaChatMessage
+		event = &events.MafiaChatMessage{BaseEvent: base, SenderID: view.PlayerID, Message: action.Message}
+	default:
+		return fmt.Errorf("unknown action kind %d", action.Kind)
+	}
+
+	data, err := events.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal bot action: %w", err)
+	}
+
+	msg := kafka.Message{
+		Topic: kafka.PlayerActionsTopic,
+		Key:   kafka.GameKey(view.GameID),
+		Value: data,
+	}
+	if err := r.producer.Publish(ctx, msg); err != nil {
+		return fmt.Errorf("failed to publish bot action: %w", err)
+	}
+
+	view.recordAction(action)
+	return nil
+}
+
+// viewerOf returns the visibility viewer for a bot (role is unknown until RoleAssigned).
+func viewerOf(view *View) events.Viewer {
+	faction := ""
+	if view.Role != domain.RoleUnknown {
+		faction = view.Role.Faction().String()
+	}
+	return events.Viewer{PlayerID: view.PlayerID, Faction: faction}
+}
@@ -0,0 +1,334 @@
+package bots
+
+import (
+	"context"
+	"testing"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
+type fakeProducer struct {
+	messages []kafka.Message
+}
+
+func (p *fakeProducer) Publish(_ context.Context, msg kafka.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *fakeProducer) Close() error { return nil }
+
+const testGameID = "game-1"
+
+// seats: p1 mafia, p2 mafia, p3 doctor, p4 sheriff, p5 villager, p6 villager
+var testRoles = map[string]string{
+	"p1": "mafia", "p2": "mafia", "p3": "doctor", "p4": "sheriff", "p5": "villager", "p6": "villager",
+}
+
+func message(t *testing.T, event any) kafka.Message {
+	t.Helper()
+	data, err := events.Marshal(event)
+	if err != nil {
+		t.Fatalf("failed to marshal: %v", err)
+	}
+	return kafka.Message{Topic: kafka.EngineEventsTopic, Key: kafka.GameKey(testGameID), Value: data}
+}
+
+func base(eventType string) events.BaseEvent {
+	return events.BaseEvent{Type: eventType, GameID: testGameID}
+}
+
+// startGame feeds a runtime the start-of-game events the engine would emit.
+func startGame(t *testing.T, r *Runtime) {
+	t.Helper()
+	ids := []string{"p1", "p2", "p3", "p4", "p5", "p6"}
+	feed(t, r, &events.GameStarted{BaseEvent: base(events.TypeGameStarted), Players: ids})
+
+	roster := &events.Roster{BaseEvent: base(events.TypeRoster)}
+	for i, id := range ids {
+		roster.Players = append(roster.Players, events.RosterEntry{ID: id, Name: "Name-" + id, Seat: i + 1, Alive: true})
+	}
+	feed(t, r, roster)
+
+	for _, id := range ids {
+		feed(t, r, &events.RoleAssigned{BaseEvent: base(events.TypeRoleAssigned), PlayerID: id, Role: testRoles[id]})
+	}
+	for _, id := range []string{"p1", "p2"} {
+		feed(t, r, &events.FactionRevealed{
+			BaseEvent: base(events.TypeFactionRevealed),
+			PlayerID:  id,
+			Faction:   events.FactionMafia,
+			Members:   []events.FactionMember{{ID: "p1"}, {ID: "p2"}},
+		})
+	}
+}
+
+func feed(t *testing.T, r *Runtime, event any) {
+	t.Helper()
+	if err := r.HandleMessage(context.Background(), message(t, event)); err != nil {
+		t.Fatalf("HandleMessage failed: %v", err)
+	}
+}
+
+func phase(newPhase string, round int) *events.PhaseChanged {
+	ev := &events.PhaseChanged{BaseEvent: base(events.TypePhaseChanged), NewPhase: newPhase}
+	ev.Round = round
+	return ev
+}
+
+func newTestRuntime(t *testing.T, producer *fakeProducer, names ...string) *Runtime {
+	t.Helper()
+	strategies, err := NewStrategies(names)
+	if err != nil {
+		t.Fatalf("NewStrategies failed: %v", err)
+	}
+	r, err := NewRuntime(testGameID, producer, strategies, 42)
+	if err != nil {
+		t.Fatalf("NewRuntime failed: %v", err)
+	}
+	return r
+}
+
+// decode returns the published player actions.
+func decode(t *testing.T, messages []kafka.Message) []any {
+	t.Helper()
+	var out []any
+	for _, msg := range messages {
+		if msg.Topic != kafka.PlayerActionsTopic || string(msg.Key) != testGameID {
+			t.Fatalf("unexpected message destination: topic=%s key=%s", msg.Topic, msg.Key)
+		}
+		ev, err := events.Deserialize(msg.Value)
+		if err != nil {
+			t.Fatalf("failed to decode bot action: %v", err)
+		}
+		out = append(out, ev)
+	}
+	return out
+}
+
+func TestNewRuntime_Validation(t *testing.T) {
+	strategies := []Strategy{&RandomStrategy{}}
+
+	if _, err := NewRuntime("", &fakeProducer{}, strategies, 1); err == nil {
+		t.Error("expected error for empty game ID")
+	}
+	if _, err := NewRuntime(testGameID, nil, strategies, 1); err == nil {
+		t.Error("expected error for nil producer")
+	}
+	if _, err := NewRuntime(testGameID, &fakeProducer{}, nil, 1); err == nil {
+		t.Error("expected error without strategies")
+	}
+}
+
+func TestRuntime_SeatsBotsAndKnowledge(t *testing.T) {
+	producer := &fakeProducer{}
+	r := newTestRuntime(t, producer, "random", "sheriff-follower")
+	startGame(t, r)
+
+	bots := r.Bots()
+	if len(bots) != 6 {
+		t.Fatalf("expected 6 bots, got %d", len(bots))
+	}
+	if bots[0].Strategy.Name() != "random" || bots[1].Strategy.Name() != "sheriff-follower" {
+		t.Errorf("strategies not dealt round-robin: %s, %s", bots[0].Strategy.Name(), bots[1].Strategy.Name())
+	}
+
+	for _, bot := range bots {
+		id := bot.View.PlayerID
+		if bot.View.Role.String() != testRoles[id] {
+			t.Errorf("%s: expected role %s, got %s", id, testRoles[id], bot.View.Role)
+		}
+		// private events must not leak: only mafia learn their mates
+		if knowsMates := len(bot.View.Mates) > 0; knowsMates != bot.View.IsMafia() {
+			t.Errorf("%s: knows mates = %v", id, knowsMates)
+		}
+	}
+
+	if len(producer.messages) != 0 {
+		t.Errorf("expected no actions before the first phase, got %d", len(producer.messages))
+	}
+}
+
+func TestRuntime_LeavesAgentSeats(t *testing.T) {
+	r, err := NewRuntime(testGameID, &fakeProducer{}, []Strategy{&RandomStrategy{}, nil}, 42)
+	if err != nil {
+		t.Fatalf("NewRuntime failed: %v", err)
+	}
+	startGame(t, r)
+
+	bots := r.Bots()
+	if len(bots) != 3 {
+		t.Fatalf("expected bots in every other seat, got %d", len(bots))
+	}
+	for _, bot := range bots {
+		if bot.View.PlayerID == "p2" {
+			t.Error("a bot took the agent's seat p2")
+		}
+	}
+}
+
+func TestRuntime_IgnoresOtherGames(t *testing.T) {
+	r := newTestRuntime(t, &fakeProducer{}, "random")
+
+	other := &events.GameStarted{BaseEvent: events.BaseEvent{Type: events.TypeGameStarted, GameID: "other"}, Players: []string{"x"}}
+	feed(t, r, other)
+
+	if len(r.Bots()) != 0 {
+		t.Error("bots must not be seated for another game")
+	}
+}
+
+func TestRuntime_NightActions(t *testing.T) {
+	producer := &fakeProducer{}
+	r := newTestRuntime(t, producer, "random")
+	startGame(t, r)
+
+	feed(t, r, phase(domain.PhaseNight.String(), 1))
+
+	actors := map[string]*events.NightAction{}
+	for _, ev := range decode(t, producer.messages) {
+		action, ok := ev.(*events.NightAction)
+		if !ok {
+			t.Fatalf("expected only night actions, got %T", ev)
+		}
+		actors[action.ActorID] = action
+	}
+
+	for _, id := range []string{"p1", "p2", "p3", "p4"} {
+		if actors[id] == nil {
+			t.Errorf("expected a night action from %s", id)
+		}
+	}
+	for _, id := range []string{"p5", "p6"} {
+		if actors[id] != nil {
+			t.Errorf("villager %s must not act at night", id)
+		}
+	}
+	for _, id := range []string{"p1", "p2"} {
+		if target := actors[id].TargetID; target == "p1" || target == "p2" {
+			t.Errorf("mafia %s targeted a mate: %s", id, target)
+		}
+	}
+	if actors["p4"].TargetID == "p4" {
+		t.Error("sheriff must not investigate themselves")
+	}
+}
+
+func TestRuntime_DeadBotsStayQuiet(t *testing.T) {
+	producer := &fakeProducer{}
+	r := newTestRuntime(t, producer, "random")
+	startGame(t, r)
+
+	feed(t, r, &events.PlayerEliminated{BaseEvent: base(events.TypePlayerEliminated), PlayerID: "p5"})
+	feed(t, r, phase(domain.PhaseVoting.String(), 1))
+
+	for _, ev := range decode(t, producer.messages) {
+		vote := ev.(*events.VoteSubmitted)
+		if vote.VoterID == "p5" {
+			t.Error("eliminated bot must not vote")
+		}
+		if vote.TargetID == "p5" || vote.TargetID == vote.VoterID {
+			t.Errorf("invalid vote target %s from %s", vote.TargetID, vote.VoterID)
+		}
+	}
+	if len(producer.messages) != 5 {
+		t.Errorf("expected 5 votes, got %d", len(producer.messages))
+	}
+}
+
+func TestRuntime_SheriffClaimIsFollowed(t *testing.T) {
+	producer := &fakeProducer{}
+	r := newTestRuntime(t, producer, "sheriff-follower")
+	startGame(t, r)
+
+	result := &events.InvestigationResult{
+		BaseEvent: base(events.TypeInvestigation),
+		PlayerID:  "p4",
+		TargetID:  "p1",
+		Faction:   events.FactionMafia,
+	}
+	feed(t, r, result)
+
+	actions := decode(t, producer.messages)
+	if len(actions) != 1 {
+		t.Fatalf("expected the sheriff's claim only, got %d actions", len(actions))
+	}
+	claim, ok := actions[0].(*events.AllChatMessage)
+	if !ok || claim.SenderID != "p4" {
+		t.Fatalf("expected a public claim from the sheriff, got %+v", actions[0])
+	}
+
+	// the engine echoes chat back to everyone
+	feed(t, r, &events.AllChatMessage{BaseEvent: base(events.TypeAllChatMessage), SenderID: claim.SenderID, Message: claim.Message})
+	producer.messages = nil
+	feed(t, r, phase(domain.PhaseVoting.String(), 1))
+
+	for _, ev := range decode(t, producer.messages) {
+		vote := ev.(*events.VoteSubmitted)
+		if vote.VoterID == "p1" || vote.VoterID == "p2" {
+			continue
+		}
+		if vote.TargetID != "p1" {
+			t.Errorf("%s ignored the claim and voted %s", vote.VoterID, vote.TargetID)
+		}
+	}
+}
+
+func TestRuntime_AccusationsDriveVotes(t *testing.T) {
+	producer := &fakeProducer{}
+	r := newTestRuntime(t, producer, "aggressive-accuser")
+	startGame(t, r)
+
+	feed(t, r, phase(domain.PhaseDay.String(), 1))
+	for _, ev := range decode(t, producer.messages) {
+		if _, ok := ev.(*events.AllChatMessage); !ok {
+			t.Fatalf("expected accusations during the day, got %T", ev)
+		}
+	}
+	if len(producer.messages) != 6 {
+		t.Fatalf("expected one accusation per bot, got %d", len(producer.messages))
+	}
+
+	// three public accusations against p6
+	for _, sender := range []string{"p1", "p2", "p3"} {
+		feed(t, r, &events.AllChatMessage{BaseEvent: base(events.TypeAllChatMessage), SenderID: sender, Message: AccusePrefix + " p6"})
+	}
+	producer.messages = nil
+	feed(t, r, phase(domain.PhaseVoting.String(), 1))
+
+	for _, ev := range decode(t, producer.messages) {
+		vote := ev.(*events.VoteSubmitted)
+		if vote.VoterID != "p6" && vote.TargetID != "p6" {
+			t.Errorf("%s voted %s instead of the most accused player", vote.VoterID, vote.TargetID)
+		}
+	}
+}
+
+func TestRuntime_Deterministic(t *testing.T) {
+	run := func() []string {
+		producer := &fakeProducer{}
+		r := newTestRuntime(t, producer, "random", "aggressive-accuser")
+		startGame(t, r)
+		feed(t, r, phase(domain.PhaseNight.String(), 1))
+		feed(t, r, phase(domain.PhaseDay.String(), 1))
+		feed(t, r, phase(domain.PhaseVoting.String(), 1))
+
+		var out []string
+		for _, msg := range producer.messages {
+			out = append(out, string(msg.Value))
+		}
+		return out
+	}
+
+	first, second := run(), run()
+	if len(first) != len(second) {
+		t.Fatalf("runs differ in length: %d vs %d", len(first), len(second))
+	}
+	for i := range first {
+		if first[i] != second[i] {
+			t.Errorf("action %d differs:\n%s\n%s", i, first[i], second[i])
+		}
+	}
+}
@@ -0,0 +1,149 @@
+package bots
+
+import (
+	"fmt"
+	"math/rand/v2"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+)
+
+// --- random --- //
+
+// RandomStrategy picks legal targets uniformly at random and never talks.
+// It is the baseline every other strategy should beat.
+type RandomStrategy struct{}
+
+func (s *RandomStrategy) Name() string { return "random" }
+
+func (s *RandomStrategy) Act(view *View, ev any, rng *rand.Rand) []Action {
+	switch phaseOpened(ev) {
+	case domain.PhaseNight:
+		return nightAction(randomNightTarget(view, rng))
+	case domain.PhaseVoting:
+		return voteAction(pick(rng, view.AliveOthers(view.IsMafia())))
+	}
+	return nil
+}
+
+// --- sheriff follower --- //
+
+// SheriffFollowerStrategy trusts public sheriff claims.
+//   - sheriff: publishes every investigation result as a claim
+//   - village: votes claimed mafia, never votes claimed village
+//   - doctor: protects the claimed sheriff
+//   - mafia: kills the claimed sheriff first, votes like a villager otherwise
+type SheriffFollowerStrategy struct{}
+
+func (s *SheriffFollowerStrategy) Name() string { return "sheriff-follower" }
+
+func (s *SheriffFollowerStrategy) Act(view *View, ev any, rng *rand.Rand) []Action {
+	// Sheriff goes public as soon as the result arrives
+	if result, ok := ev.(*events.InvestigationResult); ok && view.IsAlive() {
+		return []Action{{
+			Kind:    ActionChat,
+			Message: fmt.Sprintf("%s %s %s - I am the sheriff", ClaimPrefix, result.TargetID, result.Faction),
+		}}
+	}
+
+	switch phaseOpened(ev) {
+	case domain.PhaseNight:
+		claimant := s.claimant(view)
+		switch view.Role {
+		case domain.RoleMafia:
+			if claimant != "" && !view.Mates[claimant] {
+				return nightAction(claimant)
+			}
+		case domain.RoleDoctor:
+			if claimant != "" && claimant != view.LastSave {
+				return nightAction(claimant)
+			}
+		}
+		return nightAction(randomNightTarget(view, rng))
+
+	case domain.PhaseVoting:
+		candidates := view.AliveOthers(view.IsMafia())
+		if !view.IsMafia() {
+			for _, id := range candidates {
+				if view.Claims[id] == events.FactionMafia {
+					return voteAction(id)
+				}
+			}
+		}
+		var unclear []string
+		for _, id := range candidates {
+			if view.Claims[id] != events.FactionVillage {
+				unclear = append(unclear, id)
+			}
+		}
+		if len(unclear) == 0 {
+			unclear = candidates
+		}
+		return voteAction(pick(rng, unclear))
+	}
+	return nil
+}
+
+// claimant returns who the bot believes is the sheriff ("" if unknown).
+func (s *SheriffFollowerStrategy) claimant(view *View) string {
+	if view.Role == domain.RoleSheriff {
+		return view.PlayerID
+	}
+	if !view.Alive[view.ClaimedSheriff] {
+		return ""
+	}
+	return view.ClaimedSheriff
+}
+
+// --- aggressive accuser --- //
+
+// AggressiveAccuserStrategy picks a suspect every day, accuses them in public
+// chat and then votes with the loudest accusation.
+//   - village: suspects a player the sheriff result (own or claimed) marks as mafia, else random
+//   - mafia: accuses a random villager to steer the vote
+type AggressiveAccuserStrategy struct{}
+
+func (s *AggressiveAccuserStrategy) Name() string { return "aggressive-accuser" }
+
+func (s *AggressiveAccuserStrategy) Act(view *View, ev any, rng *rand.Rand) []Action {
+	switch phaseOpened(ev) {
+	case domain.PhaseNight:
+		return nightAction(randomNightTarget(view, rng))
+
+	case domain.PhaseDay:
+		suspect := s.suspect(view, rng)
+		if suspect == "" {
+			return nil
+		}
+		return []Action{{
+			Kind:    ActionChat,
+			Message: fmt.Sprintf("%s %s - I don't trust %s", AccusePrefix, suspect, view.Names[suspect]),
+		}}
+
+	case domain.PhaseVoting:
+		candidates := view.AliveOthers(view.IsMafia())
+		best, bestCount := "", 0
+		for _, id := range candidates {
+			if view.Accusations[id] > bestCount {
+				best, bestCount = id, view.Accusations[id]
+			}
+		}
+		if best == "" {
+			best = pick(rng, candidates)
+		}
+		return voteAction(best)
+	}
+	return nil
+}
+
+func (s *AggressiveAccuserStrategy) suspect(view *View, rng *rand.Rand) string {
+	candidates := view.AliveOthers(view.IsMafia())
+	if !view.IsMafia() {
+		for _, id := range candidates {
+			if view.Investigations[id] == events.FactionMafia || view.Claims[id] == events.FactionMafia {
+				return id
+			}
+		}
+	}
+	return pick(rng, candidates)
+}
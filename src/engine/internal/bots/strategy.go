@@ -0,0 +1,168 @@
+// Package bots is an in-process runtime of rule-based players.
+// Bots read the engine event stream and submit actions through the
+// normal player path (PlayerActionsTopic -> Engine.HandleMessage),
+// so a whole game can run without external agents (ENGINE_AGENT_MODE=mock).
+package bots
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"sort"
+	"strings"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+)
+
+// ActionKind is what a bot wants to do.
+type ActionKind int
+
+const (
+	ActionVote ActionKind = iota
+	ActionNight
+	ActionChat
+	ActionMafiaChat
+)
+
+// Action is a single intent produced by a strategy.
+// Target is used by votes and night actions, Message by chat.
+type Action struct {
+	Kind    ActionKind
+	Target  string
+	Message string
+}
+
+// Strategy decides how a bot plays.
+// Act is called for every event the bot can see, after its view was updated.
+// Strategies must only use the view and rng, so games are reproducible.
+type Strategy interface {
+	Name() string
+	Act(view *View, ev any, rng *rand.Rand) []Action
+}
+
+// strategyFactories holds the built-in strategies by name.
+var strategyFactories = map[string]func() Strategy{
+	"random":             func() Strategy { return &RandomStrategy{} },
+	"sheriff-follower":   func() Strategy { return &SheriffFollowerStrategy{} },
+	"aggressive-accuser": func() Strategy { return &AggressiveAccuserStrategy{} },
+}
+
+// NewStrategy returns the built-in strategy with the given name.
+func NewStrategy(name string) (Strategy, error) {
+	factory, ok := strategyFactories[name]
+	if !ok {
+		return nil, fmt.Errorf("unknown bot strategy %q (available: %v)", name, StrategyNames())
+	}
+	return factory(), nil
+}
+
+// ProfilePrefix starts the model profile of a seat played by a built-in bot.
+const ProfilePrefix = "bot:"
+
+// Profile returns the model profile of a seat played by the named strategy,
+// e.g. "bot:random".
+func Profile(strategy string) string {
+	return ProfilePrefix + strategy
+}
+
+// StrategyOf returns the strategy a bot's model profile names, or false if
+// the profile is not a bot's.
+func StrategyOf(profile string) (string, bool) {
+	return strings.CutPrefix(profile, ProfilePrefix)
+}
+
+// NewStrategies resolves a list of strategy names.
+func NewStrategies(names []string) ([]Strategy, error) {
+	strategies := make([]Strategy, 0, len(names))
+	for _, name := range names {
+		strategy, err := NewStrategy(name)
+		if err != nil {
+			return nil, err
+		}
+		strategies = append(strategies, strategy)
+	}
+	return strategies, nil
+}
+
+// StrategyNames returns the names of all built-in strategies, sorted.
+func StrategyNames() []string {
+	names := make([]string, 0, len(strategyFactories))
+	for name := range strategyFactories {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// --- helpers shared by strategies --- //
+
+// phaseOpened returns the phase that ev opens, or PhaseUnknown.
+func phaseOpened(ev any) domain.Phase {
+	changed, ok := ev.(*events.PhaseChanged)
+	if !ok {
+		return domain.PhaseUnknown
+	}
+	phase, _ := domain.ParsePhase(changed.NewPhase)
+	return phase
+}
+
+// pick returns a random element, or "" for an empty slice.
+func pick(rng *rand.Rand, ids []string) string {
+	if len(ids) == 0 {
+		return ""
+	}
+	return ids[rng.IntN(len(ids))]
+}
+
+// without returns ids minus the excluded ones.
+func without(ids []string, exclude ...string) []string {
+	var out []string
+	for _, id := range ids {
+		skip := false
+		for _, ex := range exclude {
+			if id == ex {
+				skip = true
+				break
+			}
+		}
+		if !skip {
+			out = append(out, id)
+		}
+	}
+	return out
+}
+
+// randomNightTarget picks a legal night target for the bot's role.
+func randomNightTarget(view *View, rng *rand.Rand) string {
+	switch view.Role {
+	case domain.RoleMafia:
+		return pick(rng, view.AliveOthers(true))
+	case domain.RoleDoctor:
+		// doctor may save themselves, but not the same player twice in a row
+		candidates := append(view.AliveOthers(false), view.PlayerID)
+		return pick(rng, without(candidates, view.LastSave))
+	case domain.RoleSheriff:
+		if view.Investigated {
+			return ""
+		}
+		return pick(rng, view.AliveOthers(false))
+	default:
+		return ""
+	}
+}
+
+// nightAction wraps a target in an action, or returns nil if there's nothing to do.
+func nightAction(target string) []Action {
+	if target == "" {
+		return nil
+	}
+	return []Action{{Kind: ActionNight, Target: target}}
+}
+
+// voteAction wraps a target in an action, or returns nil if there's nothing to do.
+func voteAction(target string) []Action {
+	if target == "" {
+		return nil
+	}
+	return []Action{{Kind: ActionVote, Target: target}}
+}
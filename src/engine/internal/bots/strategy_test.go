@@ -0,0 +1,69 @@
+package bots
+
+import (
+	"math/rand/v2"
+	"testing"
+
+	"mafia-engine/internal/domain"
+)
+
+func TestNewStrategy(t *testing.T) {
+	for _, name := range StrategyNames() {
+		strategy, err := NewStrategy(name)
+		if err != nil {
+			t.Fatalf("NewStrategy(%q) failed: %v", name, err)
+		}
+		if strategy.Name() != name {
+			t.Errorf("expected name %q, got %q", name, strategy.Name())
+		}
+	}
+
+	if _, err := NewStrategy("telepath"); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+	if _, err := NewStrategies([]string{"random", "telepath"}); err == nil {
+		t.Error("expected error for a list with an unknown strategy")
+	}
+}
+
+func TestProfile(t *testing.T) {
+	if name, ok := StrategyOf(Profile("random")); !ok || name != "random" {
+		t.Errorf("round trip gave %q %v", name, ok)
+	}
+	if _, ok := StrategyOf("llama3.2:1b"); ok {
+		t.Error("an LLM profile is not a bot's")
+	}
+}
+
+func TestRandomNightTarget_DoctorDoesNotRepeat(t *testing.T) {
+	view := newView(testGameID, "p1")
+	view.Role = domain.RoleDoctor
+	view.Seats = []string{"p1", "p2"}
+	view.Alive["p1"], view.Alive["p2"] = true, true
+	view.LastSave = "p2"
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	for i := 0; i < 20; i++ {
+		if target := randomNightTarget(view, rng); target != "p1" {
+			t.Fatalf("doctor repeated last save: %s", target)
+		}
+	}
+}
+
+func TestRandomNightTarget_SheriffInvestigatesOnce(t *testing.T) {
+	view := newView(testGameID, "p1")
+	view.Role = domain.RoleSheriff
+	view.Seats = []string{"p1", "p2"}
+	view.Alive["p1"], view.Alive["p2"] = true, true
+
+	rng := rand.New(rand.NewPCG(1, 1))
+	target := randomNightTarget(view, rng)
+	if target != "p2" {
+		t.Fatalf("expected sheriff to investigate p2, got %q", target)
+	}
+
+	view.recordAction(Action{Kind: ActionNight, Target: target})
+	if target := randomNightTarget(view, rng); target != "" {
+		t.Errorf("sheriff investigated twice: %s", target)
+	}
+}
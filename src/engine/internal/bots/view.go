@@ -0,0 +1,179 @@
+package bots
+
+import (
+	"strings"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+)
+
+// Chat conventions used by the built-in strategies to share information.
+// LLM agents ignore these prefixes; bots parse them from public chat.
+const (
+	// "[claim] <target-id> <faction>" - a sheriff publishing an investigation result
+	ClaimPrefix = "[claim]"
+
+	// "[accuse] <target-id>" - a player pushing a suspect for the next vote
+	AccusePrefix = "[accuse]"
+)
+
+// View is everything a bot knows about the game.
+// It is built only from events the bot is allowed to see.
+type View struct {
+	GameID   string
+	PlayerID string
+	Role     domain.Role
+	Round    int
+	Phase    domain.Phase
+	Over     bool
+
+	// Seats holds player IDs in seat order, Alive their status
+	Seats []string
+	Names map[string]string
+	Alive map[string]bool
+
+	// Mates are known faction mates (from FactionRevealed)
+	Mates map[string]bool
+
+	// Investigations are this bot's own sheriff results: target -> faction
+	Investigations map[string]string
+	Investigated   bool // the sheriff has used their investigation
+
+	// LastSave is the doctor's previous save (can't repeat two nights in a row)
+	LastSave    string
+	tonightSave string
+
+	// Claims are public sheriff claims: target -> faction
+	// ClaimedSheriff is the last player who claimed to be the sheriff
+	Claims         map[string]string
+	ClaimedSheriff string
+
+	// Accusations counts accusations per target in the current day
+	Accusations map[string]int
+}
+
+func newView(gameID, playerID string) *View {
+	return &View{
+		GameID:         gameID,
+		PlayerID:       playerID,
+		Names:          make(map[string]string),
+		Alive:          make(map[string]bool),
+		Mates:          make(map[string]bool),
+		Investigations: make(map[string]string),
+		Claims:         make(map[string]string),
+		Accusations:    make(map[string]int),
+	}
+}
+
+// IsAlive returns true if the bot itself is still in the game.
+func (v *View) IsAlive() bool {
+	return v.Alive[v.PlayerID]
+}
+
+// IsMafia returns true if the bot plays for the mafia.
+func (v *View) IsMafia() bool {
+	return v.Role.IsMafiaTeam()
+}
+
+// AliveOthers returns alive players other than the bot, in seat order.
+// If excludeMates is set, known faction mates are left out too.
+func (v *View) AliveOthers(excludeMates bool) []string {
+	var ids []string
+	for _, id := range v.Seats {
+		if id == v.PlayerID || !v.Alive[id] {
+			continue
+		}
+		if excludeMates && v.Mates[id] {
+			continue
+		}
+		ids = append(ids, id)
+	}
+	return ids
+}
+
+// observe updates the view with one event.
+func (v *View) observe(ev any) {
+	switch e := ev.(type) {
+	case *events.GameStarted:
+		v.Seats = append([]string(nil), e.Players...)
+		for _, id := range e.Players {
+			v.Alive[id] = true
+		}
+
+	case *events.Roster:
+		v.Seats = v.Seats[:0]
+		for _, entry := range e.Players {
+			v.Seats = append(v.Seats, entry.ID)
+			v.Names[entry.ID] = entry.Name
+			v.Alive[entry.ID] = entry.Alive
+		}
+
+	case *events.RoleAssigned:
+		if role, ok := domain.ParseRole(e.Role); ok {
+			v.Role = role
+		}
+
+	case *events.FactionRevealed:
+		for _, member := range e.Members {
+			v.Mates[member.ID] = true
+		}
+
+	case *events.PhaseChanged:
+		if e.Round > 0 {
+			v.Round = e.Round
+		}
+		v.Phase, _ = domain.ParsePhase(e.NewPhase)
+		switch v.Phase {
+		case domain.PhaseNight:
+			v.LastSave = v.tonightSave
+			v.tonightSave = ""
+		case domain.PhaseDay:
+			v.Accusations = make(map[string]int)
+		}
+
+	case *events.PlayerEliminated:
+		v.Alive[e.PlayerID] = false
+
+	case *events.InvestigationResult:
+		v.Investigations[e.TargetID] = e.Faction
+
+	case *events.AllChatMessage:
+		v.parseChat(e.SenderID, e.Message)
+
+	case *events.GameEnded:
+		v.Over = true
+	}
+}
+
+// parseChat picks up claims and accusations from public chat.
+func (v *View) parseChat(senderID, message string) {
+	fields := strings.Fields(message)
+	if len(fields) < 2 {
+		return
+	}
+
+	switch fields[0] {
+	case ClaimPrefix:
+		if len(fields) >= 3 {
+			v.Claims[fields[1]] = fields[2]
+			v.ClaimedSheriff = senderID
+		}
+	case AccusePrefix:
+		if fields[1] != senderID {
+			v.Accusations[fields[1]]++
+		}
+	}
+}
+
+// recordAction remembers the bot's own night actions for the rules it must respect.
+func (v *View) recordAction(action Action) {
+	if action.Kind != ActionNight {
+		return
+	}
+	switch v.Role {
+	case domain.RoleDoctor:
+		v.tonightSave = action.Target
+	case domain.RoleSheriff:
+		v.Investigated = true
+	}
+}
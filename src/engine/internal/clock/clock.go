@@ -0,0 +1,144 @@
+// Package clock abstracts time so the engine can run on wall-clock time in
+// production and on a virtual clock in simulations and tests.
+package clock
+
+import (
+	"sort"
+	"sync"
+	"time"
+)
+
+// Clock tells the time and schedules callbacks.
+type Clock interface {
+	Now() time.Time
+
+	// AfterFunc calls f once d has elapsed on this clock.
+	AfterFunc(d time.Duration, f func()) Timer
+}
+
+// Timer is a scheduled callback. Stop returns false if it already fired or was stopped.
+type Timer interface {
+	Stop() bool
+}
+
+// Real is the wall clock, backed by the time package.
+type Real struct{}
+
+func (Real) Now() time.Time { return time.Now() }
+
+func (Real) AfterFunc(d time.Duration, f func()) Timer { return time.AfterFunc(d, f) }
+
+// Virtual is a clock that only moves when told to.
+// Timers fire synchronously on the goroutine calling Advance/AdvanceToNext,
+// in deadline order (ties in scheduling order), so a run is fully reproducible.
+type Virtual struct {
+	mu     sync.Mutex
+	now    time.Time
+	timers []*virtualTimer
+	nextID int
+}
+
+type virtualTimer struct {
+	clock    *Virtual
+	id       int
+	deadline time.Time
+	f        func()
+}
+
+// NewVirtual creates a virtual clock starting at start.
+func NewVirtual(start time.Time) *Virtual {
+	return &Virtual{now: start}
+}
+
+func (v *Virtual) Now() time.Time {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return v.now
+}
+
+func (v *Virtual) AfterFunc(d time.Duration, f func()) Timer {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	v.nextID++
+	t := &virtualTimer{clock: v, id: v.nextID, deadline: v.now.Add(d), f: f}
+	v.timers = append(v.timers, t)
+	sort.SliceStable(v.timers, func(i, j int) bool {
+		return v.timers[i].deadline.Before(v.timers[j].deadline)
+	})
+	return t
+}
+
+// Pending returns the number of timers that have not fired or been stopped.
+func (v *Virtual) Pending() int {
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	return len(v.timers)
+}
+
+// Advance moves the clock forward by d, firing every timer that becomes due.
+// Returns the number of timers fired.
+func (v *Virtual) Advance(d time.Duration) int {
+	v.mu.Lock()
+	target := v.now.Add(d)
+	v.mu.Unlock()
+
+	fired := 0
+	for v.fireNext(target) {
+		fired++
+	}
+
+	v.mu.Lock()
+	if v.now.Before(target) {
+		v.now = target
+	}
+	v.mu.Unlock()
+	return fired
+}
+
+// AdvanceToNext jumps to the earliest pending timer and fires it.
+// Returns false if no timer is pending (the clock doesn't move).
+func (v *Virtual) AdvanceToNext() bool {
+	v.mu.Lock()
+	if len(v.timers) == 0 {
+		v.mu.Unlock()
+		return false
+	}
+	deadline := v.timers[0].deadline
+	v.mu.Unlock()
+
+	return v.fireNext(deadline)
+}
+
+// fireNext fires the earliest timer due at or before limit.
+// The callback runs without the lock, so it may schedule or stop timers.
+func (v *Virtual) fireNext(limit time.Time) bool {
+	v.mu.Lock()
+	if len(v.timers) == 0 || v.timers[0].deadline.After(limit) {
+		v.mu.Unlock()
+		return false
+	}
+	t := v.timers[0]
+	v.timers = v.timers[1:]
+	if t.deadline.After(v.now) {
+		v.now = t.deadline
+	}
+	v.mu.Unlock()
+
+	t.f()
+	return true
+}
+
+func (t *virtualTimer) Stop() bool {
+	v := t.clock
+	v.mu.Lock()
+	defer v.mu.Unlock()
+
+	for i, pending := range v.timers {
+		if pending.id == t.id {
+			v.timers = append(v.timers[:i], v.timers[i+1:]...)
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,88 @@
+package clock
+
+import (
+	"testing"
+	"time"
+)
+
+var start = time.Date(2025, 1, 1, 0, 0, 0, 0, time.UTC)
+
+func TestVirtual_AdvanceFiresInOrder(t *testing.T) {
+	v := NewVirtual(start)
+
+	var fired []string
+	v.AfterFunc(2*time.Second, func() { fired = append(fired, "b") })
+	v.AfterFunc(time.Second, func() { fired = append(fired, "a") })
+	v.AfterFunc(time.Second, func() { fired = append(fired, "a2") })
+	v.AfterFunc(5*time.Second, func() { fired = append(fired, "c") })
+
+	if n := v.Advance(2 * time.Second); n != 3 {
+		t.Fatalf("expected 3 timers to fire, got %d", n)
+	}
+	if got := v.Now(); !got.Equal(start.Add(2 * time.Second)) {
+		t.Errorf("expected clock at +2s, got %v", got.Sub(start))
+	}
+	want := []string{"a", "a2", "b"}
+	for i := range want {
+		if fired[i] != want[i] {
+			t.Fatalf("expected order %v, got %v", want, fired)
+		}
+	}
+	if v.Pending() != 1 {
+		t.Errorf("expected 1 pending timer, got %d", v.Pending())
+	}
+}
+
+func TestVirtual_AdvanceToNext(t *testing.T) {
+	v := NewVirtual(start)
+	if v.AdvanceToNext() {
+		t.Fatal("expected false without timers")
+	}
+
+	fired := false
+	v.AfterFunc(time.Minute, func() { fired = true })
+
+	if !v.AdvanceToNext() || !fired {
+		t.Fatal("expected the timer to fire")
+	}
+	if got := v.Now().Sub(start); got != time.Minute {
+		t.Errorf("expected clock at +1m, got %v", got)
+	}
+}
+
+func TestVirtual_Stop(t *testing.T) {
+	v := NewVirtual(start)
+
+	fired := false
+	timer := v.AfterFunc(time.Second, func() { fired = true })
+
+	if !timer.Stop() {
+		t.Fatal("expected Stop to report a pending timer")
+	}
+	if timer.Stop() {
+		t.Error("second Stop should return false")
+	}
+	v.Advance(time.Hour)
+	if fired {
+		t.Error("stopped timer fired")
+	}
+}
+
+func TestVirtual_CallbackCanReschedule(t *testing.T) {
+	v := NewVirtual(start)
+
+	count := 0
+	var tick func()
+	tick = func() {
+		count++
+		if count < 3 {
+			v.AfterFunc(time.Second, tick)
+		}
+	}
+	v.AfterFunc(time.Second, tick)
+
+	v.Advance(10 * time.Second)
+	if count != 3 {
+		t.Errorf("expected 3 ticks, got %d", count)
+	}
+}
@@ -3,6 +3,7 @@ package config
 import (
 	"errors"
 	"fmt"
+	"regexp"
 	"time"
 
 	"github.com/caarlos0/env/v11"
@@ -21,6 +22,11 @@ type Config struct {
 	KafkaClientID string `env:"ENGINE_KAFKA_CLIENT_ID" envDefault:"mafia-engine"`
 	KafkaGroupID  string `env:"ENGINE_KAFKA_GROUP_ID" envDefault:"mafia-engine-group"`
 
+	// kafka | memory
+	// "memory" runs an in-process broker (kafka/memory.go): no cluster needed,
+	// nothing leaves the process and the brokers above are ignored
+	KafkaTransport string `env:"ENGINE_KAFKA_TRANSPORT" envDefault:"kafka"`
+
 	// NOTE: Topic names are constants in kafka/topics.go (single source of truth)
 	// Do NOT add topic configuration here to avoid mismatch bugs.
 
@@ -40,14 +46,77 @@ type Config struct {
 	GameIDPrefix   string   `env:"ENGINE_GAME_ID_PREFIX" envDefault:"game"`
 	PlayerNames    []string `env:"ENGINE_PLAYER_NAMES" envSeparator:"," envDefault:"Gilbert McDonald,Dorothy Bird,Ernest Preston,Vincent Schultz,Joanne Sloan,Lana Moran,Adrienne Fuller,Greg Bennett,Curt Simon,Rachel McMillan,Dustin Eastman,Willard Mendez"`
 
+	// Name pack selection (see names/packs.go). A pack file (one name per line)
+	// wins over a built-in pack (classic, noir, medieval, scifi), which wins
+	// over ENGINE_PLAYER_NAMES. The pack must have at least GameMaxPlayers names.
+	NamePack     string `env:"ENGINE_NAME_PACK"`
+	NamePackFile string `env:"ENGINE_NAME_PACK_FILE"`
+	// Draw names in a seeded random order instead of list order
+	NameShuffle bool `env:"ENGINE_NAME_SHUFFLE" envDefault:"true"`
+
+	// Seed for the game RNG (roles, traits). 0 = pick a random seed at startup.
+	GameSeed int64 `env:"ENGINE_GAME_SEED" envDefault:"0"`
+
+	// Personality trait catalogue, see domain/traits.go for built-in descriptions.
+	// Empty disables traits. Overridden by the ruleset's traits when set.
+	PersonalityTraits []string `env:"ENGINE_PERSONALITY_TRAITS" envSeparator:"," envDefault:"timid,aggressive,neutral"`
+
+	// Optional JSON ruleset file (see domain/ruleset.go)
+	RulesetFile string `env:"ENGINE_RULESET_FILE"`
+
+	// Roles pinned to seats at game start ("3=sheriff,5=mafia"); the rest are dealt.
+	// Seats are 1-based in join order. Pins must fit the role distribution.
+	PinnedRoles []string `env:"ENGINE_PINNED_ROLES" envSeparator:","`
+
+	// Model profiles the seats are reserved for, seat 1 first (e.g. a
+	// tournament pairing, see cmd/tournament -schedule). Seats reserved for a
+	// bot profile ("bot:<strategy>") are played by that bot in any agent mode;
+	// an agent joining another reserved seat must declare its profile.
+	// Empty = agents declare their own profile.
+	SeatProfiles []string `env:"ENGINE_SEAT_PROFILES" envSeparator:","`
+
 	// Phase timeouts (how long each phase lasts before auto-advancing)
 	PhaseNightTimeout  time.Duration `env:"ENGINE_PHASE_NIGHT_TIMEOUT" envDefault:"2m"`
 	PhaseDayTimeout    time.Duration `env:"ENGINE_PHASE_DAY_TIMEOUT" envDefault:"5m"`
 	PhaseVotingTimeout time.Duration `env:"ENGINE_PHASE_VOTING_TIMEOUT" envDefault:"1m"`
 
+	// Time left in a phase at which players are warned (PhaseEndingSoon).
+	// Offsets not shorter than the phase are skipped; empty = no warnings.
+	PhaseWarnings []time.Duration `env:"ENGINE_PHASE_WARNINGS" envSeparator:"," envDefault:"30s,10s"`
+
+	// Record the game's inbound actions and published events to this JSONL file
+	// (see replay/recording.go), to replay it later with cmd/replay. Empty = off.
+	RecordFile string `env:"ENGINE_RECORD_FILE"`
+
+	// Store the finished game in this archive database (see archive/archive.go)
+	// for later analysis. Empty = off. With an address, the engine also serves
+	// the archive's query API over HTTP there (e.g. ":8090").
+	ArchiveFile string `env:"ENGINE_ARCHIVE_FILE"`
+	ArchiveAddr string `env:"ENGINE_ARCHIVE_ADDR"`
+
+	// Serve agent telemetry (actions, tokens, generation latency, response
+	// time per player and model) as Prometheus metrics at /metrics on this
+	// address (e.g. ":9090"). Empty = off.
+	MetricsAddr string `env:"ENGINE_METRICS_ADDR"`
+
+	// Player thoughts are kept with the game and re-published to observers only
+	// (never to players). Longer thoughts are cut to ENGINE_THOUGHT_MAX_BYTES
+	// (0 = no limit); matches of the ENGINE_THOUGHT_REDACT regular expressions
+	// (";"-separated) are replaced with "[redacted]".
+	ThoughtMaxBytes int      `env:"ENGINE_THOUGHT_MAX_BYTES" envDefault:"4096"`
+	ThoughtRedact   []string `env:"ENGINE_THOUGHT_REDACT" envSeparator:";"`
+
+	// How many emitted events are kept for player resync requests.
+	// Older gaps are answered with a state snapshot instead.
+	EventHistorySize int `env:"ENGINE_EVENT_HISTORY_SIZE" envDefault:"1024"`
+
 	// mock | llm
 	AgentMode string `env:"ENGINE_AGENT_MODE" envDefault:"mock"`
 
+	// Built-in bot strategies used in mock mode, dealt to seats round-robin
+	// (see bots/strategy.go for the available names)
+	BotStrategies []string `env:"ENGINE_BOT_STRATEGIES" envSeparator:"," envDefault:"random,sheriff-follower,aggressive-accuser"`
+
 	// -------------
 	// Logging
 	// -------------
@@ -56,7 +125,7 @@ type Config struct {
 	Env      string `env:"ENGINE_ENV" envDefault:"dev"`
 
 	// Feature flags
-	EnableRoleSecrets bool `env:"ENGINE_ENABLE_ROLE_SECRETS" envDefault:"false"`
+	EnableRoleSecrets bool `env:"ENGINE_ENABLE_ROLE_SECRETS" envDefault:"true"`
 }
 
 // Load loads configuration from environment variables,
@@ -81,6 +150,13 @@ func (c *Config) Validate() error {
 		return errors.New("ENGINE_KAFKA_BROKERS must not be empty")
 	}
 
+	switch c.KafkaTransport {
+	case "kafka", "memory":
+		// ok
+	default:
+		return fmt.Errorf("ENGINE_KAFKA_TRANSPORT must be one of [kafka, memory], got %q", c.KafkaTransport)
+	}
+
 	if c.KafkaConsumerTimeout <= 0 {
 		return errors.New("ENGINE_KAFKA_CONSUMER_TIMEOUT must be > 0")
 	}
@@ -121,6 +197,34 @@ func (c *Config) Validate() error {
 		return errors.New("ENGINE_PHASE_VOTING_TIMEOUT must be > 0")
 	}
 
+	if len(c.SeatProfiles) > c.GameMinPlayers {
+		return fmt.Errorf("ENGINE_SEAT_PROFILES has %d profiles for %d seats (ENGINE_GAME_MIN_PLAYERS)", len(c.SeatProfiles), c.GameMinPlayers)
+	}
+
+	for _, warning := range c.PhaseWarnings {
+		if warning <= 0 {
+			return fmt.Errorf("ENGINE_PHASE_WARNINGS: offsets must be > 0, got %s", warning)
+		}
+	}
+
+	if c.ArchiveAddr != "" && c.ArchiveFile == "" {
+		return errors.New("ENGINE_ARCHIVE_ADDR requires ENGINE_ARCHIVE_FILE")
+	}
+
+	if c.ThoughtMaxBytes < 0 {
+		return errors.New("ENGINE_THOUGHT_MAX_BYTES must be >= 0")
+	}
+
+	for _, pattern := range c.ThoughtRedact {
+		if _, err := regexp.Compile(pattern); err != nil {
+			return fmt.Errorf("ENGINE_THOUGHT_REDACT: invalid pattern %q: %w", pattern, err)
+		}
+	}
+
+	if c.EventHistorySize < 0 {
+		return errors.New("ENGINE_EVENT_HISTORY_SIZE must be >= 0")
+	}
+
 	switch c.AgentMode {
 	case "mock", "llm":
 		// ok
@@ -128,5 +232,9 @@ func (c *Config) Validate() error {
 		return fmt.Errorf("ENGINE_AGENT_MODE must be one of [mock, llm], got %q", c.AgentMode)
 	}
 
+	if c.AgentMode == "mock" && len(c.BotStrategies) == 0 {
+		return errors.New("ENGINE_BOT_STRATEGIES must not be empty in mock mode")
+	}
+
 	return nil
 }
@@ -28,6 +28,18 @@ func TestLoadConfigDefaults(t *testing.T) {
 	if cfg.GameIDPrefix != "game" {
 		t.Errorf("expected default GameIDPrefix 'game', got %q", cfg.GameIDPrefix)
 	}
+	if len(cfg.PersonalityTraits) != 3 {
+		t.Errorf("expected 3 default personality traits, got %v", cfg.PersonalityTraits)
+	}
+	if cfg.KafkaTransport != "kafka" {
+		t.Errorf("expected default KafkaTransport kafka, got %q", cfg.KafkaTransport)
+	}
+	if cfg.GameSeed != 0 {
+		t.Errorf("expected default GameSeed 0 (random), got %d", cfg.GameSeed)
+	}
+	if !cfg.NameShuffle {
+		t.Error("expected shuffled names by default")
+	}
 }
 
 func TestLoadConfigEnvOverrides(t *testing.T) {
@@ -71,3 +83,67 @@ func TestLoadConfigInvalidValues(t *testing.T) {
 		t.Fatalf("expected error for invalid ENGINE_KAFKA_CONSUMER_TIMEOUT, got nil")
 	}
 }
+
+func TestLoadConfigKafkaTransport(t *testing.T) {
+	t.Setenv("ENGINE_KAFKA_TRANSPORT", "memory")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if cfg.KafkaTransport != "memory" {
+		t.Fatalf("expected KafkaTransport memory, got %q", cfg.KafkaTransport)
+	}
+
+	t.Setenv("ENGINE_KAFKA_TRANSPORT", "carrier-pigeon")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatalf("expected error for unknown ENGINE_KAFKA_TRANSPORT, got nil")
+	}
+}
+
+func TestLoadConfigThoughtRedact(t *testing.T) {
+	t.Setenv("ENGINE_THOUGHT_REDACT", `sk-[a-z0-9]+;\d{3}-\d{4}`)
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if len(cfg.ThoughtRedact) != 2 {
+		t.Fatalf("expected 2 redaction patterns, got %q", cfg.ThoughtRedact)
+	}
+
+	t.Setenv("ENGINE_THOUGHT_REDACT", "(unclosed")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatalf("expected error for invalid ENGINE_THOUGHT_REDACT, got nil")
+	}
+}
+
+func TestLoadConfigPhaseWarnings(t *testing.T) {
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if len(cfg.PhaseWarnings) != 2 || cfg.PhaseWarnings[0] != 30*time.Second || cfg.PhaseWarnings[1] != 10*time.Second {
+		t.Errorf("expected default warnings 30s,10s, got %v", cfg.PhaseWarnings)
+	}
+
+	t.Setenv("ENGINE_PHASE_WARNINGS", "1m,-5s")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatalf("expected error for a negative ENGINE_PHASE_WARNINGS offset, got nil")
+	}
+}
+
+func TestLoadConfigSeatProfiles(t *testing.T) {
+	t.Setenv("ENGINE_GAME_MIN_PLAYERS", "2")
+	t.Setenv("ENGINE_SEAT_PROFILES", "llama3.2:1b,bot:random")
+	cfg, err := LoadConfig()
+	if err != nil {
+		t.Fatalf("LoadConfig failed: %v", err)
+	}
+	if len(cfg.SeatProfiles) != 2 || cfg.SeatProfiles[1] != "bot:random" {
+		t.Errorf("unexpected seat profiles %q", cfg.SeatProfiles)
+	}
+
+	t.Setenv("ENGINE_SEAT_PROFILES", "a,b,c")
+	if _, err := LoadConfig(); err == nil {
+		t.Fatalf("expected error for more ENGINE_SEAT_PROFILES than seats, got nil")
+	}
+}
@@ -0,0 +1,94 @@
+// This file contains what each player may do in the current phase
+
+package domain
+
+// VoteTargets returns the players a vote may go to, in seat order: everyone
+// alive (RegisterVote accepts a vote for oneself).
+func (g *GameState) VoteTargets() []string {
+	var targets []string
+	for _, player := range g.GetSeatedPlayers() {
+		if player.Alive {
+			targets = append(targets, player.ID)
+		}
+	}
+	return targets
+}
+
+// NightTargets returns the players the actor may target tonight as the given
+// role, in seat order, following the rules SetNightAction enforces.
+// Nil if the role has no night action or no target left (e.g. the sheriff
+// after the bullet is used).
+func (g *GameState) NightTargets(role Role, actorID string) []string {
+	var targets []string
+	for _, player := range g.GetSeatedPlayers() {
+		if g.canTarget(role, actorID, player.ID) {
+			targets = append(targets, player.ID)
+		}
+	}
+	return targets
+}
+
+// canTarget checks the target rules of a night action:
+//   - target exists and is alive
+//   - mafia/sheriff can't target themselves (doctor CAN self-save)
+//   - doctor can't save the same person two rounds in a row
+//   - sheriff only has one bullet
+func (g *GameState) canTarget(role Role, actorID, targetID string) bool {
+	if !role.HasNightAction() {
+		return false
+	}
+
+	target := g.Players[targetID]
+	if target == nil || !target.Alive {
+		return false
+	}
+
+	switch role {
+	case RoleMafia:
+		return actorID != targetID
+	case RoleDoctor:
+		return g.PreviousDoctorTarget != targetID
+	case RoleSheriff:
+		return !g.SheriffUsedBullet && actorID != targetID
+	default:
+		return false
+	}
+}
+
+// Outstanding returns the alive players the current phase still waits on, in
+// seat order: voters who haven't voted, and at night the roles with an action
+// left (the mafia kill is one team decision, so every mafia player is
+// outstanding until one of them acts). Day and the other phases wait on nobody.
+func (g *GameState) Outstanding() []*Player {
+	var waiting []*Player
+	for _, player := range g.GetSeatedPlayers() {
+		if !player.Alive {
+			continue
+		}
+		switch g.Phase {
+		case PhaseVoting:
+			if _, voted := g.Votes[player.ID]; !voted {
+				waiting = append(waiting, player)
+			}
+		case PhaseNight:
+			if !g.nightActionTaken(player.Role) && len(g.NightTargets(player.Role, player.ID)) > 0 {
+				waiting = append(waiting, player)
+			}
+		}
+	}
+	return waiting
+}
+
+// nightActionTaken reports whether the role's action is set for this night.
+func (g *GameState) nightActionTaken(role Role) bool {
+	switch role {
+	case RoleMafia:
+		return g.MafiaTarget != ""
+	case RoleDoctor:
+		return g.DoctorTarget != ""
+	case RoleSheriff:
+		return g.SheriffTarget != ""
+	default:
+		return false
+	}
+}
@@ -0,0 +1,90 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func actionsTestGame() *GameState {
+	game := NewGameState("test")
+	for _, p := range []*Player{
+		{ID: "mafia-1", Name: "Mafia", Role: RoleMafia, Alive: true},
+		{ID: "doctor", Name: "Doctor", Role: RoleDoctor, Alive: true},
+		{ID: "sheriff", Name: "Sheriff", Role: RoleSheriff, Alive: true},
+		{ID: "villager", Name: "Villager", Role: RoleVillager, Alive: true},
+		{ID: "dead", Name: "Dead", Role: RoleVillager},
+	} {
+		game.AddPlayer(p)
+	}
+	game.Round = 1
+	return game
+}
+
+func TestNightTargets(t *testing.T) {
+	game := actionsTestGame()
+	game.PreviousDoctorTarget = "villager"
+
+	tests := []struct {
+		name  string
+		role  Role
+		actor string
+		want  []string
+	}{
+		{"mafia not self", RoleMafia, "mafia-1", []string{"doctor", "sheriff", "villager"}},
+		{"doctor not last save", RoleDoctor, "doctor", []string{"mafia-1", "doctor", "sheriff"}},
+		{"sheriff not self", RoleSheriff, "sheriff", []string{"mafia-1", "doctor", "villager"}},
+		{"villager none", RoleVillager, "villager", nil},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := game.NightTargets(tt.role, tt.actor); !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("got %v, want %v", got, tt.want)
+			}
+		})
+	}
+
+	// every listed target is accepted
+	for _, target := range game.NightTargets(RoleDoctor, "doctor") {
+		if !game.Clone().SetNightAction(RoleDoctor, "doctor", target) {
+			t.Errorf("listed target %s rejected", target)
+		}
+	}
+
+	game.SheriffUsedBullet = true
+	if got := game.NightTargets(RoleSheriff, "sheriff"); got != nil {
+		t.Errorf("sheriff without a bullet got targets %v", got)
+	}
+}
+
+func TestOutstanding(t *testing.T) {
+	game := actionsTestGame()
+	ids := func() []string {
+		var ids []string
+		for _, p := range game.Outstanding() {
+			ids = append(ids, p.ID)
+		}
+		return ids
+	}
+
+	game.Phase = PhaseNight
+	if got := ids(); !reflect.DeepEqual(got, []string{"mafia-1", "doctor", "sheriff"}) {
+		t.Errorf("night: got %v", got)
+	}
+	game.SetNightAction(RoleMafia, "mafia-1", "villager")
+	game.SetNightAction(RoleSheriff, "sheriff", "mafia-1")
+	if got := ids(); !reflect.DeepEqual(got, []string{"doctor"}) {
+		t.Errorf("night after two actions: got %v", got)
+	}
+
+	game.Phase = PhaseDay
+	if got := ids(); got != nil {
+		t.Errorf("day waits on nobody, got %v", got)
+	}
+
+	game.Phase = PhaseVoting
+	game.ResetPhaseData()
+	game.RegisterVote("doctor", "mafia-1")
+	if got := ids(); !reflect.DeepEqual(got, []string{"mafia-1", "sheriff", "villager"}) {
+		t.Errorf("voting: got %v", got)
+	}
+}
@@ -0,0 +1,119 @@
+// This file contains phase duration policies - how long a phase lasts
+
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Duration is a time.Duration written as a string in JSON ("90s", "2m").
+type Duration time.Duration
+
+func (d *Duration) UnmarshalJSON(data []byte) error {
+	var s string
+	if err := json.Unmarshal(data, &s); err != nil {
+		return fmt.Errorf("duration must be a string like \"30s\": %w", err)
+	}
+	parsed, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = Duration(parsed)
+	return nil
+}
+
+func (d Duration) MarshalJSON() ([]byte, error) {
+	return json.Marshal(time.Duration(d).String())
+}
+
+// DurationPolicy decides how long a phase lasts, evaluated as the phase opens:
+//
+//	base (or the round's override) + per_alive * alive players
+//
+// bounded by min and max. While the phase runs, busy chat extends it: see
+// Extension. Empty fields add nothing.
+type DurationPolicy struct {
+	Base     Duration         `json:"base,omitempty"`      // 0 = the engine's configured timeout
+	Rounds   map[int]Duration `json:"rounds,omitempty"`    // base for given rounds, e.g. a longer day 1
+	PerAlive Duration         `json:"per_alive,omitempty"` // added per alive player
+
+	// Busy chat: at least ChatPerPlayer public messages per alive player
+	// since the phase began or was last extended, extends it by ChatExtension,
+	// up to Max (required then, so a busy chat can't keep a phase open forever)
+	ChatPerPlayer float64  `json:"chat_per_player,omitempty"`
+	ChatExtension Duration `json:"chat_extension,omitempty"`
+
+	Min Duration `json:"min,omitempty"`
+	Max Duration `json:"max,omitempty"` // 0 = no maximum
+}
+
+// Duration returns how long the current phase of the game lasts; fallback is
+// the engine's configured timeout for it.
+func (p DurationPolicy) Duration(g *GameState, fallback time.Duration) time.Duration {
+	base := fallback
+	if p.Base > 0 {
+		base = time.Duration(p.Base)
+	}
+	if override, ok := p.Rounds[g.Round]; ok {
+		base = time.Duration(override)
+	}
+
+	alive := len(g.GetAlivePlayers())
+	duration := base + time.Duration(alive)*time.Duration(p.PerAlive)
+
+	duration = max(duration, time.Duration(p.Min))
+	if p.Max > 0 {
+		duration = min(duration, time.Duration(p.Max))
+	}
+	return duration
+}
+
+// Extension returns how much longer the current phase runs when chats public
+// messages were sent since it began or was last extended, and it has lasted
+// length so far (extensions included): ChatExtension if the chat is busy,
+// cut so the phase lasts at most Max. 0 means the phase ends as scheduled.
+func (p DurationPolicy) Extension(g *GameState, chats int, length time.Duration) time.Duration {
+	alive := len(g.GetAlivePlayers())
+	if p.ChatExtension <= 0 || p.ChatPerPlayer <= 0 || alive == 0 {
+		return 0
+	}
+	if float64(chats)/float64(alive) < p.ChatPerPlayer {
+		return 0
+	}
+
+	extension := time.Duration(p.ChatExtension)
+	if p.Max > 0 {
+		extension = min(extension, time.Duration(p.Max)-length)
+	}
+	return max(extension, 0)
+}
+
+// Validate checks the policy for negative durations and inverted bounds.
+func (p DurationPolicy) Validate() error {
+	for _, d := range []Duration{p.Base, p.PerAlive, p.ChatExtension, p.Min, p.Max} {
+		if d < 0 {
+			return errors.New("durations must be >= 0")
+		}
+	}
+	for round, d := range p.Rounds {
+		if round <= 0 || d <= 0 {
+			return fmt.Errorf("round %d: overrides need a round >= 1 and a duration > 0", round)
+		}
+	}
+	if p.ChatPerPlayer < 0 {
+		return errors.New("chat_per_player must be >= 0")
+	}
+	if p.ChatExtension > 0 && p.ChatPerPlayer == 0 {
+		return errors.New("chat_extension needs a chat_per_player > 0")
+	}
+	if p.ChatExtension > 0 && p.Max == 0 {
+		return errors.New("chat_extension needs a max > 0")
+	}
+	if p.Max > 0 && p.Min > p.Max {
+		return fmt.Errorf("min %s is above max %s", time.Duration(p.Min), time.Duration(p.Max))
+	}
+	return nil
+}
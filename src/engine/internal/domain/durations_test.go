@@ -0,0 +1,104 @@
+package domain
+
+import (
+	"testing"
+	"time"
+)
+
+func TestDurationPolicy_Duration(t *testing.T) {
+	game := createTestGame(6)
+	game.Phase = PhaseDay
+	game.Round = 2
+	game.EliminatePlayer("player-1") // 5 alive
+
+	policy := DurationPolicy{
+		Base:     Duration(time.Minute),
+		Rounds:   map[int]Duration{1: Duration(4 * time.Minute)},
+		PerAlive: Duration(30 * time.Second),
+		Min:      Duration(2 * time.Minute),
+		Max:      Duration(5 * time.Minute),
+	}
+
+	// 1m + 5 * 30s
+	if got := policy.Duration(game, 10*time.Minute); got != 210*time.Second {
+		t.Errorf("day: got %v, want 3m30s", got)
+	}
+
+	// round override, bounded by max
+	game.Round = 1
+	if got := policy.Duration(game, 0); got != 5*time.Minute {
+		t.Errorf("day 1: got %v, want the 5m maximum", got)
+	}
+
+	// bounded by min; no base falls back to the configured timeout
+	for _, id := range []string{"player-2", "player-3", "player-4", "player-5"} {
+		game.EliminatePlayer(id)
+	}
+	game.Round = 3
+	if got := (DurationPolicy{PerAlive: Duration(time.Second), Min: Duration(time.Minute)}).Duration(game, 20*time.Second); got != time.Minute {
+		t.Errorf("short day: got %v, want the 1m minimum", got)
+	}
+	if got := (DurationPolicy{}).Duration(game, 20*time.Second); got != 20*time.Second {
+		t.Errorf("empty policy: got %v, want the fallback", got)
+	}
+}
+
+func TestDurationPolicy_Extension(t *testing.T) {
+	game := createTestGame(5)
+	game.Phase = PhaseDay
+	game.Round = 1 // day 1 can be extended too
+
+	policy := DurationPolicy{ChatPerPlayer: 2, ChatExtension: Duration(time.Minute), Max: Duration(5 * time.Minute)}
+	tests := []struct {
+		name   string
+		chats  int
+		length time.Duration
+		want   time.Duration
+	}{
+		{"quiet", 9, 3 * time.Minute, 0},
+		{"busy", 10, 3 * time.Minute, time.Minute},
+		{"up to max", 10, 270 * time.Second, 30 * time.Second},
+		{"at max", 10, 5 * time.Minute, 0},
+	}
+	for _, tt := range tests {
+		if got := policy.Extension(game, tt.chats, tt.length); got != tt.want {
+			t.Errorf("%s: got %v, want %v", tt.name, got, tt.want)
+		}
+	}
+
+	// no maximum, and no extension without a threshold
+	if got := (DurationPolicy{ChatPerPlayer: 1, ChatExtension: Duration(time.Minute)}).Extension(game, 5, time.Hour); got != time.Minute {
+		t.Errorf("no max: got %v, want 1m", got)
+	}
+	if got := (DurationPolicy{ChatExtension: Duration(time.Minute)}).Extension(game, 100, 0); got != 0 {
+		t.Errorf("no threshold: got %v, want 0", got)
+	}
+}
+
+func TestRuleset_PhaseDuration(t *testing.T) {
+	ruleset, err := ParseRuleset([]byte(`{"name":"adaptive","durations":{"day":{"per_alive":"45s","rounds":{"1":"6m"},"max":"8m"}}}`))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	game := createTestGame(4)
+	game.Phase = PhaseDay
+	game.Round = 2
+
+	if got := ruleset.PhaseDuration(game, time.Minute); got != 4*time.Minute {
+		t.Errorf("day: got %v, want 4m", got)
+	}
+	game.Round = 1
+	if got := ruleset.PhaseDuration(game, time.Minute); got != 8*time.Minute {
+		t.Errorf("day 1: got %v, want 8m", got)
+	}
+
+	// phases without a policy, and no ruleset, keep the configured timeout
+	game.Phase = PhaseNight
+	if got := ruleset.PhaseDuration(game, time.Minute); got != time.Minute {
+		t.Errorf("night: got %v, want 1m", got)
+	}
+	var none *Ruleset
+	if got := none.PhaseDuration(game, time.Minute); got != time.Minute {
+		t.Errorf("nil ruleset: got %v, want 1m", got)
+	}
+}
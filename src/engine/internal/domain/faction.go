@@ -0,0 +1,67 @@
+// This file containes faction (team) definitions and membership helpers
+
+package domain
+
+// Faction groups roles that win together.
+type Faction int
+
+const (
+	FactionNone Faction = iota
+	FactionVillage
+	FactionMafia
+)
+
+func (f Faction) String() string {
+	switch f {
+	case FactionNone:
+		return "none"
+	case FactionVillage:
+		return "village"
+	case FactionMafia:
+		return "mafia"
+	default:
+		return "invalid"
+	}
+}
+
+// KnowsMembers returns true if members of the faction know each other's identity.
+// Village members don't - that's the whole game.
+func (f Faction) KnowsMembers() bool {
+	return f == FactionMafia
+}
+
+// Faction returns the faction a role belongs to.
+func (r Role) Faction() Faction {
+	switch {
+	case r.IsMafiaTeam():
+		return FactionMafia
+	case r.IsVillagerTeam():
+		return FactionVillage
+	default:
+		return FactionNone
+	}
+}
+
+// FactionMates returns the other members of the player's faction,
+// dead or alive, sorted by ID.
+// Returns nil if the player doesn't exist or the faction doesn't know its members.
+func (g *GameState) FactionMates(playerID string) []*Player {
+	player := g.Players[playerID]
+	if player == nil {
+		return nil
+	}
+
+	faction := player.Role.Faction()
+	if !faction.KnowsMembers() {
+		return nil
+	}
+
+	var mates []*Player
+	for _, other := range g.Players {
+		if other.ID != playerID && other.Role.Faction() == faction {
+			mates = append(mates, other)
+		}
+	}
+	sortPlayersByID(mates)
+	return mates
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "testing"
+
+func TestRoleFaction(t *testing.T) {
+	tests := []struct {
+		role     Role
+		expected Faction
+	}{
+		{RoleVillager, FactionVillage},
+		{RoleDoctor, FactionVillage},
+		{RoleSheriff, FactionVillage},
+		{RoleMafia, FactionMafia},
+		{RoleUnknown, FactionNone},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.role.String(), func(t *testing.T) {
+			if got := tt.role.Faction(); got != tt.expected {
+				t.Errorf("got %s, expected %s", got, tt.expected)
+			}
+		})
+	}
+}
+
+func TestFactionKnowsMembers(t *testing.T) {
+	if !FactionMafia.KnowsMembers() {
+		t.Error("mafia should know its members")
+	}
+	if FactionVillage.KnowsMembers() {
+		t.Error("village should not know its members")
+	}
+}
+
+func TestFactionMates(t *testing.T) {
+	game := createTestGame(5)
+	game.Players["player-1"].Role = RoleMafia
+	game.Players["player-2"].Role = RoleMafia
+	game.Players["player-3"].Role = RoleSheriff
+	game.Players["player-4"].Role = RoleVillager
+	game.Players["player-5"].Role = RoleVillager
+	game.EliminatePlayer("player-2")
+
+	mates := game.FactionMates("player-1")
+	if len(mates) != 1 || mates[0].ID != "player-2" {
+		t.Errorf("expected dead mafia partner player-2, got %v", mates)
+	}
+
+	if mates := game.FactionMates("player-4"); mates != nil {
+		t.Errorf("villagers should not learn faction mates, got %v", mates)
+	}
+
+	if mates := game.FactionMates("ghost"); mates != nil {
+		t.Errorf("unknown player should have no mates, got %v", mates)
+	}
+}
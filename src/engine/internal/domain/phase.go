@@ -33,3 +33,14 @@ func (p Phase) String() string {
 		return "invalid"
 	}
 }
+
+// ParsePhase converts a phase name (as produced by Phase.String) back to a Phase.
+// Returns false for unknown names.
+func ParsePhase(name string) (Phase, bool) {
+	for _, phase := range []Phase{PhaseWaiting, PhaseNight, PhaseDay, PhaseVoting, PhaseEnded} {
+		if phase.String() == name {
+			return phase, true
+		}
+	}
+	return PhaseUnknown, false
+}
@@ -52,3 +52,16 @@ func TestPhaseIotaValues(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePhase(t *testing.T) {
+	for _, phase := range []Phase{PhaseWaiting, PhaseNight, PhaseDay, PhaseVoting, PhaseEnded} {
+		parsed, ok := ParsePhase(phase.String())
+		if !ok || parsed != phase {
+			t.Errorf("ParsePhase(%q) = %v, %v", phase.String(), parsed, ok)
+		}
+	}
+
+	if _, ok := ParsePhase("dusk"); ok {
+		t.Error("ParsePhase should reject unknown names")
+	}
+}
@@ -16,7 +16,27 @@ type Player struct {
 	Name  string
 	Role  Role
 	Alive bool
-	// TODO: add personallity trait (e.g. timid, agressive, nuetral...)
+
+	// stable seat number (1-based, join order), assigned by GameState.AddPlayer
+	Seat int
+
+	// role was pinned to the seat at game start instead of dealt, see GameState.AssignRoles
+	RolePinned bool
+
+	// optional public persona metadata shown in the roster (e.g. avatar, bio)
+	Persona map[string]string
+
+	// which agent plays the seat (e.g. an LLM model profile, or "bot:random"),
+	// kept for analysis and never shown to other players; empty if unknown
+	ModelProfile string
+
+	// the seat was reserved for ModelProfile when it was added (e.g. by a
+	// tournament pairing): agents declaring another profile are turned away
+	ProfilePinned bool
+
+	// private personality trait (e.g. timid, aggressive, neutral), see traits.go
+	Trait            string
+	TraitDescription string
 }
 
 // NewPlayer creates a new player with the provided id, name, and role.
@@ -37,6 +57,18 @@ func NewPlayer(id, name string, role Role) (*Player, error) {
 	}, nil
 }
 
+// Clone returns a deep copy of the player
+func (p *Player) Clone() *Player {
+	clone := *p
+	if p.Persona != nil {
+		clone.Persona = make(map[string]string, len(p.Persona))
+		for key, value := range p.Persona {
+			clone.Persona[key] = value
+		}
+	}
+	return &clone
+}
+
 // --- Role enum --- //
 
 // Role represents possible player roles
@@ -67,6 +99,17 @@ func (r Role) String() string {
 	}
 }
 
+// ParseRole converts a role name (as produced by Role.String) back to a Role.
+// Returns false for unknown names.
+func ParseRole(name string) (Role, bool) {
+	for _, role := range []Role{RoleVillager, RoleMafia, RoleDoctor, RoleSheriff} {
+		if role.String() == name {
+			return role, true
+		}
+	}
+	return RoleUnknown, false
+}
+
 // --- ID Generation --- //
 
 // package-level counter for generating player IDs
@@ -94,6 +137,28 @@ func ResetPlayerCounter() {
 	playerCounter = 0
 }
 
+// PlayerIDs generates the player IDs of one game, player-1, player-2, etc.,
+// independent of other games in the process (unlike CreatePlayerID).
+// Thread-safe.
+type PlayerIDs struct {
+	mu   sync.Mutex
+	last int
+}
+
+// NewPlayerIDs continues after the given number of players already seated.
+func NewPlayerIDs(seated int) *PlayerIDs {
+	return &PlayerIDs{last: seated}
+}
+
+// Next returns the next ID.
+func (g *PlayerIDs) Next() string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	g.last++
+	return fmt.Sprintf("player-%d", g.last)
+}
+
 // --- Player helpers --- //
 
 // --- Role helpers --- //
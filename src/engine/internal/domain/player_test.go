@@ -44,6 +44,18 @@ func TestResetPlayerCounter(t *testing.T) {
 	}
 }
 
+func TestPlayerIDs(t *testing.T) {
+	// games number their players independently
+	a, b := NewPlayerIDs(0), NewPlayerIDs(2)
+	a.Next()
+	if id := a.Next(); id != "player-2" {
+		t.Errorf("got %s, expected player-2", id)
+	}
+	if id := b.Next(); id != "player-3" {
+		t.Errorf("after two seated players, got %s, expected player-3", id)
+	}
+}
+
 // --- NewPlayer Tests --- //
 
 func TestNewPlayer(t *testing.T) {
@@ -139,6 +151,21 @@ func TestRoleString(t *testing.T) {
 	}
 }
 
+func TestParseRole(t *testing.T) {
+	for _, role := range []Role{RoleVillager, RoleMafia, RoleDoctor, RoleSheriff} {
+		parsed, ok := ParseRole(role.String())
+		if !ok || parsed != role {
+			t.Errorf("ParseRole(%q) = %v, %v", role.String(), parsed, ok)
+		}
+	}
+
+	for _, name := range []string{"", "unknown", "godfather"} {
+		if _, ok := ParseRole(name); ok {
+			t.Errorf("ParseRole(%q) should fail", name)
+		}
+	}
+}
+
 func TestRoleIsVillagerTeam(t *testing.T) {
 	tests := []struct {
 		name     string
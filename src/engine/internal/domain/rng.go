@@ -0,0 +1,24 @@
+// This file containes the seeded game RNG
+
+package domain
+
+import "math/rand/v2"
+
+// RNG streams split the game seed into independent random sequences,
+// so adding a new random decision doesn't shift the results of existing ones.
+const (
+	RNGStreamRoles uint64 = iota + 1
+	RNGStreamTraits
+	RNGStreamNames
+)
+
+// NewSeed returns a random game seed (used when none is configured).
+func NewSeed() int64 {
+	return rand.Int64()
+}
+
+// RNG returns a deterministic random generator for the given stream,
+// derived from the game seed. Same seed + stream = same sequence.
+func (g *GameState) RNG(stream uint64) *rand.Rand {
+	return rand.New(rand.NewPCG(uint64(g.Seed), stream))
+}
@@ -2,6 +2,12 @@
 
 package domain
 
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
 // Rule helpers are pure functions. Minimum/maximum player limits are provided
 // by the caller (engine) so they can be configured at runtime.
 
@@ -31,3 +37,53 @@ func GetRoleDistribution(currentPlayerCount int) map[Role]int {
 		RoleSheriff:  sheriffCount,
 	}
 }
+
+// ParsePinnedRoles parses "seat=role" entries (e.g. "3=sheriff") into seat -> role.
+func ParsePinnedRoles(entries []string) (map[int]Role, error) {
+	pinned := make(map[int]Role, len(entries))
+	for _, entry := range entries {
+		entry = strings.TrimSpace(entry)
+		if entry == "" {
+			continue
+		}
+
+		seatText, roleName, ok := strings.Cut(entry, "=")
+		if !ok {
+			return nil, fmt.Errorf("pinned role %q: expected seat=role", entry)
+		}
+		seat, err := strconv.Atoi(strings.TrimSpace(seatText))
+		if err != nil || seat < 1 {
+			return nil, fmt.Errorf("pinned role %q: seat must be a number >= 1", entry)
+		}
+		role, ok := ParseRole(strings.TrimSpace(roleName))
+		if !ok {
+			return nil, fmt.Errorf("pinned role %q: unknown role %q", entry, roleName)
+		}
+		if _, dup := pinned[seat]; dup {
+			return nil, fmt.Errorf("seat %d is pinned twice", seat)
+		}
+		pinned[seat] = role
+	}
+	return pinned, nil
+}
+
+// ValidatePinnedRoles checks the pins fit into the role distribution:
+// no role is pinned more often than the distribution deals it.
+func ValidatePinnedRoles(pinned map[int]Role, roleDistribution map[Role]int) error {
+	counts := make(map[Role]int)
+	for seat, role := range pinned {
+		if seat < 1 {
+			return fmt.Errorf("pinned seat %d is invalid", seat)
+		}
+		if _, ok := ParseRole(role.String()); !ok {
+			return fmt.Errorf("seat %d: cannot pin role %s", seat, role)
+		}
+		counts[role]++
+	}
+	for role, count := range counts {
+		if count > roleDistribution[role] {
+			return fmt.Errorf("%d %s pinned, the distribution deals %d", count, role, roleDistribution[role])
+		}
+	}
+	return nil
+}
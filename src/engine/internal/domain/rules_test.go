@@ -1,6 +1,9 @@
 package domain
 
-import "testing"
+import (
+	"reflect"
+	"testing"
+)
 
 // Tests use local min/max values to remain independent from runtime config.
 const (
@@ -163,3 +166,20 @@ func TestGetRoleDistribution(t *testing.T) {
 		})
 	}
 }
+
+func TestParsePinnedRoles(t *testing.T) {
+	pinned, err := ParsePinnedRoles([]string{"3=sheriff", " 1 = mafia ", ""})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	expected := map[int]Role{3: RoleSheriff, 1: RoleMafia}
+	if !reflect.DeepEqual(pinned, expected) {
+		t.Errorf("got %v, expected %v", pinned, expected)
+	}
+
+	for _, bad := range [][]string{{"sheriff"}, {"0=mafia"}, {"x=mafia"}, {"2=jester"}, {"2=mafia", "2=doctor"}} {
+		if _, err := ParsePinnedRoles(bad); err == nil {
+			t.Errorf("%v: expected error", bad)
+		}
+	}
+}
@@ -0,0 +1,163 @@
+// This file containes the ruleset - tunable rules loaded from a file
+
+package domain
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+)
+
+// Ruleset holds the game rules that can vary between games.
+// Empty fields fall back to the engine configuration.
+type Ruleset struct {
+	Name string `json:"name"`
+
+	// personality trait catalogue, overrides ENGINE_PERSONALITY_TRAITS
+	Traits []Trait `json:"traits,omitempty"`
+
+	// special role counts per player count (JSON keys are player counts),
+	// player counts without an entry use GetRoleDistribution
+	Distributions map[int]RoleCounts `json:"distributions,omitempty"`
+
+	// phase duration policies by phase name ("night", "day", "voting"), see
+	// durations.go; phases without one use ENGINE_PHASE_*_TIMEOUT as is
+	Durations map[string]DurationPolicy `json:"durations,omitempty"`
+}
+
+// RoleCounts is how many special roles a game gets; the other seats are villagers.
+// The engine resolves one doctor and one sheriff action per night, so those are 0 or 1.
+type RoleCounts struct {
+	Mafia   int `json:"mafia"`
+	Doctor  int `json:"doctor"`
+	Sheriff int `json:"sheriff"`
+}
+
+// RoleCountsOf counts the special roles among the dealt roles.
+func RoleCountsOf(roles []Role) RoleCounts {
+	var counts RoleCounts
+	for _, role := range roles {
+		switch role {
+		case RoleMafia:
+			counts.Mafia++
+		case RoleDoctor:
+			counts.Doctor++
+		case RoleSheriff:
+			counts.Sheriff++
+		}
+	}
+	return counts
+}
+
+// Distribution expands the counts for a game of n players.
+func (c RoleCounts) Distribution(n int) map[Role]int {
+	return map[Role]int{
+		RoleVillager: n - c.Mafia - c.Doctor - c.Sheriff,
+		RoleMafia:    c.Mafia,
+		RoleDoctor:   c.Doctor,
+		RoleSheriff:  c.Sheriff,
+	}
+}
+
+// Validate checks the counts can deal a game of n players that doesn't end at once.
+func (c RoleCounts) Validate(n int) error {
+	if c.Mafia < 1 {
+		return fmt.Errorf("%d players: need at least 1 mafia", n)
+	}
+	if c.Doctor < 0 || c.Doctor > 1 || c.Sheriff < 0 || c.Sheriff > 1 {
+		return fmt.Errorf("%d players: doctor and sheriff counts must be 0 or 1", n)
+	}
+	if c.Mafia+c.Doctor+c.Sheriff > n {
+		return fmt.Errorf("%d players: %d special roles don't fit", n, c.Mafia+c.Doctor+c.Sheriff)
+	}
+	if c.Mafia >= n-c.Mafia {
+		return fmt.Errorf("%d players: %d mafia would win immediately", n, c.Mafia)
+	}
+	return nil
+}
+
+// RoleDistribution returns the roles to deal for n players.
+// A nil ruleset, or one without an entry for n, falls back to GetRoleDistribution.
+func (r *Ruleset) RoleDistribution(n int) map[Role]int {
+	if r != nil {
+		if counts, ok := r.Distributions[n]; ok {
+			return counts.Distribution(n)
+		}
+	}
+	return GetRoleDistribution(n)
+}
+
+// PhaseDuration returns how long the game's current phase lasts: the
+// phase's duration policy applied to the game, or fallback (the configured
+// timeout) for a nil ruleset or a phase without a policy.
+func (r *Ruleset) PhaseDuration(g *GameState, fallback time.Duration) time.Duration {
+	if r != nil {
+		if policy, ok := r.Durations[g.Phase.String()]; ok {
+			return policy.Duration(g, fallback)
+		}
+	}
+	return fallback
+}
+
+// PhaseExtension returns how much longer the game's current phase runs after
+// busy chat, see DurationPolicy.Extension. 0 for a nil ruleset or a phase
+// without a policy.
+func (r *Ruleset) PhaseExtension(g *GameState, chats int, length time.Duration) time.Duration {
+	if r != nil {
+		if policy, ok := r.Durations[g.Phase.String()]; ok {
+			return policy.Extension(g, chats, length)
+		}
+	}
+	return 0
+}
+
+// ParseRuleset decodes a JSON ruleset and validates it.
+func ParseRuleset(data []byte) (*Ruleset, error) {
+	var ruleset Ruleset
+	if err := json.Unmarshal(data, &ruleset); err != nil {
+		return nil, fmt.Errorf("failed to parse ruleset: %w", err)
+	}
+	if err := ruleset.Validate(); err != nil {
+		return nil, err
+	}
+	return &ruleset, nil
+}
+
+// Validate checks the ruleset for inconsistent values.
+func (r *Ruleset) Validate() error {
+	if r.Name == "" {
+		return errors.New("ruleset name must not be empty")
+	}
+
+	seen := make(map[string]bool, len(r.Traits))
+	for _, trait := range r.Traits {
+		if trait.Name == "" {
+			return errors.New("ruleset trait name must not be empty")
+		}
+		if seen[trait.Name] {
+			return fmt.Errorf("ruleset has duplicate trait %q", trait.Name)
+		}
+		seen[trait.Name] = true
+	}
+
+	for n, counts := range r.Distributions {
+		if n <= 0 {
+			return fmt.Errorf("ruleset distribution for %d players is invalid", n)
+		}
+		if err := counts.Validate(n); err != nil {
+			return fmt.Errorf("ruleset distribution: %w", err)
+		}
+	}
+
+	for name, policy := range r.Durations {
+		if phase, ok := ParsePhase(name); !ok || phase == PhaseWaiting || phase == PhaseEnded {
+			return fmt.Errorf("ruleset duration for unknown phase %q", name)
+		}
+		if err := policy.Validate(); err != nil {
+			return fmt.Errorf("ruleset %s duration: %w", name, err)
+		}
+	}
+
+	return nil
+}
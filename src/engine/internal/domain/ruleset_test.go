@@ -0,0 +1,76 @@
+package domain
+
+import "testing"
+
+func TestParseRuleset(t *testing.T) {
+	data := []byte(`{"name":"calm","traits":[{"name":"timid","description":"quiet"},{"name":"neutral"}]}`)
+
+	ruleset, err := ParseRuleset(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if ruleset.Name != "calm" || len(ruleset.Traits) != 2 {
+		t.Errorf("unexpected ruleset: %+v", ruleset)
+	}
+}
+
+func TestParseRuleset_Invalid(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+	}{
+		{"not json", `nope`},
+		{"missing name", `{"traits":[]}`},
+		{"duplicate trait", `{"name":"x","traits":[{"name":"a"},{"name":"a"}]}`},
+		{"empty trait", `{"name":"x","traits":[{"name":""}]}`},
+		{"no mafia", `{"name":"x","distributions":{"6":{"mafia":0,"doctor":1,"sheriff":1}}}`},
+		{"mafia majority", `{"name":"x","distributions":{"6":{"mafia":3,"doctor":1,"sheriff":1}}}`},
+		{"two doctors", `{"name":"x","distributions":{"6":{"mafia":1,"doctor":2,"sheriff":1}}}`},
+		{"too many roles", `{"name":"x","distributions":{"2":{"mafia":1,"doctor":1,"sheriff":1}}}`},
+		{"duration for unknown phase", `{"name":"x","durations":{"dusk":{"base":"1m"}}}`},
+		{"duration not a string", `{"name":"x","durations":{"day":{"base":60}}}`},
+		{"negative duration", `{"name":"x","durations":{"day":{"per_alive":"-5s"}}}`},
+		{"min above max", `{"name":"x","durations":{"day":{"min":"5m","max":"1m"}}}`},
+		{"round zero", `{"name":"x","durations":{"day":{"rounds":{"0":"5m"}}}}`},
+		{"extension without threshold", `{"name":"x","durations":{"day":{"chat_extension":"1m","max":"5m"}}}`},
+		{"extension without max", `{"name":"x","durations":{"day":{"chat_per_player":1,"chat_extension":"1m"}}}`},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if _, err := ParseRuleset([]byte(tt.data)); err == nil {
+				t.Error("expected error")
+			}
+		})
+	}
+}
+
+func TestRuleset_RoleDistribution(t *testing.T) {
+	data := []byte(`{"name":"lean","distributions":{"7":{"mafia":2,"doctor":1,"sheriff":0}}}`)
+	ruleset, err := ParseRuleset(data)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	got := ruleset.RoleDistribution(7)
+	if got[RoleMafia] != 2 || got[RoleDoctor] != 1 || got[RoleSheriff] != 0 || got[RoleVillager] != 4 {
+		t.Errorf("unexpected distribution for 7 players: %v", got)
+	}
+
+	// player counts without an entry fall back to the default rules
+	if got, want := ruleset.RoleDistribution(9), GetRoleDistribution(9); got[RoleMafia] != want[RoleMafia] {
+		t.Errorf("expected default distribution for 9 players, got %v", got)
+	}
+
+	var none *Ruleset
+	if got := none.RoleDistribution(6); got[RoleMafia] != 2 {
+		t.Errorf("nil ruleset should use defaults, got %v", got)
+	}
+}
+
+func TestRoleCountsOf(t *testing.T) {
+	roles := []Role{RoleMafia, RoleVillager, RoleSheriff, RoleMafia, RoleDoctor, RoleVillager}
+	if got := RoleCountsOf(roles); got != (RoleCounts{Mafia: 2, Doctor: 1, Sheriff: 1}) {
+		t.Errorf("got %+v", got)
+	}
+}
@@ -4,7 +4,7 @@ package domain
 
 import (
 	"fmt"
-	"math/rand"
+	"sort"
 
 	"github.com/xyproto/randomstring"
 )
@@ -14,6 +14,12 @@ type GameState struct {
 	// unique game ID
 	ID string
 
+	// seed for the game RNG, see rng.go
+	Seed int64
+
+	// name pack the player names were drawn from
+	NamePack string
+
 	// current status
 	Round  int
 	Phase  Phase
@@ -32,9 +38,49 @@ type GameState struct {
 	DoctorTarget  string
 	SheriffTarget string
 
+	// player who sent the sheriff's action, so the result goes to them
+	SheriffActor string
+
 	// night action history for rule enforcement
 	PreviousDoctorTarget string // Track last save (can't save same person twice in a row)
 	SheriffUsedBullet    bool   // Sheriff only has one bullet
+
+	// resolved sheriff investigations, in order (private to the sheriff)
+	Investigations []Investigation
+
+	// resolved nights, in order (engine-side record for analysis, never sent as is)
+	Nights []NightOutcome
+
+	// players' thoughts, in order (observers only, see thoughts.go)
+	Thoughts []Thought
+
+	// public chat messages in the current phase, for duration policies
+	PhaseChat int
+
+	// when the current phase times out (Unix ms), 0 if it has no timeout.
+	// Set by the engine when it schedules the phase timer.
+	PhaseEndsAt int64
+}
+
+// Investigation is the result of a sheriff's night action
+type Investigation struct {
+	Round     int
+	SheriffID string
+	TargetID  string
+	IsMafia   bool
+}
+
+// NightOutcome records what happened in one night
+type NightOutcome struct {
+	Round        int
+	MafiaTarget  string // "" if the mafia didn't act
+	DoctorTarget string // "" if the doctor didn't act
+	Killed       string // "" if nobody died
+}
+
+// Saved returns true if the doctor protected the mafia's target
+func (n NightOutcome) Saved() bool {
+	return n.MafiaTarget != "" && n.MafiaTarget == n.DoctorTarget
 }
 
 // set winner type
@@ -85,6 +131,45 @@ func (g *GameState) GetAlivePlayers() []*Player {
 	return alive
 }
 
+// GetPlayersWithRole returns all players (dead or alive) with the given role, sorted by ID
+func (g *GameState) GetPlayersWithRole(role Role) []*Player {
+	var players []*Player
+	for _, player := range g.Players {
+		if player.Role == role {
+			players = append(players, player)
+		}
+	}
+	sortPlayersByID(players)
+	return players
+}
+
+// GetInvestigations returns the investigations made by the given sheriff
+func (g *GameState) GetInvestigations(sheriffID string) []Investigation {
+	var results []Investigation
+	for _, inv := range g.Investigations {
+		if inv.SheriffID == sheriffID {
+			results = append(results, inv)
+		}
+	}
+	return results
+}
+
+// GetSeatedPlayers returns all players (dead or alive) in seat order
+// Players without a seat (0) come first, ties are broken by ID
+func (g *GameState) GetSeatedPlayers() []*Player {
+	players := make([]*Player, 0, len(g.Players))
+	for _, player := range g.Players {
+		players = append(players, player)
+	}
+	sort.Slice(players, func(i, j int) bool {
+		if players[i].Seat != players[j].Seat {
+			return players[i].Seat < players[j].Seat
+		}
+		return players[i].ID < players[j].ID
+	})
+	return players
+}
+
 // GetPlayerCount returns the total number of players in the game
 func (g *GameState) GetPlayerCount() int {
 	return len(g.Players)
@@ -128,12 +213,36 @@ func (g *GameState) IsGameOver() bool {
 	return false
 }
 
+// Clone returns a deep copy of the game state
+// Mutating the copy (e.g. replaying commands on it) never touches the original
+func (g *GameState) Clone() *GameState {
+	clone := *g
+
+	clone.Players = make(map[string]*Player, len(g.Players))
+	for id, player := range g.Players {
+		clone.Players[id] = player.Clone()
+	}
+
+	clone.Votes = make(map[string]string, len(g.Votes))
+	for voter, target := range g.Votes {
+		clone.Votes[voter] = target
+	}
+
+	// append to nil so an empty history stays nil, like a fresh state
+	clone.Investigations = append([]Investigation(nil), g.Investigations...)
+	clone.Nights = append([]NightOutcome(nil), g.Nights...)
+	clone.Thoughts = append([]Thought(nil), g.Thoughts...)
+
+	return &clone
+}
+
 // --- mutating game state --- //
 
 // NewGameState initializes a new game state with the given ID prefix.
 func NewGameState(idPrefix string) *GameState {
 	return &GameState{
 		ID:     CreateGameID(idPrefix),
+		Seed:   NewSeed(),
 		Round:  1,
 		Phase:  PhaseWaiting,
 		Winner: WinnerNone,
@@ -149,34 +258,108 @@ func NewGameState(idPrefix string) *GameState {
 // Example: "game-a3k9m" or "dev-x7p2q"
 func CreateGameID(prefix string) string {
 	const idlength = 5
-	randomSuffix := randomstring.String(idlength)
+	randomSuffix := randomstring.CookieFriendlyString(idlength) // alphanumeric: IDs travel in JSON and Kafka keys
 	return fmt.Sprintf("%s-%s", prefix, randomSuffix)
 }
 
+// ShufflePlayerOrder returns the alive players in a random order
+// Starts from seat order and shuffles with the game RNG, so the result
+// only depends on the seed
 func (g *GameState) ShufflePlayerOrder() []*Player {
-	players := g.GetAlivePlayers()
+	var players []*Player
+	for _, player := range g.GetSeatedPlayers() {
+		if player.Alive {
+			players = append(players, player)
+		}
+	}
 
-	rand.Shuffle(len(players), func(i, j int) {
+	rng := g.RNG(RNGStreamRoles)
+	rng.Shuffle(len(players), func(i, j int) {
 		players[i], players[j] = players[j], players[i]
 	})
 
 	return players
 }
 
+// roleDealOrder fixes the order roles are dealt in (map iteration is random)
+var roleDealOrder = []Role{RoleMafia, RoleDoctor, RoleSheriff, RoleVillager}
+
 // assign roles to players, takes map[Role]int from 'GetRoleDistribution()'
 func (g *GameState) AssignRolesToPlayers(roleDistribution map[Role]int) {
 	shuffledPlayers := g.ShufflePlayerOrder()
 	playerIndex := 0
 
-	for role, count := range roleDistribution {
-		for range count {
+	for _, role := range roleDealOrder {
+		for range roleDistribution[role] {
 			shuffledPlayers[playerIndex].Role = role
 			playerIndex++
 		}
 	}
 }
 
+// AssignRoles deals the distribution with some seats pinned to a role (seat -> role)
+// Pinned players get their role, the rest of the distribution is dealt to the
+// other players with the game RNG; without pins this is AssignRolesToPlayers
+func (g *GameState) AssignRoles(roleDistribution map[Role]int, pinned map[int]Role) error {
+	if len(pinned) == 0 {
+		g.AssignRolesToPlayers(roleDistribution)
+		return nil
+	}
+	if err := ValidatePinnedRoles(pinned, roleDistribution); err != nil {
+		return err
+	}
+
+	seated := make(map[int]bool, len(g.Players))
+	for _, player := range g.Players {
+		seated[player.Seat] = true
+	}
+	for seat := range pinned {
+		if !seated[seat] {
+			return fmt.Errorf("no player in pinned seat %d", seat)
+		}
+	}
+
+	remaining := make(map[Role]int, len(roleDistribution))
+	for role, count := range roleDistribution {
+		remaining[role] = count
+	}
+	for _, role := range pinned {
+		remaining[role]--
+	}
+
+	// same shuffle as an unpinned deal, pinned players just skip it
+	var unpinned []*Player
+	for _, player := range g.ShufflePlayerOrder() {
+		if role, ok := pinned[player.Seat]; ok {
+			player.Role = role
+			player.RolePinned = true
+			continue
+		}
+		unpinned = append(unpinned, player)
+	}
+
+	playerIndex := 0
+	for _, role := range roleDealOrder {
+		for range remaining[role] {
+			unpinned[playerIndex].Role = role
+			playerIndex++
+		}
+	}
+	return nil
+}
+
+// HasPinnedRoles returns true if any player's role was pinned at game start
+func (g *GameState) HasPinnedRoles() bool {
+	for _, player := range g.Players {
+		if player.RolePinned {
+			return true
+		}
+	}
+	return false
+}
+
 // AddPlayer adds a player to the game
+// Assigns the next free seat if the player has none
 // Returns the added player, or nil if player with same ID already exists
 func (g *GameState) AddPlayer(player *Player) *Player {
 	// check if player already exists (prevent duplicates)
@@ -184,6 +367,16 @@ func (g *GameState) AddPlayer(player *Player) *Player {
 		return nil
 	}
 
+	// seats follow join order and never change, even after eliminations
+	if player.Seat == 0 {
+		for _, other := range g.Players {
+			if other.Seat > player.Seat {
+				player.Seat = other.Seat
+			}
+		}
+		player.Seat++
+	}
+
 	// add player to map: key = ID, value = pointer to player
 	g.Players[player.ID] = player
 	return player
@@ -210,19 +403,24 @@ func (g *GameState) EliminatePlayer(id string) *Player {
 }
 
 // ResetPhaseData clears all votes and night actions
-// Called between phases to start fresh
+// Called between phases (before Phase changes) to start fresh
 // Preserves PreviousDoctorTarget for rule enforcement
 func (g *GameState) ResetPhaseData() {
 	// clear day votes — create new empty map
 	g.Votes = make(map[string]string)
 
 	// save doctor target before clearing (for consecutive save rule)
-	g.PreviousDoctorTarget = g.DoctorTarget
+	// only when a night ends: day and voting resets must not forget last night's save
+	if g.Phase == PhaseNight {
+		g.PreviousDoctorTarget = g.DoctorTarget
+	}
 
 	// clear night actions — reset to zero value (empty string)
 	g.MafiaTarget = ""
 	g.DoctorTarget = ""
 	g.SheriffTarget = ""
+	g.SheriffActor = ""
+	g.PhaseChat = 0
 	// Note: SheriffUsedBullet persists across rounds (one bullet per game)
 }
 
@@ -263,14 +461,8 @@ func (g *GameState) RegisterVote(voterID, targetID string) bool {
 //   - sheriff already used their bullet
 //   - mafia/sheriff tries to target themselves (doctor CAN self-save)
 func (g *GameState) SetNightAction(role Role, actorID, targetID string) bool {
-	// validate role has night action
-	if !role.HasNightAction() {
-		return false
-	}
-
-	// validate target exists and is alive
-	target := g.Players[targetID]
-	if target == nil || !target.Alive {
+	// validate the target against the role's rules (see NightTargets)
+	if !g.canTarget(role, actorID, targetID) {
 		return false
 	}
 
@@ -281,36 +473,20 @@ func (g *GameState) SetNightAction(role Role, actorID, targetID string) bool {
 		if g.MafiaTarget != "" {
 			return false // already set
 		}
-		// Mafia cannot target themselves
-		if actorID == targetID {
-			return false
-		}
 		g.MafiaTarget = targetID
 
 	case RoleDoctor:
 		if g.DoctorTarget != "" {
 			return false // already set
 		}
-		// Doctor cannot save the same person two rounds in a row
-		if g.PreviousDoctorTarget == targetID {
-			return false
-		}
-		// Doctor CAN save themselves
 		g.DoctorTarget = targetID
 
 	case RoleSheriff:
 		if g.SheriffTarget != "" {
 			return false // already set
 		}
-		// Sheriff only has one bullet
-		if g.SheriffUsedBullet {
-			return false
-		}
-		// Sheriff cannot investigate themselves
-		if actorID == targetID {
-			return false
-		}
 		g.SheriffTarget = targetID
+		g.SheriffActor = actorID
 		g.SheriffUsedBullet = true // Mark bullet as used
 
 	default:
@@ -341,6 +517,48 @@ func (g *GameState) ResolveNightActions() string {
 	return g.MafiaTarget
 }
 
+// RecordNight stores the outcome of the current night
+// Must be called before ResetPhaseData clears the night targets
+func (g *GameState) RecordNight(killed string) NightOutcome {
+	outcome := NightOutcome{
+		Round:        g.Round,
+		MafiaTarget:  g.MafiaTarget,
+		DoctorTarget: g.DoctorTarget,
+		Killed:       killed,
+	}
+	g.Nights = append(g.Nights, outcome)
+	return outcome
+}
+
+// RecordChat counts a public chat message in the current phase
+func (g *GameState) RecordChat() {
+	g.PhaseChat++
+}
+
+// ResolveInvestigation records the sheriff's investigation for this night
+// Returns nil if the sheriff didn't act
+// The result belongs to the sheriff who sent the action
+// Must be called before ResetPhaseData clears SheriffTarget
+func (g *GameState) ResolveInvestigation() *Investigation {
+	if g.SheriffTarget == "" {
+		return nil
+	}
+
+	target := g.Players[g.SheriffTarget]
+	if target == nil || g.SheriffActor == "" {
+		return nil
+	}
+
+	inv := Investigation{
+		Round:     g.Round,
+		SheriffID: g.SheriffActor,
+		TargetID:  target.ID,
+		IsMafia:   target.Role.IsMafiaTeam(),
+	}
+	g.Investigations = append(g.Investigations, inv)
+	return &inv
+}
+
 // ResolveVotingPhase tallies votes and returns eliminated player ID
 // Returns empty string if no one was eliminated (tie or no votes)
 func (g *GameState) ResolveVotingPhase() string {
@@ -357,3 +575,10 @@ func (g *GameState) ResolveVotingPhase() string {
 
 	return winner
 }
+
+// sortPlayersByID sorts players in place so results don't depend on map order
+func sortPlayersByID(players []*Player) {
+	sort.Slice(players, func(i, j int) bool {
+		return players[i].ID < players[j].ID
+	})
+}
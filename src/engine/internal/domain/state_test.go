@@ -67,6 +67,13 @@ func TestCreateGameID(t *testing.T) {
 	}
 
 	// IDs with same prefix should have different random suffixes
+	// IDs must survive a JSON round trip unchanged
+	for _, r := range id1[5:] {
+		if !(r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9') {
+			t.Errorf("game ID suffix must be alphanumeric, got %q", id1)
+		}
+	}
+
 	id3 := CreateGameID("test")
 	if id1 == id3 {
 		t.Error("two game IDs with same prefix should have different suffixes")
@@ -378,6 +385,27 @@ func TestResetPhaseData(t *testing.T) {
 	}
 }
 
+func TestResetPhaseData_KeepsLastNightsSave(t *testing.T) {
+	game := createTestGame(3)
+	game.Phase = PhaseNight
+	game.SetNightAction(RoleDoctor, "player-1", "player-2")
+
+	// night -> day -> voting -> night
+	game.ResetPhaseData()
+	game.Phase = PhaseDay
+	game.ResetPhaseData()
+	game.Phase = PhaseVoting
+	game.ResetPhaseData()
+	game.Phase = PhaseNight
+
+	if game.PreviousDoctorTarget != "player-2" {
+		t.Errorf("expected last night's save to be remembered, got %q", game.PreviousDoctorTarget)
+	}
+	if game.SetNightAction(RoleDoctor, "player-1", "player-2") {
+		t.Error("doctor saved the same player two nights in a row")
+	}
+}
+
 // --- ShufflePlayerOrder Tests ---
 
 func TestShufflePlayerOrder_ReturnsSameCount(t *testing.T) {
@@ -456,6 +484,94 @@ func TestAssignRolesToPlayers(t *testing.T) {
 	}
 }
 
+func TestAssignRolesToPlayers_SameSeedSameRoles(t *testing.T) {
+	first := createTestGame(9)
+	first.Seed = 7
+	first.AssignRolesToPlayers(GetRoleDistribution(9))
+
+	second := createTestGame(9)
+	second.Seed = 7
+	second.AssignRolesToPlayers(GetRoleDistribution(9))
+
+	for id, player := range first.Players {
+		if second.Players[id].Role != player.Role {
+			t.Errorf("%s: same seed gave %s and %s", id, player.Role, second.Players[id].Role)
+		}
+	}
+}
+
+func TestAssignRoles_PinnedSeats(t *testing.T) {
+	ResetPlayerCounter()
+	game := createTestGame(9)
+	pinned := map[int]Role{2: RoleSheriff, 5: RoleMafia}
+
+	if err := game.AssignRoles(GetRoleDistribution(9), pinned); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	counts := make(map[Role]int)
+	for _, player := range game.Players {
+		counts[player.Role]++
+		role, isPinned := pinned[player.Seat]
+		if player.RolePinned != isPinned {
+			t.Errorf("seat %d: RolePinned = %v", player.Seat, player.RolePinned)
+		}
+		if isPinned && player.Role != role {
+			t.Errorf("seat %d: expected %s, got %s", player.Seat, role, player.Role)
+		}
+	}
+	for role, count := range GetRoleDistribution(9) {
+		if counts[role] != count {
+			t.Errorf("expected %d %s, got %d", count, role, counts[role])
+		}
+	}
+	if !game.HasPinnedRoles() {
+		t.Error("expected HasPinnedRoles")
+	}
+}
+
+func TestAssignRoles_NoPinsDealsAsBefore(t *testing.T) {
+	first := createTestGame(9)
+	first.Seed = 7
+	first.AssignRolesToPlayers(GetRoleDistribution(9))
+
+	second := createTestGame(9)
+	second.Seed = 7
+	if err := second.AssignRoles(GetRoleDistribution(9), nil); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for id, player := range first.Players {
+		if second.Players[id].Role != player.Role {
+			t.Errorf("%s: pinless deal gave %s, expected %s", id, second.Players[id].Role, player.Role)
+		}
+	}
+	if second.HasPinnedRoles() {
+		t.Error("expected no pinned roles")
+	}
+}
+
+func TestAssignRoles_RejectsPins(t *testing.T) {
+	tests := map[string]map[int]Role{
+		"too many sheriffs": {1: RoleSheriff, 2: RoleSheriff},
+		"empty seat":        {7: RoleMafia},
+		"unknown role":      {1: RoleUnknown},
+	}
+	for name, pinned := range tests {
+		t.Run(name, func(t *testing.T) {
+			game := createTestGame(6)
+			if err := game.AssignRoles(GetRoleDistribution(6), pinned); err == nil {
+				t.Error("expected error")
+			}
+			for _, player := range game.Players {
+				if player.Role != RoleUnknown {
+					t.Errorf("%s was dealt %s despite the error", player.ID, player.Role)
+				}
+			}
+		})
+	}
+}
+
 // testing scenerios on 4 players
 func TestIsGameOver(t *testing.T) {
 	tests := []struct {
@@ -507,3 +623,152 @@ func TestIsGameOver(t *testing.T) {
 		})
 	}
 }
+
+// --- Investigation Tests ---
+
+func TestResolveInvestigation(t *testing.T) {
+	game := createTestGame(4)
+	game.Round = 2
+	game.Players["player-1"].Role = RoleSheriff
+	game.Players["player-2"].Role = RoleMafia
+	game.Players["player-3"].Role = RoleVillager
+	game.Players["player-4"].Role = RoleVillager
+
+	game.SetNightAction(RoleSheriff, "player-1", "player-2")
+
+	inv := game.ResolveInvestigation()
+	if inv == nil {
+		t.Fatal("expected investigation result")
+	}
+	if inv.SheriffID != "player-1" || inv.TargetID != "player-2" || !inv.IsMafia || inv.Round != 2 {
+		t.Errorf("unexpected investigation: %+v", inv)
+	}
+	if got := game.GetInvestigations("player-1"); len(got) != 1 {
+		t.Errorf("expected 1 recorded investigation, got %d", len(got))
+	}
+	if got := game.GetInvestigations("player-3"); len(got) != 0 {
+		t.Errorf("other players should have no investigations, got %d", len(got))
+	}
+}
+
+func TestResolveInvestigation_GoesToActingSheriff(t *testing.T) {
+	game := createTestGame(4)
+	game.Players["player-1"].Role = RoleSheriff
+	game.Players["player-2"].Role = RoleSheriff
+	game.Players["player-3"].Role = RoleMafia
+
+	game.SetNightAction(RoleSheriff, "player-2", "player-3")
+
+	inv := game.ResolveInvestigation()
+	if inv == nil || inv.SheriffID != "player-2" {
+		t.Fatalf("expected player-2's investigation, got %+v", inv)
+	}
+	if got := game.GetInvestigations("player-1"); len(got) != 0 {
+		t.Errorf("the other sheriff should not learn the result, got %d", len(got))
+	}
+}
+
+func TestResolveInvestigation_NoAction(t *testing.T) {
+	game := createTestGame(3)
+
+	if inv := game.ResolveInvestigation(); inv != nil {
+		t.Errorf("expected nil without sheriff action, got %+v", inv)
+	}
+}
+
+func TestRecordNight(t *testing.T) {
+	game := createTestGame(4)
+	game.Round = 3
+	game.Players["player-1"].Role = RoleMafia
+	game.Players["player-2"].Role = RoleDoctor
+
+	game.SetNightAction(RoleMafia, "player-1", "player-3")
+	game.SetNightAction(RoleDoctor, "player-2", "player-3")
+
+	outcome := game.RecordNight(game.ResolveNightActions())
+	if outcome.Killed != "" || !outcome.Saved() || outcome.Round != 3 {
+		t.Errorf("expected a save in round 3, got %+v", outcome)
+	}
+
+	game.ResetPhaseData()
+	outcome = game.RecordNight(game.ResolveNightActions())
+	if outcome.Saved() || outcome.MafiaTarget != "" {
+		t.Errorf("expected an empty night, got %+v", outcome)
+	}
+	if len(game.Nights) != 2 {
+		t.Errorf("expected 2 recorded nights, got %d", len(game.Nights))
+	}
+}
+
+// --- Clone Tests ---
+
+func TestClone_IsDeep(t *testing.T) {
+	game := createTestGame(4)
+	game.Players["player-1"].Role = RoleMafia
+	game.Players["player-1"].Persona = map[string]string{"bio": "quiet"}
+	game.Phase = PhaseVoting
+	game.RegisterVote("player-1", "player-2")
+	game.Nights = []NightOutcome{{Round: 1, MafiaTarget: "player-3"}}
+	game.Investigations = []Investigation{{Round: 1, TargetID: "player-1", IsMafia: true}}
+
+	clone := game.Clone()
+	if clone.ID != game.ID || clone.Seed != game.Seed || clone.Phase != PhaseVoting {
+		t.Fatalf("clone lost scalar fields: %+v", clone)
+	}
+
+	// mutate everything reachable from the clone
+	clone.EliminatePlayer("player-2")
+	clone.Players["player-1"].Persona["bio"] = "loud"
+	clone.RegisterVote("player-3", "player-1")
+	clone.Nights[0].Killed = "player-3"
+	clone.Investigations[0].IsMafia = false
+	clone.ResetPhaseData()
+
+	if !game.Players["player-2"].Alive {
+		t.Error("eliminating in the clone killed the original player")
+	}
+	if game.Players["player-1"].Persona["bio"] != "quiet" {
+		t.Error("persona is shared with the clone")
+	}
+	if len(game.Votes) != 1 {
+		t.Errorf("votes are shared with the clone: %v", game.Votes)
+	}
+	if game.Nights[0].Killed != "" || !game.Investigations[0].IsMafia {
+		t.Error("history is shared with the clone")
+	}
+}
+
+func TestClone_EmptyHistoryStaysNil(t *testing.T) {
+	clone := createTestGame(2).Clone()
+	if clone.Nights != nil || clone.Investigations != nil {
+		t.Error("expected nil history in the clone of a fresh game")
+	}
+}
+
+// --- Seat Tests ---
+
+func TestAddPlayerAssignsSeats(t *testing.T) {
+	game := createTestGame(3)
+
+	for i, player := range game.GetSeatedPlayers() {
+		if player.Seat != i+1 {
+			t.Errorf("%s: got seat %d, expected %d", player.ID, player.Seat, i+1)
+		}
+	}
+
+	// seats are not reused after elimination
+	game.EliminatePlayer("player-2")
+	late, _ := NewPlayer("late", "Late Joiner", RoleUnknown)
+	game.AddPlayer(late)
+	if late.Seat != 4 {
+		t.Errorf("late joiner: got seat %d, expected 4", late.Seat)
+	}
+
+	// preset seats are kept
+	pinned, _ := NewPlayer("pinned", "Pinned", RoleUnknown)
+	pinned.Seat = 9
+	game.AddPlayer(pinned)
+	if pinned.Seat != 9 {
+		t.Errorf("preset seat overwritten: got %d", pinned.Seat)
+	}
+}
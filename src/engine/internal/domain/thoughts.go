@@ -0,0 +1,47 @@
+package domain
+
+// Thought is a player's private reasoning, kept for observers and analysis.
+// It is never shown to players.
+type Thought struct {
+	ID       int64 // per game, from 1
+	PlayerID string
+	Round    int
+	Phase    Phase
+	Text     string // after redaction and truncation
+
+	// the action the thought preceded ("" until the player acts), see LinkThoughts
+	Action   string
+	TargetID string
+}
+
+// AddThought records a thought in the current round and phase.
+func (g *GameState) AddThought(playerID, text string) Thought {
+	thought := Thought{
+		ID:       int64(len(g.Thoughts) + 1),
+		PlayerID: playerID,
+		Round:    g.Round,
+		Phase:    g.Phase,
+		Text:     text,
+	}
+	g.Thoughts = append(g.Thoughts, thought)
+	return thought
+}
+
+// LinkThoughts attaches the player's action to every thought the player had
+// since their last action, and returns their IDs in order.
+func (g *GameState) LinkThoughts(playerID, action, targetID string) []int64 {
+	var ids []int64
+	for i := len(g.Thoughts) - 1; i >= 0; i-- {
+		thought := &g.Thoughts[i]
+		if thought.PlayerID != playerID {
+			continue
+		}
+		if thought.Action != "" {
+			break // linked to an earlier action
+		}
+		thought.Action = action
+		thought.TargetID = targetID
+		ids = append([]int64{thought.ID}, ids...)
+	}
+	return ids
+}
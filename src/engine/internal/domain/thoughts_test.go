@@ -0,0 +1,37 @@
+package domain
+
+import (
+	"reflect"
+	"testing"
+)
+
+func TestLinkThoughts(t *testing.T) {
+	game := createTestGame(3)
+	game.Phase = PhaseVoting
+
+	game.AddThought("player-1", "player-2 is quiet")
+	game.AddThought("player-2", "no idea")
+	first := game.AddThought("player-1", "vote player-2")
+	if first.ID != 3 || first.Round != 1 || first.Phase != PhaseVoting {
+		t.Fatalf("unexpected thought %+v", first)
+	}
+
+	if ids := game.LinkThoughts("player-1", "vote", "player-2"); !reflect.DeepEqual(ids, []int64{1, 3}) {
+		t.Errorf("linked %v, want [1 3]", ids)
+	}
+	if game.Thoughts[1].Action != "" {
+		t.Errorf("linked another player's thought: %+v", game.Thoughts[1])
+	}
+
+	// only thoughts since the last action are linked
+	game.AddThought("player-1", "that went well")
+	if ids := game.LinkThoughts("player-1", "chat", ""); !reflect.DeepEqual(ids, []int64{4}) {
+		t.Errorf("linked %v, want [4]", ids)
+	}
+	if ids := game.LinkThoughts("player-1", "chat", ""); ids != nil {
+		t.Errorf("linked %v without new thoughts", ids)
+	}
+	if game.Thoughts[0].TargetID != "player-2" {
+		t.Errorf("earlier link overwritten: %+v", game.Thoughts[0])
+	}
+}
@@ -0,0 +1,67 @@
+// This file containes personality traits assigned to players
+
+package domain
+
+import (
+	"errors"
+	"fmt"
+)
+
+// Trait is a personality given to a player's agent as a playstyle prompt.
+type Trait struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+// BuiltinTraits is the default trait catalogue.
+var BuiltinTraits = []Trait{
+	{Name: "timid", Description: "Cautious and quiet. Rarely accuses anyone and follows the majority."},
+	{Name: "aggressive", Description: "Bold and confrontational. Accuses early and pushes hard for votes."},
+	{Name: "neutral", Description: "Balanced. Weighs the evidence and speaks when it matters."},
+	{Name: "analytical", Description: "Methodical. Tracks votes and claims and points out contradictions."},
+	{Name: "chatty", Description: "Talkative and social. Shares opinions freely and asks many questions."},
+}
+
+// TraitsByName builds a trait catalogue from trait names.
+// Built-in traits keep their description, unknown names are accepted as custom traits.
+// Returns an error for empty or duplicate names.
+func TraitsByName(names []string) ([]Trait, error) {
+	builtin := make(map[string]Trait, len(BuiltinTraits))
+	for _, trait := range BuiltinTraits {
+		builtin[trait.Name] = trait
+	}
+
+	traits := make([]Trait, 0, len(names))
+	seen := make(map[string]bool, len(names))
+	for _, name := range names {
+		if name == "" {
+			return nil, errors.New("trait name must not be empty")
+		}
+		if seen[name] {
+			return nil, fmt.Errorf("duplicate trait %q", name)
+		}
+		seen[name] = true
+
+		if trait, ok := builtin[name]; ok {
+			traits = append(traits, trait)
+		} else {
+			traits = append(traits, Trait{Name: name})
+		}
+	}
+	return traits, nil
+}
+
+// AssignTraitsToPlayers draws a trait for every player, in seat order,
+// using the game RNG. Traits may repeat. Does nothing if the catalogue is empty.
+func (g *GameState) AssignTraitsToPlayers(catalogue []Trait) {
+	if len(catalogue) == 0 {
+		return
+	}
+
+	rng := g.RNG(RNGStreamTraits)
+	for _, player := range g.GetSeatedPlayers() {
+		trait := catalogue[rng.IntN(len(catalogue))]
+		player.Trait = trait.Name
+		player.TraitDescription = trait.Description
+	}
+}
@@ -0,0 +1,56 @@
+package domain
+
+import "testing"
+
+func TestTraitsByName(t *testing.T) {
+	traits, err := TraitsByName([]string{"timid", "sarcastic"})
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(traits) != 2 {
+		t.Fatalf("expected 2 traits, got %d", len(traits))
+	}
+	if traits[0].Description == "" {
+		t.Error("built-in trait should keep its description")
+	}
+	if traits[1].Name != "sarcastic" || traits[1].Description != "" {
+		t.Errorf("unexpected custom trait: %+v", traits[1])
+	}
+
+	if _, err := TraitsByName([]string{"timid", "timid"}); err == nil {
+		t.Error("expected error for duplicate trait")
+	}
+	if _, err := TraitsByName([]string{""}); err == nil {
+		t.Error("expected error for empty trait name")
+	}
+}
+
+func TestAssignTraitsToPlayers_Deterministic(t *testing.T) {
+	first := createTestGame(8)
+	first.Seed = 42
+	first.AssignTraitsToPlayers(BuiltinTraits)
+
+	second := createTestGame(8)
+	second.Seed = 42
+	second.AssignTraitsToPlayers(BuiltinTraits)
+
+	for id, player := range first.Players {
+		if player.Trait == "" {
+			t.Errorf("%s has no trait", id)
+		}
+		if second.Players[id].Trait != player.Trait {
+			t.Errorf("%s: same seed gave %q and %q", id, player.Trait, second.Players[id].Trait)
+		}
+	}
+}
+
+func TestAssignTraitsToPlayers_EmptyCatalogue(t *testing.T) {
+	game := createTestGame(3)
+	game.AssignTraitsToPlayers(nil)
+
+	for id, player := range game.Players {
+		if player.Trait != "" {
+			t.Errorf("%s got trait %q from empty catalogue", id, player.Trait)
+		}
+	}
+}
@@ -0,0 +1,88 @@
+package engine
+
+import (
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+)
+
+// actionRequests tells every player with something to do in the current
+// phase what the engine accepts from them (ActionRequested, privately), and
+// publishes who the phase waits on. The engine calls it once the phase timer
+// is scheduled, so the deadline is known.
+func actionRequests(state *domain.GameState) []Effect {
+	effects := []Effect{}
+	for _, player := range state.GetSeatedPlayers() {
+		actions := requestedActions(state, player)
+		if len(actions) == 0 {
+			continue
+		}
+		request := &events.ActionRequested{
+			BaseEvent: events.BaseEvent{
+				GameID: state.ID,
+				Type:   events.TypeActionRequested,
+			},
+			PlayerID: player.ID,
+			Actions:  actions,
+			Deadline: state.PhaseEndsAt,
+		}
+		effects = append(effects, NewPublishEffect(request))
+	}
+	return append(effects, actionStatus(state)...)
+}
+
+// requestedActions lists the actions the player may take in the current
+// phase, with the targets the rules allow (see domain.NightTargets).
+func requestedActions(state *domain.GameState, player *domain.Player) []events.RequestedAction {
+	if !player.Alive {
+		return nil
+	}
+
+	var actions []events.RequestedAction
+	switch state.Phase {
+	case domain.PhaseNight:
+		if targets := state.NightTargets(player.Role, player.ID); len(targets) > 0 {
+			actions = append(actions, events.RequestedAction{
+				Type:     events.ActionNightAction,
+				Role:     player.Role.String(),
+				Targets:  targets,
+				Required: true,
+			})
+		}
+		if player.Role.IsMafiaTeam() {
+			actions = append(actions, events.RequestedAction{Type: events.ActionMafiaChat})
+		}
+	case domain.PhaseDay:
+		actions = append(actions, events.RequestedAction{Type: events.ActionChat})
+	case domain.PhaseVoting:
+		actions = append(actions,
+			events.RequestedAction{Type: events.ActionVote, Targets: state.VoteTargets(), Required: true},
+			events.RequestedAction{Type: events.ActionChat},
+		)
+	}
+	return actions
+}
+
+// actionStatus publishes how many players the phase still waits on. Phases
+// without required actions have no status; the night's goes to observers.
+func actionStatus(state *domain.GameState) []Effect {
+	if state.Phase != domain.PhaseNight && state.Phase != domain.PhaseVoting {
+		return []Effect{}
+	}
+
+	outstanding := state.Outstanding()
+	status := &events.ActionStatus{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypeActionStatus,
+		},
+		Waiting: len(outstanding),
+	}
+	for _, player := range outstanding {
+		status.Players = append(status.Players, player.ID)
+	}
+
+	if state.Phase == domain.PhaseNight {
+		return []Effect{NewObserverEffect(status)}
+	}
+	return []Effect{NewPublishEffect(status)}
+}
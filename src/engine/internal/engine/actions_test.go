@@ -0,0 +1,135 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
+// Each phase opens with a request per player who can act, with the deadline,
+// and a status of who the phase waits on.
+func TestEngine_RequestsActions(t *testing.T) {
+	cfg := testConfig(t)
+	virtual := clock.NewVirtual(time.Unix(1000, 0))
+	producer := &fakeProducer{}
+	state := domain.NewGameState("test")
+	eng, err := NewEngine(state, producer, cfg, WithClock(virtual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+
+	// published since the last call, decoded
+	seen := 0
+	published := func() (requests map[string]*events.ActionRequested, statuses []*events.ActionStatus, topics []string) {
+		t.Helper()
+		requests = make(map[string]*events.ActionRequested)
+		for _, msg := range producer.messages[seen:] {
+			ev, err := events.DeserializeEngineEvent(msg.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch e := ev.(type) {
+			case *events.ActionRequested:
+				requests[e.PlayerID] = e
+			case *events.ActionStatus:
+				statuses = append(statuses, e)
+				topics = append(topics, msg.Topic)
+			}
+		}
+		seen = len(producer.messages)
+		return requests, statuses, topics
+	}
+
+	if err := eng.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	eng.ProcessPending()
+
+	// night: the roles with an action, privately; the status to observers
+	requests, statuses, topics := published()
+	for _, player := range state.GetSeatedPlayers() {
+		request := requests[player.ID]
+		if !player.Role.HasNightAction() {
+			if request != nil {
+				t.Errorf("%s (%s) asked to act at night", player.ID, player.Role)
+			}
+			continue
+		}
+		if request == nil {
+			t.Fatalf("%s (%s) not asked to act", player.ID, player.Role)
+		}
+		if request.Deadline != state.PhaseEndsAt || request.Deadline == 0 {
+			t.Errorf("deadline %d, want %d", request.Deadline, state.PhaseEndsAt)
+		}
+		action := request.Actions[0]
+		if action.Type != events.ActionNightAction || action.Role != player.Role.String() || !action.Required {
+			t.Errorf("unexpected night action %+v", action)
+		}
+		for _, target := range action.Targets {
+			if target == player.ID && player.Role != domain.RoleDoctor {
+				t.Errorf("%s may target itself", player.Role)
+			}
+		}
+		if player.Role == domain.RoleMafia && (len(request.Actions) != 2 || request.Actions[1].Type != events.ActionMafiaChat) {
+			t.Errorf("mafia not offered the mafia chat: %+v", request.Actions)
+		}
+	}
+	if len(statuses) != 1 || topics[0] != kafka.ObserverEventsTopic || statuses[0].Waiting != len(requests) {
+		t.Errorf("unexpected night status %+v on %v", statuses, topics)
+	}
+
+	// day: everyone alive may chat, nobody is waited on
+	virtual.Advance(cfg.PhaseNightTimeout)
+	eng.ProcessPending()
+	requests, statuses, _ = published()
+	alive := len(state.GetAlivePlayers())
+	if len(requests) != alive || len(statuses) != 0 {
+		t.Errorf("day: %d requests for %d alive players, %d statuses", len(requests), alive, len(statuses))
+	}
+
+	// voting: a public status after each vote
+	virtual.Advance(cfg.PhaseDayTimeout)
+	eng.ProcessPending()
+	requests, statuses, topics = published()
+	if len(statuses) != 1 || topics[0] != kafka.EngineEventsTopic || statuses[0].Waiting != alive {
+		t.Fatalf("unexpected voting status %+v on %v", statuses, topics)
+	}
+	voter := state.GetAlivePlayers()[0]
+	vote := requests[voter.ID].Actions[0]
+	if vote.Type != events.ActionVote || len(vote.Targets) != alive {
+		t.Fatalf("unexpected vote request %+v", vote)
+	}
+
+	data, _ := json.Marshal(&events.VoteSubmitted{
+		BaseEvent: events.BaseEvent{GameID: state.ID, Type: events.TypeVoteSubmitted},
+		VoterID:   voter.ID,
+		TargetID:  vote.Targets[0],
+	})
+	if err := eng.HandleMessage(context.Background(), kafka.Message{Value: data}); err != nil {
+		t.Fatal(err)
+	}
+	eng.ProcessPending()
+	_, statuses, _ = published()
+	if len(statuses) != 1 || statuses[0].Waiting != alive-1 || statuses[0].Seq == 0 {
+		t.Errorf("unexpected status after a vote %+v", statuses)
+	}
+	for _, id := range statuses[0].Players {
+		if id == voter.ID {
+			t.Errorf("%s voted but is still waited on", voter.ID)
+		}
+	}
+}
@@ -2,6 +2,8 @@ package engine
 
 import (
 	"fmt"
+	"strings"
+	"time"
 
 	"mafia-engine/internal/domain"
 	"mafia-engine/internal/events"
@@ -43,11 +45,52 @@ func (c *AddPlayerCommand) Apply(state *domain.GameState) ([]Effect, error) {
 	return []Effect{}, nil
 }
 
+// DeclareModelCommand records which model profile plays a seat, as announced
+// by the player agent when it connects (PlayerJoined). It emits nothing: the
+// profile is for analysis only. A player can re-announce itself (e.g. after a
+// restart), but once the game started it cannot switch to another profile,
+// and a seat reserved for a profile (Player.ProfilePinned) takes no other.
+type DeclareModelCommand struct {
+	PlayerID     string
+	ModelProfile string
+}
+
+// Apply implements the Command interface.
+func (c *DeclareModelCommand) Apply(state *domain.GameState) ([]Effect, error) {
+	profile := strings.TrimSpace(c.ModelProfile)
+	if profile == "" {
+		return nil, fmt.Errorf("model profile must not be empty")
+	}
+	if state.Phase == domain.PhaseEnded {
+		return nil, fmt.Errorf("game is over")
+	}
+
+	player := state.GetPlayer(c.PlayerID)
+	if player == nil {
+		return nil, fmt.Errorf("player %s not found", c.PlayerID)
+	}
+	if player.ProfilePinned && player.ModelProfile != profile {
+		return nil, fmt.Errorf("seat %d is reserved for %s", player.Seat, player.ModelProfile)
+	}
+	if state.Phase != domain.PhaseWaiting && player.ModelProfile != "" && player.ModelProfile != profile {
+		return nil, fmt.Errorf("player %s already plays as %s", c.PlayerID, player.ModelProfile)
+	}
+
+	player.ModelProfile = profile
+	return []Effect{}, nil
+}
+
 // StartGameCommand initializes the game by assigning roles and emitting GameStarted.
 // This should be called after all players have been added.
 type StartGameCommand struct {
-	MinPlayers int // Minimum required players to start
-	MaxPlayers int // Maximum allowed players
+	MinPlayers int             // Minimum required players to start
+	MaxPlayers int             // Maximum allowed players
+	Traits     []domain.Trait  // Personality trait catalogue (empty = no traits)
+	Ruleset    *domain.Ruleset // Role distribution overrides (nil = default rules)
+
+	// PinnedRoles fixes roles by seat (e.g. ENGINE_PINNED_ROLES, scenario files);
+	// unpinned seats are dealt the rest of the distribution with the game RNG
+	PinnedRoles map[int]domain.Role
 }
 
 func (c *StartGameCommand) Apply(state *domain.GameState) ([]Effect, error) {
@@ -63,11 +106,14 @@ func (c *StartGameCommand) Apply(state *domain.GameState) ([]Effect, error) {
 			c.MinPlayers, c.MaxPlayers, currentCount)
 	}
 
-	// Calculate role distribution using domain helper
-	roleDistribution := domain.GetRoleDistribution(currentCount)
+	// Calculate role distribution (ruleset override or default rules)
+	roleDistribution := c.Ruleset.RoleDistribution(currentCount)
 
-	// Use domain helper to assign roles
-	state.AssignRolesToPlayers(roleDistribution)
+	// Use domain helpers to assign roles and personality traits (seeded by the game RNG)
+	if err := state.AssignRoles(roleDistribution, c.PinnedRoles); err != nil {
+		return nil, fmt.Errorf("cannot start game: %w", err)
+	}
+	state.AssignTraitsToPlayers(c.Traits)
 
 	// Transition to Night phase (game starts at night for mafia coordination)
 	state.Phase = domain.PhaseNight
@@ -76,10 +122,10 @@ func (c *StartGameCommand) Apply(state *domain.GameState) ([]Effect, error) {
 	// Build effects
 	effects := []Effect{}
 
-	// Emit GameStarted event
+	// Emit GameStarted event (player IDs in seat order)
 	playerIDs := make([]string, 0, len(state.Players))
-	for id := range state.Players {
-		playerIDs = append(playerIDs, id)
+	for _, player := range state.GetSeatedPlayers() {
+		playerIDs = append(playerIDs, player.ID)
 	}
 
 	gameStartedEvent := &events.GameStarted{
@@ -88,29 +134,53 @@ func (c *StartGameCommand) Apply(state *domain.GameState) ([]Effect, error) {
 			Type:   events.TypeGameStarted,
 		},
 		Players: playerIDs,
+		Pinned:  state.HasPinnedRoles(),
 	}
 	effects = append(effects, NewPublishEffect(gameStartedEvent))
 
-	// Emit RoleAssigned events (one per player)
-	for _, player := range state.Players {
+	// Emit Roster so players can refer to each other by name
+	effects = append(effects, NewPublishEffect(newRosterEvent(state)))
+
+	// Emit RoleAssigned events (one per player, trait delivered alongside the role)
+	for _, player := range state.GetSeatedPlayers() {
 		roleEvent := &events.RoleAssigned{
 			BaseEvent: events.BaseEvent{
 				GameID: state.ID,
 				Type:   events.TypeRoleAssigned,
 			},
-			PlayerID: player.ID,
-			Role:     player.Role.String(),
+			PlayerID:         player.ID,
+			Role:             player.Role.String(),
+			Trait:            player.Trait,
+			TraitDescription: player.TraitDescription,
 		}
 		effects = append(effects, NewPublishEffect(roleEvent))
 	}
 
+	// Emit FactionRevealed events (one per member of a faction that knows its members)
+	// Players are visited in seat order so the event order is stable
+	for _, player := range state.GetSeatedPlayers() {
+		if !player.Role.Faction().KnowsMembers() {
+			continue
+		}
+		revealEvent := &events.FactionRevealed{
+			BaseEvent: events.BaseEvent{
+				GameID: state.ID,
+				Type:   events.TypeFactionRevealed,
+			},
+			PlayerID: player.ID,
+			Faction:  player.Role.Faction().String(),
+			Members:  factionMembers(state, player.ID),
+		}
+		effects = append(effects, NewPublishEffect(revealEvent))
+	}
+
 	// Emit PhaseChanged to indicate game has started in Night phase
 	phaseEvent := &events.PhaseChanged{
 		BaseEvent: events.BaseEvent{
 			GameID: state.ID,
 			Type:   events.TypePhaseChanged,
+			Round:  state.Round,
 		},
-		Round:    state.Round,
 		OldPhase: domain.PhaseWaiting.String(),
 		NewPhase: domain.PhaseNight.String(),
 	}
@@ -120,16 +190,17 @@ func (c *StartGameCommand) Apply(state *domain.GameState) ([]Effect, error) {
 }
 
 // VoteCommand records a player's vote during the voting phase.
-// This is a pure state mutation - no effects are emitted.
-// Votes are tallied silently and resolved at phase change.
+// Votes are tallied silently and resolved at phase change; only the number
+// of players still to vote is published (ActionStatus).
 type VoteCommand struct {
-	VoterID  string
-	TargetID string
+	VoterID   string
+	TargetID  string
+	Telemetry *events.Telemetry // optional, reported by the agent
 }
 
 // Apply implements the Command interface.
 // It validates the vote and records it in game state.
-// Returns empty effects slice - voting is silent.
+// The ballot itself stays secret until the phase closes.
 func (c *VoteCommand) Apply(state *domain.GameState) ([]Effect, error) {
 	// Validation: Check voting phase
 	if state.Phase != domain.PhaseVoting {
@@ -146,15 +217,17 @@ func (c *VoteCommand) Apply(state *domain.GameState) ([]Effect, error) {
 		return nil, fmt.Errorf("vote rejected: invalid voter/target or duplicate vote")
 	}
 
-	// No effects - votes are silent until tallied
-	return []Effect{}, nil
+	// Votes are silent until tallied, only who is left to vote is public
+	effects := actionStatus(state)
+	return append(effects, linkThoughts(state, c.VoterID, events.ActionVote, c.TargetID)...), nil
 }
 
 // ChatCommand handles public chat messages.
-// It doesn't mutate state but returns a PublishEffect for the engine to execute.
+// It only counts the message in state and returns a PublishEffect for the engine to execute.
 type ChatCommand struct {
-	SenderID string
-	Message  string
+	SenderID  string
+	Message   string
+	Telemetry *events.Telemetry // optional, reported by the agent
 }
 
 // Apply implements the Command interface.
@@ -170,7 +243,8 @@ func (c *ChatCommand) Apply(state *domain.GameState) ([]Effect, error) {
 		return nil, fmt.Errorf("sender %s is dead and cannot speak", c.SenderID)
 	}
 
-	// No state mutation - chat is stateless
+	// Only counted, for duration policies (busy days may last longer)
+	state.RecordChat()
 
 	// Create the event (without timestamp - engine will inject it)
 	event := &events.AllChatMessage{
@@ -185,14 +259,15 @@ func (c *ChatCommand) Apply(state *domain.GameState) ([]Effect, error) {
 
 	// Return effect for engine to execute
 	effect := NewPublishEffect(event)
-	return []Effect{effect}, nil
+	return append([]Effect{effect}, linkThoughts(state, c.SenderID, events.ActionChat, "")...), nil
 }
 
 // MafiaChatCommand handles private mafia chat messages.
 // Only mafia members can send these during night phase.
 type MafiaChatCommand struct {
-	SenderID string
-	Message  string
+	SenderID  string
+	Message   string
+	Telemetry *events.Telemetry // optional, reported by the agent
 }
 
 func (c *MafiaChatCommand) Apply(state *domain.GameState) ([]Effect, error) {
@@ -229,15 +304,17 @@ func (c *MafiaChatCommand) Apply(state *domain.GameState) ([]Effect, error) {
 
 	// Return effect for engine to execute
 	effect := NewPublishEffect(event)
-	return []Effect{effect}, nil
+	return append([]Effect{effect}, linkThoughts(state, c.SenderID, events.ActionMafiaChat, "")...), nil
 }
 
 // NightActionCommand handles mafia kills, doctor saves, sheriff investigations.
-// This is a pure state mutation - no effects until phase resolves.
+// Nothing public happens until the phase resolves (the updated ActionStatus
+// goes to observers).
 type NightActionCommand struct {
-	Role     string // "mafia", "doctor", "sheriff"
-	ActorID  string
-	TargetID string
+	Role      string // "mafia", "doctor", "sheriff"
+	ActorID   string
+	TargetID  string
+	Telemetry *events.Telemetry // optional, reported by the agent
 }
 
 func (c *NightActionCommand) Apply(state *domain.GameState) ([]Effect, error) {
@@ -273,26 +350,42 @@ func (c *NightActionCommand) Apply(state *domain.GameState) ([]Effect, error) {
 		return nil, fmt.Errorf("night action rejected: rules violated (check target validity, consecutive saves, or bullet usage)")
 	}
 
-	// No effects - night actions are secret until phase resolves
-	return []Effect{}, nil
+	// No public effects - night actions are secret until phase resolves
+	effects := actionStatus(state)
+	return append(effects, linkThoughts(state, c.ActorID, events.ActionNightAction, c.TargetID)...), nil
 }
 
 // PhaseChangeCommand transitions the game to a new phase.
 // This is complex - it mutates state AND returns multiple effects.
 type PhaseChangeCommand struct {
 	NewPhase domain.Phase
+
+	// Timeout is set by the phase timer (nil for other phase changes), so a
+	// timeout queued just before the phase ended or was extended is dropped
+	Timeout *PhaseTimeout
 }
 
 func (c *PhaseChangeCommand) Apply(state *domain.GameState) ([]Effect, error) {
+	// Validation: the timeout is for the phase that is running now
+	if c.Timeout != nil && c.Timeout.Stale(state) {
+		return nil, fmt.Errorf("stale timeout for %s of round %d", c.Timeout.Phase, c.Timeout.Round)
+	}
+
 	// Track eliminated player for event emission
 	var eliminatedPlayerID string
 	var eliminationReason string
+	var investigation *domain.Investigation
+	var voteResult *events.VoteResult
 
 	// Step 1: Resolve actions from PREVIOUS phase
 	switch state.Phase {
 	case domain.PhaseNight:
-		// Resolve night actions using domain helper
+		// Record the sheriff's investigation before phase data is cleared
+		investigation = state.ResolveInvestigation()
+
+		// Resolve night actions using domain helper and keep the outcome
 		eliminatedPlayerID = state.ResolveNightActions()
+		state.RecordNight(eliminatedPlayerID)
 		if eliminatedPlayerID != "" {
 			eliminationReason = "killed_by_mafia"
 			// Use domain helper to mark player as dead
@@ -302,6 +395,7 @@ func (c *PhaseChangeCommand) Apply(state *domain.GameState) ([]Effect, error) {
 	case domain.PhaseVoting:
 		// Resolve voting using domain helper
 		eliminatedPlayerID = state.ResolveVotingPhase()
+		voteResult = newVoteResultEvent(state, eliminatedPlayerID)
 		if eliminatedPlayerID != "" {
 			eliminationReason = "voted_out"
 			// Use domain helper to mark player as dead
@@ -327,13 +421,19 @@ func (c *PhaseChangeCommand) Apply(state *domain.GameState) ([]Effect, error) {
 	// Step 5: Build effects
 	effects := []Effect{}
 
+	// The votes are public once the voting phase closes
+	if voteResult != nil {
+		// ballots stay secret: players only learn who was eliminated
+		effects = append(effects, NewObserverEffect(voteResult))
+	}
+
 	// Always emit PhaseChanged event
 	phaseEvent := &events.PhaseChanged{
 		BaseEvent: events.BaseEvent{
 			GameID: state.ID,
 			Type:   events.TypePhaseChanged,
+			Round:  state.Round,
 		},
-		Round:    state.Round,
 		OldPhase: oldPhase.String(),
 		NewPhase: c.NewPhase.String(),
 	}
@@ -350,23 +450,58 @@ func (c *PhaseChangeCommand) Apply(state *domain.GameState) ([]Effect, error) {
 			Reason:   eliminationReason,
 		}
 		effects = append(effects, NewPublishEffect(eliminatedEvent))
+		effects = append(effects, NewPublishEffect(newRosterEvent(state)))
 	}
 
-	// If game ended, emit GameEnded event
-	if gameEnded {
-		gameEndedEvent := &events.GameEnded{
+	// Tell the sheriff (privately) what they found
+	if investigation != nil {
+		target := state.GetPlayer(investigation.TargetID)
+		resultEvent := &events.InvestigationResult{
 			BaseEvent: events.BaseEvent{
 				GameID: state.ID,
-				Type:   events.TypeGameEnded,
+				Type:   events.TypeInvestigation,
 			},
-			Winner: state.Winner.String(),
+			PlayerID: investigation.SheriffID,
+			TargetID: investigation.TargetID,
+			Faction:  target.Role.Faction().String(),
 		}
-		effects = append(effects, NewPublishEffect(gameEndedEvent))
+		effects = append(effects, NewPublishEffect(resultEvent))
+	}
+
+	// If game ended, emit GameEnded event (with the final reveal)
+	if gameEnded {
+		effects = append(effects, NewPublishEffect(newGameEndedEvent(state)))
 	}
 
 	return effects, nil
 }
 
+// PhaseWarningCommand announces that the current phase times out soon.
+// It is sent by the phase's warning timers (see TimerManager.ScheduleWarnings)
+// and doesn't change the game.
+type PhaseWarningCommand struct {
+	PhaseTimeout
+	Remaining time.Duration
+}
+
+func (c *PhaseWarningCommand) Apply(state *domain.GameState) ([]Effect, error) {
+	// Validation: the phase it warns about is still running with the same
+	// deadline (a timer may fire just as the phase ends early or is extended)
+	if c.Stale(state) {
+		return nil, fmt.Errorf("stale warning for %s of round %d", c.Phase, c.Round)
+	}
+
+	event := &events.PhaseEndingSoon{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypePhaseEndingSoon,
+		},
+		EndsAt:    state.PhaseEndsAt,
+		Remaining: c.Remaining.Milliseconds(),
+	}
+	return []Effect{NewPublishEffect(event)}, nil
+}
+
 // EliminatePlayerCommand removes a player from the game.
 // This mutates state AND returns effects (elimination + maybe game end).
 type EliminatePlayerCommand struct {
@@ -398,18 +533,233 @@ func (c *EliminatePlayerCommand) Apply(state *domain.GameState) ([]Effect, error
 		Reason:   c.Reason,
 	}
 	effects = append(effects, NewPublishEffect(eliminatedEvent))
+	effects = append(effects, NewPublishEffect(newRosterEvent(state)))
 
-	// If game ended, emit GameEnded event
+	// If game ended, emit GameEnded event (with the final reveal)
 	if gameEnded {
-		gameEndedEvent := &events.GameEnded{
-			BaseEvent: events.BaseEvent{
-				GameID: state.ID,
-				Type:   events.TypeGameEnded,
-			},
-			Winner: state.Winner.String(),
-		}
-		effects = append(effects, NewPublishEffect(gameEndedEvent))
+		effects = append(effects, NewPublishEffect(newGameEndedEvent(state)))
 	}
 
 	return effects, nil
 }
+
+// ResyncCommand answers a player's ResyncRequested with the events it missed.
+// It doesn't mutate state. History is injected by the engine loop before Apply.
+type ResyncCommand struct {
+	PlayerID string
+	LastSeq  int64
+	History  *EventLog
+}
+
+// Apply implements the Command interface.
+// Missed events are filtered by the requesting player's visibility.
+// If the log no longer retains the whole range, a public snapshot is sent instead.
+func (c *ResyncCommand) Apply(state *domain.GameState) ([]Effect, error) {
+	// Validation 1: Requesting player exists (dead players may still follow the game)
+	player := state.GetPlayer(c.PlayerID)
+	if player == nil {
+		return nil, fmt.Errorf("player %s not found", c.PlayerID)
+	}
+
+	// Validation 2: Engine wired the event log
+	if c.History == nil {
+		return nil, fmt.Errorf("resync unavailable: no event history")
+	}
+
+	reply := &events.Resync{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypeResync,
+		},
+		PlayerID: c.PlayerID,
+		FromSeq:  c.LastSeq + 1,
+		ToSeq:    c.History.LastSeq(),
+	}
+
+	missed, ok := c.History.Since(c.LastSeq)
+	if !ok {
+		reply.Snapshot = buildSnapshot(state)
+		return []Effect{NewPublishEffect(reply)}, nil
+	}
+
+	viewer := viewerFor(player)
+	for _, ev := range missed {
+		if !viewer.CanSee(ev) {
+			continue
+		}
+		raw, err := events.Marshal(ev)
+		if err != nil {
+			return nil, fmt.Errorf("failed to marshal missed event: %w", err)
+		}
+		reply.Events = append(reply.Events, raw)
+	}
+
+	return []Effect{NewPublishEffect(reply)}, nil
+}
+
+// StateRequestCommand answers a player's StateRequest with its own knowledge view.
+// It doesn't mutate state. History is injected by the engine loop before Apply.
+type StateRequestCommand struct {
+	PlayerID string
+	History  *EventLog
+}
+
+// Apply implements the Command interface.
+// The reply follows the same visibility rules as the original events:
+// the player's own role, faction mates only if the faction knows its members
+// (as in FactionRevealed), and only the investigations this player made.
+func (c *StateRequestCommand) Apply(state *domain.GameState) ([]Effect, error) {
+	// Validation: Requesting player exists (dead players may still follow the game)
+	player := state.GetPlayer(c.PlayerID)
+	if player == nil {
+		return nil, fmt.Errorf("player %s not found", c.PlayerID)
+	}
+
+	reply := &events.PlayerState{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypePlayerState,
+		},
+		PlayerID:    player.ID,
+		Name:        player.Name,
+		Role:        player.Role.String(),
+		Faction:     player.Role.Faction().String(),
+		Trait:       player.Trait,
+		Alive:       player.Alive,
+		Players:     rosterEntries(state),
+		PhaseEndsAt: state.PhaseEndsAt,
+	}
+	if c.History != nil {
+		reply.LastSeq = c.History.LastSeq()
+	}
+
+	reply.FactionMates = factionMembers(state, player.ID)
+
+	for _, inv := range state.GetInvestigations(player.ID) {
+		faction := domain.FactionVillage
+		if inv.IsMafia {
+			faction = domain.FactionMafia
+		}
+		reply.Results = append(reply.Results, events.InvestigationRecord{
+			Round:    inv.Round,
+			TargetID: inv.TargetID,
+			Faction:  faction.String(),
+		})
+	}
+
+	return []Effect{NewPublishEffect(reply)}, nil
+}
+
+// factionMembers lists the player's faction mates for FactionRevealed and PlayerState.
+// Empty if the player's faction doesn't know its members.
+func factionMembers(state *domain.GameState, playerID string) []events.FactionMember {
+	var members []events.FactionMember
+	for _, mate := range state.FactionMates(playerID) {
+		members = append(members, events.FactionMember{
+			ID:    mate.ID,
+			Name:  mate.Name,
+			Role:  mate.Role.String(),
+			Alive: mate.Alive,
+		})
+	}
+	return members
+}
+
+// newGameEndedEvent builds GameEnded with every player's role and trait revealed.
+func newGameEndedEvent(state *domain.GameState) *events.GameEnded {
+	ended := &events.GameEnded{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypeGameEnded,
+		},
+		Winner: state.Winner.String(),
+	}
+	for _, player := range state.GetSeatedPlayers() {
+		ended.Players = append(ended.Players, events.PlayerReveal{
+			ID:     player.ID,
+			Name:   player.Name,
+			Seat:   player.Seat,
+			Role:   player.Role.String(),
+			Trait:  player.Trait,
+			Alive:  player.Alive,
+			Pinned: player.RolePinned,
+		})
+	}
+	for _, night := range state.Nights {
+		reveal := events.NightReveal{
+			Round:        night.Round,
+			MafiaTarget:  night.MafiaTarget,
+			DoctorTarget: night.DoctorTarget,
+			Killed:       night.Killed,
+		}
+		for _, inv := range state.Investigations {
+			if inv.Round == night.Round {
+				reveal.SheriffTarget = inv.TargetID
+			}
+		}
+		ended.Nights = append(ended.Nights, reveal)
+	}
+	return ended
+}
+
+// newVoteResultEvent lists the ballots of the closing voting phase.
+// Must be called before ResetPhaseData clears the votes.
+func newVoteResultEvent(state *domain.GameState, eliminated string) *events.VoteResult {
+	result := &events.VoteResult{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypeVoteResult,
+		},
+		Votes:      []events.Ballot{},
+		Eliminated: eliminated,
+	}
+	for _, player := range state.GetSeatedPlayers() {
+		if target, voted := state.Votes[player.ID]; voted {
+			result.Votes = append(result.Votes, events.Ballot{VoterID: player.ID, TargetID: target})
+		}
+	}
+	return result
+}
+
+// viewerFor returns the visibility viewer for a player in this game.
+func viewerFor(player *domain.Player) events.Viewer {
+	return events.Viewer{PlayerID: player.ID, Faction: player.Role.Faction().String()}
+}
+
+// buildSnapshot returns the public view of the current game state.
+func buildSnapshot(state *domain.GameState) *events.GameSnapshot {
+	return &events.GameSnapshot{
+		Round:   state.Round,
+		Phase:   state.Phase.String(),
+		Winner:  state.Winner.String(),
+		Players: rosterEntries(state),
+	}
+}
+
+// rosterEntries returns the public roster (no roles) in seat order.
+func rosterEntries(state *domain.GameState) []events.RosterEntry {
+	seated := state.GetSeatedPlayers()
+	entries := make([]events.RosterEntry, 0, len(seated))
+	for _, player := range seated {
+		entries = append(entries, events.RosterEntry{
+			ID:      player.ID,
+			Name:    player.Name,
+			Seat:    player.Seat,
+			Alive:   player.Alive,
+			Persona: player.Persona,
+		})
+	}
+	return entries
+}
+
+// newRosterEvent builds a Roster event from the current state.
+func newRosterEvent(state *domain.GameState) *events.Roster {
+	return &events.Roster{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypeRoster,
+		},
+		NamePack: state.NamePack,
+		Players:  rosterEntries(state),
+	}
+}
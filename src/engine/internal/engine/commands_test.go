@@ -1,10 +1,13 @@
 package engine
 
 import (
+	"reflect"
 	"strings"
 	"testing"
 
 	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
 )
 
 func TestAddPlayerCommand_Success(t *testing.T) {
@@ -46,6 +49,57 @@ func TestAddPlayerCommand_WrongPhase(t *testing.T) {
 	}
 }
 
+func TestDeclareModelCommand(t *testing.T) {
+	state := &domain.GameState{
+		Phase:   domain.PhaseWaiting,
+		Players: make(map[string]*domain.Player),
+	}
+	player, _ := domain.NewPlayer("player-1", "Alice", domain.RoleUnknown)
+	state.AddPlayer(player)
+
+	declare := func(id, profile string) error {
+		effects, err := (&DeclareModelCommand{PlayerID: id, ModelProfile: profile}).Apply(state)
+		if len(effects) != 0 {
+			t.Errorf("expected no effects, got %d", len(effects))
+		}
+		return err
+	}
+
+	if err := declare("player-1", " llama3.2:1b "); err != nil || player.ModelProfile != "llama3.2:1b" {
+		t.Fatalf("declare failed: %v, profile %q", err, player.ModelProfile)
+	}
+	if err := declare("player-1", "llama3.2:3b"); err != nil || player.ModelProfile != "llama3.2:3b" {
+		t.Fatalf("players may switch before the game starts: %v", err)
+	}
+
+	state.Phase = domain.PhaseNight
+	if err := declare("player-1", "llama3.2:3b"); err != nil {
+		t.Errorf("re-announcing the same profile should work: %v", err)
+	}
+	for _, tt := range []struct{ id, profile string }{
+		{"player-1", "llama3.2:1b"}, // switching mid-game
+		{"player-1", " "},
+		{"player-9", "llama3.2:1b"},
+	} {
+		if err := declare(tt.id, tt.profile); err == nil {
+			t.Errorf("expected error declaring %q for %s", tt.profile, tt.id)
+		}
+	}
+	if player.ModelProfile != "llama3.2:3b" {
+		t.Errorf("rejected declarations must not change the profile, got %q", player.ModelProfile)
+	}
+
+	// a reserved seat only takes its profile, even before the game starts
+	state.Phase = domain.PhaseWaiting
+	player.ProfilePinned = true
+	if err := declare("player-1", "llama3.2:1b"); err == nil || player.ModelProfile != "llama3.2:3b" {
+		t.Errorf("expected the reserved seat to refuse another profile, got %v, %q", err, player.ModelProfile)
+	}
+	if err := declare("player-1", "llama3.2:3b"); err != nil {
+		t.Errorf("the reserved profile should join: %v", err)
+	}
+}
+
 func TestStartGameCommand_Success(t *testing.T) {
 	state := &domain.GameState{
 		ID:      "test-game",
@@ -71,8 +125,169 @@ func TestStartGameCommand_Success(t *testing.T) {
 	if state.Round != 1 {
 		t.Errorf("expected round 1, got %d", state.Round)
 	}
-	if len(effects) != 8 {
-		t.Errorf("expected 8 effects, got %d", len(effects))
+	// GameStarted + Roster + 6 RoleAssigned + 2 FactionRevealed (mafia) + PhaseChanged
+	if len(effects) != 11 {
+		t.Errorf("expected 11 effects, got %d", len(effects))
+	}
+
+	// Every mafia member learns the other mafia member
+	reveals := 0
+	for _, effect := range effects {
+		reveal, ok := effect.(*PublishEffect).Event.(*events.FactionRevealed)
+		if !ok {
+			continue
+		}
+		reveals++
+		if state.GetPlayer(reveal.PlayerID).Role != domain.RoleMafia {
+			t.Errorf("FactionRevealed sent to non-mafia player %s", reveal.PlayerID)
+		}
+		if len(reveal.Members) != 1 || reveal.Members[0].ID == reveal.PlayerID {
+			t.Errorf("unexpected members for %s: %+v", reveal.PlayerID, reveal.Members)
+		}
+	}
+	if reveals != 2 {
+		t.Errorf("expected 2 FactionRevealed events, got %d", reveals)
+	}
+}
+
+func TestStartGameCommand_DeliversTraitsWithRoles(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Seed:    1,
+		Phase:   domain.PhaseWaiting,
+		Players: make(map[string]*domain.Player),
+	}
+	for i := 0; i < 6; i++ {
+		player, _ := domain.NewPlayer(domain.CreatePlayerID(), "TestPlayer", domain.RoleUnknown)
+		state.AddPlayer(player)
+	}
+
+	traits := []domain.Trait{{Name: "timid", Description: "quiet"}}
+	effects, err := (&StartGameCommand{MinPlayers: 6, MaxPlayers: 12, Traits: traits}).Apply(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	for _, effect := range effects {
+		switch ev := effect.(*PublishEffect).Event.(type) {
+		case *events.RoleAssigned:
+			if ev.Trait != "timid" || ev.TraitDescription != "quiet" {
+				t.Errorf("%s: trait not delivered: %+v", ev.PlayerID, ev)
+			}
+		case *events.Roster:
+			// traits are private - the public roster must not leak them
+			for _, entry := range ev.Players {
+				if len(entry.Persona) != 0 {
+					t.Errorf("unexpected persona in roster: %+v", entry)
+				}
+			}
+		}
+	}
+}
+
+func TestStartGameCommand_UsesRulesetDistribution(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Seed:    1,
+		Phase:   domain.PhaseWaiting,
+		Players: make(map[string]*domain.Player),
+	}
+	for i := 0; i < 6; i++ {
+		player, _ := domain.NewPlayer(domain.CreatePlayerID(), "TestPlayer", domain.RoleUnknown)
+		state.AddPlayer(player)
+	}
+
+	ruleset := &domain.Ruleset{
+		Name:          "lean",
+		Distributions: map[int]domain.RoleCounts{6: {Mafia: 1, Doctor: 1}},
+	}
+	if _, err := (&StartGameCommand{MinPlayers: 6, MaxPlayers: 12, Ruleset: ruleset}).Apply(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if got := len(state.GetPlayersWithRole(domain.RoleMafia)); got != 1 {
+		t.Errorf("expected 1 mafia, got %d", got)
+	}
+	if got := len(state.GetPlayersWithRole(domain.RoleSheriff)); got != 0 {
+		t.Errorf("expected no sheriff, got %d", got)
+	}
+	if got := len(state.GetPlayersWithRole(domain.RoleVillager)); got != 4 {
+		t.Errorf("expected 4 villagers, got %d", got)
+	}
+}
+
+func TestStartGameCommand_PinnedRoles(t *testing.T) {
+	newState := func() *domain.GameState {
+		state := &domain.GameState{
+			ID:      "test-game",
+			Seed:    1,
+			Phase:   domain.PhaseWaiting,
+			Players: make(map[string]*domain.Player),
+		}
+		for _, id := range []string{"a", "b", "c", "d", "e", "f"} {
+			player, _ := domain.NewPlayer(id, "TestPlayer", domain.RoleUnknown)
+			state.AddPlayer(player)
+		}
+		return state
+	}
+	pinned := map[int]domain.Role{1: domain.RoleSheriff, 4: domain.RoleMafia}
+
+	state := newState()
+	effects, err := (&StartGameCommand{MinPlayers: 6, MaxPlayers: 12, PinnedRoles: pinned}).Apply(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.Players["a"].Role != domain.RoleSheriff || state.Players["d"].Role != domain.RoleMafia {
+		t.Errorf("pins not applied: a=%s d=%s", state.Players["a"].Role, state.Players["d"].Role)
+	}
+	started := effects[0].(*PublishEffect).Event.(*events.GameStarted)
+	if !started.Pinned {
+		t.Error("expected GameStarted to be marked pinned")
+	}
+
+	ended := newGameEndedEvent(state)
+	for _, reveal := range ended.Players {
+		if _, isPinned := pinned[reveal.Seat]; reveal.Pinned != isPinned {
+			t.Errorf("seat %d: reveal pinned = %v", reveal.Seat, reveal.Pinned)
+		}
+	}
+
+	// pins must fit the distribution (1 sheriff for 6)
+	state = newState()
+	tooMany := map[int]domain.Role{1: domain.RoleSheriff, 2: domain.RoleSheriff}
+	if _, err := (&StartGameCommand{MinPlayers: 6, MaxPlayers: 12, PinnedRoles: tooMany}).Apply(state); err == nil {
+		t.Error("expected error")
+	}
+	if state.Phase != domain.PhaseWaiting {
+		t.Error("game started anyway")
+	}
+}
+
+func TestPhaseChangeCommand_RecordsNight(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Phase:   domain.PhaseNight,
+		Round:   1,
+		Players: make(map[string]*domain.Player),
+		Votes:   make(map[string]string),
+	}
+	roles := []domain.Role{domain.RoleMafia, domain.RoleDoctor, domain.RoleVillager, domain.RoleVillager, domain.RoleVillager}
+	for i, role := range roles {
+		id := "p" + string(rune('1'+i))
+		state.AddPlayer(&domain.Player{ID: id, Name: id, Role: role, Alive: true})
+	}
+	state.SetNightAction(domain.RoleMafia, "p1", "p3")
+	state.SetNightAction(domain.RoleDoctor, "p2", "p3")
+
+	if _, err := (&PhaseChangeCommand{NewPhase: domain.PhaseDay}).Apply(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	if len(state.Nights) != 1 || !state.Nights[0].Saved() {
+		t.Errorf("expected one recorded save, got %+v", state.Nights)
+	}
+	if !state.Players["p3"].Alive {
+		t.Error("saved player should be alive")
 	}
 }
 
@@ -94,10 +309,288 @@ func TestVoteCommand_Success(t *testing.T) {
 	if err != nil {
 		t.Fatalf("unexpected error: %v", err)
 	}
-	if len(effects) != 0 {
-		t.Errorf("expected 0 effects, got %d", len(effects))
+	// only who is left to vote is published
+	if len(effects) != 1 {
+		t.Fatalf("expected 1 effect, got %d", len(effects))
+	}
+	status, ok := effects[0].(*PublishEffect).Event.(*events.ActionStatus)
+	if !ok || status.Waiting != 1 || !reflect.DeepEqual(status.Players, []string{"p2"}) {
+		t.Errorf("unexpected status %+v", effects[0].(*PublishEffect).Event)
 	}
 	if state.Votes["p1"] != "p2" {
 		t.Error("vote not registered")
 	}
 }
+
+func TestResyncCommand_FiltersByVisibility(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Round:   1,
+		Phase:   domain.PhaseNight,
+		Players: make(map[string]*domain.Player),
+	}
+	villager, _ := domain.NewPlayer("v1", "Alice", domain.RoleVillager)
+	mafia, _ := domain.NewPlayer("m1", "Bob", domain.RoleMafia)
+	state.AddPlayer(villager)
+	state.AddPlayer(mafia)
+
+	history := NewEventLog(10)
+	history.Stamp(&events.RoleAssigned{BaseEvent: events.BaseEvent{Type: events.TypeRoleAssigned}, PlayerID: "v1", Role: "villager"}, state)
+	history.Stamp(&events.RoleAssigned{BaseEvent: events.BaseEvent{Type: events.TypeRoleAssigned}, PlayerID: "m1", Role: "mafia"}, state)
+	history.Stamp(&events.MafiaChatMessage{BaseEvent: events.BaseEvent{Type: events.TypeMafiaChatMessage}, SenderID: "m1"}, state)
+	history.Stamp(&events.AllChatMessage{BaseEvent: events.BaseEvent{Type: events.TypeAllChatMessage}, SenderID: "m1"}, state)
+
+	cmd := &ResyncCommand{PlayerID: "v1", LastSeq: 0, History: history}
+	effects, err := cmd.Apply(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if len(effects) != 1 {
+		t.Fatalf("expected 1 effect, got %d", len(effects))
+	}
+
+	reply := effects[0].(*PublishEffect).Event.(*events.Resync)
+	// own role + public chat only
+	if len(reply.Events) != 2 {
+		t.Errorf("expected 2 visible events, got %d", len(reply.Events))
+	}
+	if reply.FromSeq != 1 || reply.ToSeq != 4 {
+		t.Errorf("unexpected range %d..%d", reply.FromSeq, reply.ToSeq)
+	}
+	if reply.Snapshot != nil {
+		t.Error("snapshot should not be sent when history covers the gap")
+	}
+}
+
+func TestResyncCommand_SnapshotFallback(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Round:   3,
+		Phase:   domain.PhaseDay,
+		Players: make(map[string]*domain.Player),
+	}
+	p1, _ := domain.NewPlayer("p1", "Alice", domain.RoleVillager)
+	state.AddPlayer(p1)
+
+	history := NewEventLog(1)
+	history.Stamp(&events.AllChatMessage{BaseEvent: events.BaseEvent{Type: events.TypeAllChatMessage}}, state)
+	history.Stamp(&events.AllChatMessage{BaseEvent: events.BaseEvent{Type: events.TypeAllChatMessage}}, state)
+
+	cmd := &ResyncCommand{PlayerID: "p1", LastSeq: 0, History: history}
+	effects, err := cmd.Apply(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	reply := effects[0].(*PublishEffect).Event.(*events.Resync)
+	if reply.Snapshot == nil {
+		t.Fatal("expected snapshot fallback")
+	}
+	if reply.Snapshot.Round != 3 || len(reply.Snapshot.Players) != 1 {
+		t.Errorf("unexpected snapshot: %+v", reply.Snapshot)
+	}
+}
+
+func TestResyncCommand_UnknownPlayer(t *testing.T) {
+	state := &domain.GameState{Players: make(map[string]*domain.Player)}
+
+	cmd := &ResyncCommand{PlayerID: "ghost", History: NewEventLog(1)}
+	if _, err := cmd.Apply(state); err == nil {
+		t.Fatal("expected error for unknown player")
+	}
+}
+
+func TestStateRequestCommand_OwnKnowledgeOnly(t *testing.T) {
+	state := &domain.GameState{
+		ID:          "test-game",
+		Round:       2,
+		Phase:       domain.PhaseDay,
+		Players:     make(map[string]*domain.Player),
+		PhaseEndsAt: 1234,
+		Investigations: []domain.Investigation{
+			{Round: 1, SheriffID: "s1", TargetID: "m2", IsMafia: true},
+		},
+	}
+	m1, _ := domain.NewPlayer("m1", "Alice", domain.RoleMafia)
+	m2, _ := domain.NewPlayer("m2", "Bob", domain.RoleMafia)
+	s1, _ := domain.NewPlayer("s1", "Carol", domain.RoleSheriff)
+	v1, _ := domain.NewPlayer("v1", "Dave", domain.RoleVillager)
+	for _, p := range []*domain.Player{m1, m2, s1, v1} {
+		state.AddPlayer(p)
+	}
+
+	tests := []struct {
+		playerID    string
+		wantMates   int
+		wantResults int
+	}{
+		{"m1", 1, 0},
+		{"s1", 0, 1},
+		{"v1", 0, 0},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.playerID, func(t *testing.T) {
+			cmd := &StateRequestCommand{PlayerID: tt.playerID, History: NewEventLog(1)}
+			effects, err := cmd.Apply(state)
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+
+			reply := effects[0].(*PublishEffect).Event.(*events.PlayerState)
+			if reply.PlayerID != tt.playerID {
+				t.Errorf("reply addressed to %s", reply.PlayerID)
+			}
+			if len(reply.FactionMates) != tt.wantMates {
+				t.Errorf("faction mates: got %d, want %d", len(reply.FactionMates), tt.wantMates)
+			}
+			if len(reply.Results) != tt.wantResults {
+				t.Errorf("results: got %d, want %d", len(reply.Results), tt.wantResults)
+			}
+			if len(reply.Players) != 4 || reply.PhaseEndsAt != 1234 {
+				t.Errorf("unexpected public part: players=%d endsAt=%d", len(reply.Players), reply.PhaseEndsAt)
+			}
+		})
+	}
+}
+
+func TestPhaseChangeCommand_EmitsInvestigationResult(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Round:   1,
+		Phase:   domain.PhaseNight,
+		Players: make(map[string]*domain.Player),
+		Votes:   make(map[string]string),
+	}
+	s1, _ := domain.NewPlayer("s1", "Carol", domain.RoleSheriff)
+	m1, _ := domain.NewPlayer("m1", "Alice", domain.RoleMafia)
+	v1, _ := domain.NewPlayer("v1", "Dave", domain.RoleVillager)
+	v2, _ := domain.NewPlayer("v2", "Erin", domain.RoleVillager)
+	for _, p := range []*domain.Player{s1, m1, v1, v2} {
+		state.AddPlayer(p)
+	}
+	state.SetNightAction(domain.RoleSheriff, "s1", "m1")
+
+	effects, err := (&PhaseChangeCommand{NewPhase: domain.PhaseDay}).Apply(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var result *events.InvestigationResult
+	for _, effect := range effects {
+		if ev, ok := effect.(*PublishEffect).Event.(*events.InvestigationResult); ok {
+			result = ev
+		}
+	}
+	if result == nil {
+		t.Fatal("expected InvestigationResult effect")
+	}
+	if result.PlayerID != "s1" || result.TargetID != "m1" || result.Faction != "mafia" {
+		t.Errorf("unexpected result: %+v", result)
+	}
+}
+
+func TestPhaseChangeCommand_EmitsVoteResult(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Round:   1,
+		Phase:   domain.PhaseVoting,
+		Players: make(map[string]*domain.Player),
+		Votes:   make(map[string]string),
+	}
+	for _, name := range []string{"Alice", "Bob", "Carol", "Dave", "Erin"} {
+		player, _ := domain.NewPlayer(strings.ToLower(name), name, domain.RoleVillager)
+		state.AddPlayer(player)
+	}
+	state.GetPlayer("alice").Role = domain.RoleMafia
+	state.RegisterVote("carol", "alice")
+	state.RegisterVote("alice", "bob")
+	state.RegisterVote("bob", "alice")
+
+	effects, err := (&PhaseChangeCommand{NewPhase: domain.PhaseNight}).Apply(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	publish := effects[0].(*PublishEffect)
+	result, ok := publish.Event.(*events.VoteResult)
+	if !ok {
+		t.Fatalf("expected VoteResult before PhaseChanged, got %T", publish.Event)
+	}
+	if publish.Topic != kafka.ObserverEventsTopic {
+		t.Errorf("ballots must only go to observers, got topic %q", publish.Topic)
+	}
+	want := []events.Ballot{{VoterID: "alice", TargetID: "bob"}, {VoterID: "bob", TargetID: "alice"}, {VoterID: "carol", TargetID: "alice"}}
+	if !reflect.DeepEqual(result.Votes, want) {
+		t.Errorf("expected ballots in seat order %v, got %v", want, result.Votes)
+	}
+	if result.Eliminated != "alice" {
+		t.Errorf("expected alice eliminated, got %q", result.Eliminated)
+	}
+}
+
+func TestGameEnded_RevealsNights(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Round:   1,
+		Phase:   domain.PhaseNight,
+		Players: make(map[string]*domain.Player),
+		Votes:   make(map[string]string),
+	}
+	roles := []domain.Role{domain.RoleMafia, domain.RoleDoctor, domain.RoleSheriff, domain.RoleVillager}
+	for i, role := range roles {
+		id := "p" + string(rune('1'+i))
+		state.AddPlayer(&domain.Player{ID: id, Name: id, Role: role, Alive: true})
+	}
+	state.SetNightAction(domain.RoleMafia, "p1", "p4")
+	state.SetNightAction(domain.RoleDoctor, "p2", "p2")
+	state.SetNightAction(domain.RoleSheriff, "p3", "p1")
+	if _, err := (&PhaseChangeCommand{NewPhase: domain.PhaseDay}).Apply(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	ended := newGameEndedEvent(state)
+	want := []events.NightReveal{{Round: 1, MafiaTarget: "p4", DoctorTarget: "p2", SheriffTarget: "p1", Killed: "p4"}}
+	if !reflect.DeepEqual(ended.Nights, want) {
+		t.Errorf("expected %v, got %v", want, ended.Nights)
+	}
+}
+
+func TestEliminatePlayerCommand_EmitsRoster(t *testing.T) {
+	state := &domain.GameState{
+		ID:      "test-game",
+		Phase:   domain.PhaseDay,
+		Players: make(map[string]*domain.Player),
+	}
+	for _, name := range []string{"Alice", "Bob", "Carol", "Dave"} {
+		player, _ := domain.NewPlayer(strings.ToLower(name), name, domain.RoleVillager)
+		state.AddPlayer(player)
+	}
+	state.GetPlayer("alice").Role = domain.RoleMafia
+
+	effects, err := (&EliminatePlayerCommand{PlayerID: "bob", Reason: "voted_out"}).Apply(state)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	var roster *events.Roster
+	for _, effect := range effects {
+		if ev, ok := effect.(*PublishEffect).Event.(*events.Roster); ok {
+			roster = ev
+		}
+	}
+	if roster == nil {
+		t.Fatal("expected Roster after elimination")
+	}
+
+	// seat order follows join order, names reach the players
+	wantNames := []string{"Alice", "Bob", "Carol", "Dave"}
+	for i, entry := range roster.Players {
+		if entry.Seat != i+1 || entry.Name != wantNames[i] {
+			t.Errorf("seat %d: got %+v", i+1, entry)
+		}
+		if entry.Alive != (entry.ID != "bob") {
+			t.Errorf("%s alive=%v after bob's elimination", entry.ID, entry.Alive)
+		}
+	}
+}
@@ -15,6 +15,9 @@ type PublishEffect struct {
 	// Event is the event struct to publish (must have BaseEvent embedded)
 	Event any
 
+	// Topic to publish to; empty means kafka.EngineEventsTopic
+	Topic string
+
 	// Timestamp is set by the engine when creating the effect
 	// Commands must NOT set this - engine provides deterministic timestamps
 	Timestamp int64
@@ -41,9 +44,14 @@ func (e *PublishEffect) Execute(ctx context.Context, producer kafka.Producer) er
 		return fmt.Errorf("failed to extract game ID: %w", err)
 	}
 
+	topic := e.Topic
+	if topic == "" {
+		topic = kafka.EngineEventsTopic
+	}
+
 	// Create Kafka message
 	msg := kafka.Message{
-		Topic: kafka.EngineEventsTopic,
+		Topic: topic,
 		Key:   kafka.GameKey(gameID),
 		Value: eventBytes,
 	}
@@ -57,63 +65,22 @@ func (e *PublishEffect) Execute(ctx context.Context, producer kafka.Producer) er
 }
 
 // Helper: Inject timestamp into event's BaseEvent field
-// This uses reflection-like patterns but we can optimize with type switches
 func injectTimestamp(event any, timestamp int64) error {
-	// Type switch on known event types
-	switch e := event.(type) {
-	case *events.AllChatMessage:
-		e.Timestamp = timestamp
-	case *events.MafiaChatMessage:
-		e.Timestamp = timestamp
-	case *events.PhaseChanged:
-		e.Timestamp = timestamp
-	case *events.PlayerEliminated:
-		e.Timestamp = timestamp
-	case *events.GameEnded:
-		e.Timestamp = timestamp
-	case *events.GameStarted:
-		e.Timestamp = timestamp
-	case *events.NightAction:
-		e.Timestamp = timestamp
-	case *events.RoleAssigned:
-		e.Timestamp = timestamp
-	case *events.VoteSubmitted:
-		e.Timestamp = timestamp
-	case *events.PlayerThoughts:
-		e.Timestamp = timestamp
-	default:
+	e, ok := event.(events.Event)
+	if !ok {
 		return fmt.Errorf("unknown event type: %T", event)
 	}
+	e.Header().Timestamp = timestamp
 	return nil
 }
 
 // Helper: Extract GameID from event's BaseEvent field
 func extractGameID(event any) (string, error) {
-	// Type switch to extract GameID
-	switch e := event.(type) {
-	case *events.AllChatMessage:
-		return e.GameID, nil
-	case *events.MafiaChatMessage:
-		return e.GameID, nil
-	case *events.PhaseChanged:
-		return e.GameID, nil
-	case *events.PlayerEliminated:
-		return e.GameID, nil
-	case *events.GameEnded:
-		return e.GameID, nil
-	case *events.GameStarted:
-		return e.GameID, nil
-	case *events.NightAction:
-		return e.GameID, nil
-	case *events.RoleAssigned:
-		return e.GameID, nil
-	case *events.VoteSubmitted:
-		return e.GameID, nil
-	case *events.PlayerThoughts:
-		return e.GameID, nil
-	default:
+	e, ok := event.(events.Event)
+	if !ok {
 		return "", fmt.Errorf("unknown event type: %T", event)
 	}
+	return e.Header().GameID, nil
 }
 
 // NewPublishEffect creates a PublishEffect with the current timestamp.
@@ -125,6 +92,15 @@ func NewPublishEffect(event any) *PublishEffect {
 	}
 }
 
+// NewObserverEffect creates a PublishEffect for kafka.ObserverEventsTopic.
+// Observer events get round and phase but no seq: players never see them,
+// so they must not open gaps in the players' sequence.
+func NewObserverEffect(event any) *PublishEffect {
+	effect := NewPublishEffect(event)
+	effect.Topic = kafka.ObserverEventsTopic
+	return effect
+}
+
 // TimerEffect schedules a command to execute after a delay.
 // Example: "After 5 minutes, advance to next phase"
 // NOTE: This requires access to cmdCh, which is passed during effect creation.
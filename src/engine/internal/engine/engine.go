@@ -3,13 +3,18 @@ package engine
 import (
 	"context"
 	"errors"
+	"fmt"
+	"os"
 	"sync"
+	"time"
 
+	"mafia-engine/internal/clock"
 	"mafia-engine/internal/config"
 	"mafia-engine/internal/domain"
 	"mafia-engine/internal/events"
 	"mafia-engine/internal/kafka"
 	"mafia-engine/internal/names"
+	"mafia-engine/internal/telemetry"
 )
 
 // Effect represents a side effect that should be executed after state mutation.
@@ -49,12 +54,47 @@ type Engine struct {
 	// nameGen generates player names from configured list.
 	nameGen *names.Generator
 
+	// ids numbers this game's players.
+	ids *domain.PlayerIDs
+
+	// ruleset holds optional rule overrides loaded from ENGINE_RULESET_FILE (nil if none).
+	ruleset *domain.Ruleset
+
+	// traits is the personality trait catalogue dealt at game start.
+	traits []domain.Trait
+
+	// pinnedRoles fixes roles by seat at game start, from ENGINE_PINNED_ROLES (nil if none).
+	pinnedRoles map[int]domain.Role
+
+	// thoughts redacts and limits player thoughts, from ENGINE_THOUGHT_*.
+	thoughts *ThoughtPolicy
+
 	// cmdCh carries internal commands that mutate state.
 	cmdCh chan Command
 
+	// clock provides time for timers, deadlines and event timestamps.
+	clock clock.Clock
+
 	// timers manages phase timeout timers.
 	timers *TimerManager
 
+	// phaseLength is how long the current phase runs, extensions included, and
+	// chatMark the phase's chat count at its last extension (see extendPhase).
+	phaseLength time.Duration
+	chatMark    int
+
+	// history stamps emitted events with seq numbers and retains them for resync.
+	history *EventLog
+
+	// journal records applied commands for replay and forking (nil if disabled).
+	journal *Journal
+
+	// telemetry aggregates player usage and response times (nil if disabled).
+	telemetry *telemetry.Recorder
+
+	// responses times players from the phase opening to their first action.
+	responses responseClock
+
 	// ctx controls engine lifecycle.
 	ctx    context.Context
 	cancel context.CancelFunc
@@ -63,12 +103,38 @@ type Engine struct {
 	wg sync.WaitGroup
 }
 
+// Option customizes an Engine at construction time.
+type Option func(*Engine)
+
+// WithClock runs the engine on the given clock instead of wall-clock time
+// (e.g. a clock.Virtual for simulations).
+func WithClock(c clock.Clock) Option {
+	return func(e *Engine) {
+		e.clock = c
+	}
+}
+
+// WithRuleset plays with the given ruleset instead of ENGINE_RULESET_FILE.
+func WithRuleset(r *domain.Ruleset) Option {
+	return func(e *Engine) {
+		e.ruleset = r
+	}
+}
+
+// WithJournal records the initial state and every applied command in j.
+func WithJournal(j *Journal) Option {
+	return func(e *Engine) {
+		e.journal = j
+	}
+}
+
 // NewEngine constructs an Engine with its dependencies wired.
 // It does not start any goroutines.
 func NewEngine(
 	initialState *domain.GameState,
 	producer kafka.Producer,
 	cfg *config.Config,
+	opts ...Option,
 ) (*Engine, error) {
 
 	if initialState == nil {
@@ -81,24 +147,91 @@ func NewEngine(
 		return nil, errors.New("config must not be nil")
 	}
 
-	// Create name generator from config
-	nameGen, err := names.NewGenerator(cfg.PlayerNames)
+	// Resolve the name pack and make sure it can name a full game
+	pack, err := names.ResolvePack(cfg.NamePack, cfg.NamePackFile, cfg.PlayerNames)
+	if err != nil {
+		return nil, err
+	}
+	if err := pack.CheckCoverage(cfg.GameMaxPlayers); err != nil {
+		return nil, err
+	}
+
+	// Create name generator, shuffled by the game RNG unless disabled
+	var nameGen *names.Generator
+	if cfg.NameShuffle {
+		nameGen, err = names.NewShuffledGenerator(pack.Names, initialState.RNG(domain.RNGStreamNames))
+	} else {
+		nameGen, err = names.NewGenerator(pack.Names)
+	}
 	if err != nil {
 		return nil, err
 	}
+	initialState.NamePack = pack.Name
+
+	// Load optional ruleset file
+	var ruleset *domain.Ruleset
+	if cfg.RulesetFile != "" {
+		ruleset, err = LoadRuleset(cfg.RulesetFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	traits, err := domain.TraitsByName(cfg.PersonalityTraits)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENGINE_PERSONALITY_TRAITS: %w", err)
+	}
+
+	pinnedRoles, err := domain.ParsePinnedRoles(cfg.PinnedRoles)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENGINE_PINNED_ROLES: %w", err)
+	}
+	for seat := range pinnedRoles {
+		if seat > cfg.GameMaxPlayers {
+			return nil, fmt.Errorf("invalid ENGINE_PINNED_ROLES: seat %d is beyond ENGINE_GAME_MAX_PLAYERS (%d)", seat, cfg.GameMaxPlayers)
+		}
+	}
+	if len(pinnedRoles) == 0 {
+		pinnedRoles = nil
+	}
+
+	thoughts, err := NewThoughtPolicy(cfg.ThoughtMaxBytes, cfg.ThoughtRedact)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ENGINE_THOUGHT_REDACT: %w", err)
+	}
 
 	ctx, cancel := context.WithCancel(context.Background())
 
-	return &Engine{
-		state:    initialState,
-		producer: producer,
-		cfg:      cfg,
-		nameGen:  nameGen,
-		cmdCh:    make(chan Command, 64),
-		timers:   NewTimerManager(),
-		ctx:      ctx,
-		cancel:   cancel,
-	}, nil
+	e := &Engine{
+		state:       initialState,
+		producer:    producer,
+		cfg:         cfg,
+		nameGen:     nameGen,
+		ids:         domain.NewPlayerIDs(len(initialState.Players)),
+		ruleset:     ruleset,
+		traits:      traits,
+		pinnedRoles: pinnedRoles,
+		thoughts:    thoughts,
+		clock:       clock.Real{},
+		cmdCh:       make(chan Command, 64),
+		history:     NewEventLog(cfg.EventHistorySize),
+		ctx:         ctx,
+		cancel:      cancel,
+	}
+	for _, opt := range opts {
+		opt(e)
+	}
+	e.timers = NewTimerManagerWithClock(e.clock)
+	if e.journal != nil {
+		e.journal.begin(initialState)
+	}
+
+	// Ruleset traits take precedence over the configured trait names
+	if e.ruleset != nil && len(e.ruleset.Traits) > 0 {
+		e.traits = e.ruleset.Traits
+	}
+
+	return e, nil
 }
 
 // Start launches the engine loop.
@@ -118,10 +251,27 @@ func (e *Engine) Stop() {
 	e.wg.Wait()
 }
 
+// ProcessPending runs every queued command on the caller's goroutine and
+// returns how many were processed. It lets a single-threaded driver (e.g. a
+// simulation on a virtual clock) step the engine deterministically instead of
+// calling Start; don't mix the two.
+func (e *Engine) ProcessPending() int {
+	processed := 0
+	for {
+		select {
+		case cmd := <-e.cmdCh:
+			e.process(cmd)
+			processed++
+		default:
+			return processed
+		}
+	}
+}
+
 // CreatePlayer creates a new player with auto-generated ID and name.
 // Returns the created player or an error if name generation fails.
 func (e *Engine) CreatePlayer() (*domain.Player, error) {
-	id := domain.CreatePlayerID()
+	id := e.ids.Next()
 	name, err := e.nameGen.Next()
 	if err != nil {
 		return nil, err
@@ -133,10 +283,33 @@ func (e *Engine) CreatePlayer() (*domain.Player, error) {
 // Creates a player with auto-generated ID and name, then sends AddPlayerCommand.
 // Returns error if name generation fails or command validation fails.
 func (e *Engine) AddPlayer() error {
+	return e.AddPlayerWithProfile("")
+}
+
+// AddPlayerWithProfile adds a player played by the given model profile
+// (see domain.Player.ModelProfile).
+func (e *Engine) AddPlayerWithProfile(modelProfile string) error {
+	return e.addPlayer(modelProfile, false)
+}
+
+// AddPlayerWithPinnedProfile adds a player whose seat is reserved for the
+// given model profile (e.g. from a tournament pairing): agents that join the
+// seat declaring another profile are refused (see DeclareModelCommand).
+func (e *Engine) AddPlayerWithPinnedProfile(modelProfile string) error {
+	if modelProfile == "" {
+		return errors.New("pinned model profile must not be empty")
+	}
+	return e.addPlayer(modelProfile, true)
+}
+
+// addPlayer sends an AddPlayerCommand for a new player.
+func (e *Engine) addPlayer(modelProfile string, pinned bool) error {
 	player, err := e.CreatePlayer()
 	if err != nil {
 		return err
 	}
+	player.ModelProfile = modelProfile
+	player.ProfilePinned = pinned
 
 	cmd := &AddPlayerCommand{
 		Player:     player,
@@ -153,13 +326,35 @@ func (e *Engine) AddPlayer() error {
 }
 
 // StartGame sends a StartGameCommand to the engine.
-// It uses min/max players from the configuration.
+// It uses min/max players, the trait catalogue, the ruleset and the pinned roles
+// from the configuration.
 func (e *Engine) StartGame() error {
+	return e.StartGameWithPinnedRoles(e.pinnedRoles)
+}
+
+// StartGameWithPinnedRoles starts the game with the given roles pinned by seat
+// instead of the configured ones. The pins must fit the ruleset's distribution
+// for the player count, otherwise the game does not start.
+func (e *Engine) StartGameWithPinnedRoles(pinned map[int]domain.Role) error {
 	cmd := &StartGameCommand{
-		MinPlayers: e.cfg.GameMinPlayers,
-		MaxPlayers: e.cfg.GameMaxPlayers,
+		MinPlayers:  e.cfg.GameMinPlayers,
+		MaxPlayers:  e.cfg.GameMaxPlayers,
+		Traits:      e.traits,
+		Ruleset:     e.ruleset,
+		PinnedRoles: pinned,
+	}
+
+	select {
+	case e.cmdCh <- cmd:
+		return nil
+	case <-e.ctx.Done():
+		return e.ctx.Err()
 	}
+}
 
+// Submit sends a command to the engine loop as is, e.g. one replayed from a
+// Journal. Commands the game rejects are dropped, as for any other source.
+func (e *Engine) Submit(cmd Command) error {
 	select {
 	case e.cmdCh <- cmd:
 		return nil
@@ -168,6 +363,15 @@ func (e *Engine) StartGame() error {
 	}
 }
 
+// LoadRuleset reads and validates a JSON ruleset file.
+func LoadRuleset(path string) (*domain.Ruleset, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from trusted configuration
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ruleset %s: %w", path, err)
+	}
+	return domain.ParseRuleset(data)
+}
+
 // HandleMessage is the single external entrypoint into the engine.
 // It deserializes the event and delegates interpretation to handlers.
 func (e *Engine) HandleMessage(ctx context.Context, msg kafka.Message) error {
@@ -0,0 +1,111 @@
+package engine
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/kafka"
+)
+
+// fakeProducer records published messages instead of sending them to Kafka
+type fakeProducer struct {
+	messages []kafka.Message
+}
+
+func (p *fakeProducer) Publish(ctx context.Context, msg kafka.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *fakeProducer) Close() error { return nil }
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	return cfg
+}
+
+func TestNewEngine_NamePack(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.NamePack = "noir"
+	state := domain.NewGameState("test")
+
+	if _, err := NewEngine(state, &fakeProducer{}, cfg); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if state.NamePack != "noir" {
+		t.Errorf("expected state to record pack noir, got %q", state.NamePack)
+	}
+}
+
+func TestNewEngine_NamePackTooSmall(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PlayerNames = []string{"Alice", "Bob"}
+
+	if _, err := NewEngine(domain.NewGameState("test"), &fakeProducer{}, cfg); err == nil {
+		t.Fatal("expected error when name pack can't cover max players")
+	}
+}
+
+func TestNewEngine_PinnedRoles(t *testing.T) {
+	for _, pins := range [][]string{{"1=jester"}, {"99=mafia"}} {
+		cfg := testConfig(t)
+		cfg.PinnedRoles = pins
+		if _, err := NewEngine(domain.NewGameState("test"), &fakeProducer{}, cfg); err == nil {
+			t.Errorf("%v: expected error", pins)
+		}
+	}
+}
+
+func TestEngine_VirtualClockDrivesPhases(t *testing.T) {
+	cfg := testConfig(t)
+	start := time.Unix(1000, 0)
+	virtual := clock.NewVirtual(start)
+	state := domain.NewGameState("test")
+
+	eng, err := NewEngine(state, &fakeProducer{}, cfg, WithClock(virtual))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatalf("AddPlayer failed: %v", err)
+		}
+		eng.ProcessPending()
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatalf("StartGame failed: %v", err)
+	}
+	if n := eng.ProcessPending(); n != 1 {
+		t.Fatalf("expected 1 processed command, got %d", n)
+	}
+
+	if state.Phase != domain.PhaseNight {
+		t.Fatalf("expected night, got %s", state.Phase)
+	}
+	if want := start.Add(cfg.PhaseNightTimeout).UnixMilli(); state.PhaseEndsAt != want {
+		t.Errorf("expected deadline %d from the virtual clock, got %d", want, state.PhaseEndsAt)
+	}
+
+	// nothing happens until the clock moves
+	virtual.Advance(cfg.PhaseNightTimeout - time.Second)
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseNight {
+		t.Fatalf("phase changed early: %s", state.Phase)
+	}
+
+	virtual.Advance(time.Second)
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseDay {
+		t.Errorf("expected day after the night timeout, got %s", state.Phase)
+	}
+}
@@ -20,8 +20,9 @@ func HandleEvent(ctx context.Context, cmdCh chan Command, ev any) error {
 	case *events.VoteSubmitted:
 		// Create command from event data
 		cmd := &VoteCommand{
-			VoterID:  e.VoterID,
-			TargetID: e.TargetID,
+			VoterID:   e.VoterID,
+			TargetID:  e.TargetID,
+			Telemetry: e.Telemetry,
 		}
 
 		// Send to command channel
@@ -36,8 +37,9 @@ func HandleEvent(ctx context.Context, cmdCh chan Command, ev any) error {
 	case *events.AllChatMessage:
 		// Create command from event data
 		cmd := &ChatCommand{
-			SenderID: e.SenderID,
-			Message:  e.Message,
+			SenderID:  e.SenderID,
+			Message:   e.Message,
+			Telemetry: e.Telemetry,
 		}
 
 		// Send to command channel
@@ -51,9 +53,10 @@ func HandleEvent(ctx context.Context, cmdCh chan Command, ev any) error {
 	case *events.NightAction:
 		// Create command from event data
 		cmd := &NightActionCommand{
-			Role:     e.Role,
-			ActorID:  e.ActorID,
-			TargetID: e.TargetID,
+			Role:      e.Role,
+			ActorID:   e.ActorID,
+			TargetID:  e.TargetID,
+			Telemetry: e.Telemetry,
 		}
 
 		// Send to command channel
@@ -67,8 +70,52 @@ func HandleEvent(ctx context.Context, cmdCh chan Command, ev any) error {
 	case *events.MafiaChatMessage:
 		// Create mafia-specific chat command
 		cmd := &MafiaChatCommand{
-			SenderID: e.SenderID,
-			Message:  e.Message,
+			SenderID:  e.SenderID,
+			Message:   e.Message,
+			Telemetry: e.Telemetry,
+		}
+
+		// Send to command channel
+		select {
+		case cmdCh <- cmd:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+	case *events.ResyncRequested:
+		// Event log is attached by the engine loop before Apply
+		cmd := &ResyncCommand{
+			PlayerID: e.PlayerID,
+			LastSeq:  e.LastSeq,
+		}
+
+		// Send to command channel
+		select {
+		case cmdCh <- cmd:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+	case *events.StateRequest:
+		// Event log is attached by the engine loop before Apply
+		cmd := &StateRequestCommand{
+			PlayerID: e.PlayerID,
+		}
+
+		// Send to command channel
+		select {
+		case cmdCh <- cmd:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
+
+	case *events.PlayerJoined:
+		cmd := &DeclareModelCommand{
+			PlayerID:     e.PlayerID,
+			ModelProfile: e.ModelProfile,
 		}
 
 		// Send to command channel
@@ -80,11 +127,20 @@ func HandleEvent(ctx context.Context, cmdCh chan Command, ev any) error {
 		}
 
 	case *events.PlayerThoughts:
-		// Player thoughts don't mutate game state
-		// They're for AI agent reasoning/debugging
-		// We could log them or emit them back to Kafka for observability
-		// For now, we just acknowledge and ignore (no state change needed)
-		return nil
+		// Thoughts never reach players: they are kept with the game and
+		// re-published to observers only
+		cmd := &ThoughtCommand{
+			PlayerID: e.SenderID,
+			Thought:  e.Thought,
+		}
+
+		// Send to command channel
+		select {
+		case cmdCh <- cmd:
+			return nil
+		case <-ctx.Done():
+			return ctx.Err()
+		}
 
 	default:
 		// Unknown event type - this should never happen if Deserialize is correct
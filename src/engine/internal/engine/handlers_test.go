@@ -17,6 +17,7 @@ func TestHandleEvent_VoteSubmitted(t *testing.T) {
 		BaseEvent: events.BaseEvent{GameID: "test", Type: events.TypeVoteSubmitted},
 		VoterID:   "p1",
 		TargetID:  "p2",
+		Telemetry: &events.Telemetry{Model: "gpt-4o", PromptTokens: 10},
 	}
 
 	if err := HandleEvent(ctx, cmdCh, event); err != nil {
@@ -29,7 +30,7 @@ func TestHandleEvent_VoteSubmitted(t *testing.T) {
 		if !ok {
 			t.Fatalf("expected VoteCommand, got %T", cmd)
 		}
-		if voteCmd.VoterID != "p1" || voteCmd.TargetID != "p2" {
+		if voteCmd.VoterID != "p1" || voteCmd.TargetID != "p2" || voteCmd.Telemetry != event.Telemetry {
 			t.Error("wrong command data")
 		}
 	case <-time.After(50 * time.Millisecond):
@@ -86,6 +87,89 @@ func TestHandleEvent_NightAction(t *testing.T) {
 	}
 }
 
+func TestHandleEvent_ResyncRequested(t *testing.T) {
+	cmdCh := make(chan Command, 1)
+	ctx := context.Background()
+
+	event := &events.ResyncRequested{
+		BaseEvent: events.BaseEvent{GameID: "test", Type: events.TypeResyncRequested},
+		PlayerID:  "p1",
+		LastSeq:   7,
+	}
+
+	if err := HandleEvent(ctx, cmdCh, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case cmd := <-cmdCh:
+		resync, ok := cmd.(*ResyncCommand)
+		if !ok {
+			t.Fatalf("expected ResyncCommand, got %T", cmd)
+		}
+		if resync.PlayerID != "p1" || resync.LastSeq != 7 {
+			t.Error("wrong command data")
+		}
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("no command sent")
+	}
+}
+
+func TestHandleEvent_StateRequest(t *testing.T) {
+	cmdCh := make(chan Command, 1)
+	ctx := context.Background()
+
+	event := &events.StateRequest{
+		BaseEvent: events.BaseEvent{GameID: "test", Type: events.TypeStateRequest},
+		PlayerID:  "p1",
+	}
+
+	if err := HandleEvent(ctx, cmdCh, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case cmd := <-cmdCh:
+		req, ok := cmd.(*StateRequestCommand)
+		if !ok {
+			t.Fatalf("expected StateRequestCommand, got %T", cmd)
+		}
+		if req.PlayerID != "p1" {
+			t.Error("wrong command data")
+		}
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("no command sent")
+	}
+}
+
+func TestHandleEvent_PlayerJoined(t *testing.T) {
+	cmdCh := make(chan Command, 1)
+	ctx := context.Background()
+
+	event := &events.PlayerJoined{
+		BaseEvent:    events.BaseEvent{GameID: "test", Type: events.TypePlayerJoined},
+		PlayerID:     "p1",
+		ModelProfile: "llama3.2:3b",
+	}
+
+	if err := HandleEvent(ctx, cmdCh, event); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+
+	select {
+	case cmd := <-cmdCh:
+		declare, ok := cmd.(*DeclareModelCommand)
+		if !ok {
+			t.Fatalf("expected DeclareModelCommand, got %T", cmd)
+		}
+		if declare.PlayerID != "p1" || declare.ModelProfile != "llama3.2:3b" {
+			t.Error("wrong command data")
+		}
+	case <-time.After(50 * time.Millisecond):
+		t.Fatal("no command sent")
+	}
+}
+
 func TestHandleEvent_PlayerThoughts(t *testing.T) {
 	cmdCh := make(chan Command, 1)
 	ctx := context.Background()
@@ -101,10 +185,16 @@ func TestHandleEvent_PlayerThoughts(t *testing.T) {
 	}
 
 	select {
-	case <-cmdCh:
-		t.Fatal("PlayerThoughts should not send command")
-	case <-time.After(20 * time.Millisecond):
-		// Expected: no command
+	case cmd := <-cmdCh:
+		thought, ok := cmd.(*ThoughtCommand)
+		if !ok {
+			t.Fatalf("expected *ThoughtCommand, got %T", cmd)
+		}
+		if thought.PlayerID != "p1" || thought.Thought != "thinking" {
+			t.Errorf("unexpected command %+v", thought)
+		}
+	case <-time.After(100 * time.Millisecond):
+		t.Fatal("timeout waiting for command")
 	}
 }
 
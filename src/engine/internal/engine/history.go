@@ -0,0 +1,87 @@
+package engine
+
+import (
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+)
+
+// EventLog assigns per-game sequence numbers to emitted events and keeps
+// the most recent ones so players can catch up after missing events.
+// It is owned by the engine loop and is not safe for concurrent use.
+type EventLog struct {
+	capacity int
+	lastSeq  int64
+
+	// entries is a ring buffer of the newest events
+	entries []events.Event
+	start   int // index of the oldest entry
+}
+
+// NewEventLog creates a log retaining up to capacity events.
+// A capacity <= 0 keeps no events (every resync falls back to a snapshot).
+func NewEventLog(capacity int) *EventLog {
+	if capacity < 0 {
+		capacity = 0
+	}
+	return &EventLog{
+		capacity: capacity,
+		entries:  make([]events.Event, 0, capacity),
+	}
+}
+
+// Stamp assigns the next seq plus round/phase context from state,
+// records the event, and returns the assigned seq.
+func (l *EventLog) Stamp(event events.Event, state *domain.GameState) int64 {
+	l.lastSeq++
+
+	event.Header().Seq = l.lastSeq
+	stampContext(event, state)
+
+	l.append(event)
+	return l.lastSeq
+}
+
+// stampContext sets the round and phase the event happened in.
+func stampContext(event events.Event, state *domain.GameState) {
+	header := event.Header()
+	header.Round = state.Round
+	header.Phase = state.Phase.String()
+}
+
+// LastSeq returns the seq of the most recently stamped event (0 if none).
+func (l *EventLog) LastSeq() int64 {
+	return l.lastSeq
+}
+
+// Since returns all retained events with seq > afterSeq, oldest first.
+// ok is false when part of that range has already been evicted,
+// in which case the caller should fall back to a snapshot.
+func (l *EventLog) Since(afterSeq int64) (missed []events.Event, ok bool) {
+	if afterSeq >= l.lastSeq {
+		return nil, true
+	}
+
+	oldestSeq := l.lastSeq - int64(len(l.entries)) + 1
+	if afterSeq+1 < oldestSeq {
+		return nil, false
+	}
+
+	skip := int(afterSeq + 1 - oldestSeq)
+	for i := skip; i < len(l.entries); i++ {
+		missed = append(missed, l.entries[(l.start+i)%len(l.entries)])
+	}
+	return missed, true
+}
+
+func (l *EventLog) append(event events.Event) {
+	if l.capacity == 0 {
+		return
+	}
+	if len(l.entries) < l.capacity {
+		l.entries = append(l.entries, event)
+		return
+	}
+	// full: overwrite the oldest entry
+	l.entries[l.start] = event
+	l.start = (l.start + 1) % l.capacity
+}
@@ -0,0 +1,73 @@
+package engine
+
+import (
+	"testing"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+)
+
+func newChat(msg string) *events.AllChatMessage {
+	return &events.AllChatMessage{
+		BaseEvent: events.BaseEvent{GameID: "test", Type: events.TypeAllChatMessage},
+		Message:   msg,
+	}
+}
+
+func TestEventLogStamp(t *testing.T) {
+	state := &domain.GameState{Round: 2, Phase: domain.PhaseDay}
+	log := NewEventLog(4)
+
+	first := newChat("a")
+	second := newChat("b")
+	log.Stamp(first, state)
+	log.Stamp(second, state)
+
+	if first.Seq != 1 || second.Seq != 2 {
+		t.Errorf("expected seq 1,2 got %d,%d", first.Seq, second.Seq)
+	}
+	if second.Round != 2 || second.Phase != "day" {
+		t.Errorf("context not stamped: round=%d phase=%q", second.Round, second.Phase)
+	}
+	if log.LastSeq() != 2 {
+		t.Errorf("expected last seq 2, got %d", log.LastSeq())
+	}
+}
+
+func TestEventLogSince(t *testing.T) {
+	state := &domain.GameState{Round: 1, Phase: domain.PhaseNight}
+	log := NewEventLog(3)
+	for _, msg := range []string{"a", "b", "c", "d", "e"} {
+		log.Stamp(newChat(msg), state)
+	}
+
+	// retained: seq 3,4,5
+	missed, ok := log.Since(3)
+	if !ok {
+		t.Fatal("expected range to be retained")
+	}
+	if len(missed) != 2 || missed[0].Header().Seq != 4 || missed[1].Header().Seq != 5 {
+		t.Errorf("unexpected missed events: %v", missed)
+	}
+
+	if _, ok := log.Since(1); ok {
+		t.Error("expected evicted range to report ok=false")
+	}
+
+	missed, ok = log.Since(5)
+	if !ok || len(missed) != 0 {
+		t.Errorf("expected nothing missed when up to date, got %d (ok=%v)", len(missed), ok)
+	}
+}
+
+func TestEventLogZeroCapacity(t *testing.T) {
+	log := NewEventLog(0)
+	log.Stamp(newChat("a"), &domain.GameState{})
+
+	if log.LastSeq() != 1 {
+		t.Errorf("seq should still advance, got %d", log.LastSeq())
+	}
+	if _, ok := log.Since(0); ok {
+		t.Error("expected snapshot fallback with zero capacity")
+	}
+}
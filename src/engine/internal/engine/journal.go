@@ -0,0 +1,308 @@
+package engine
+
+import (
+	"encoding/json"
+	"fmt"
+	"sync"
+
+	"mafia-engine/internal/domain"
+)
+
+// Journal records the state a game started from and every command the engine
+// applied to it, in order. Commands are deterministic (the game RNG comes from
+// the seed), so replaying the journal rebuilds the game at any command, e.g. to
+// fork it and play out alternative futures (see sim.Branch).
+// Commands that only read state (resync, state requests, phase warnings) are
+// not recorded.
+// A journal marshals to JSON (see JournalEntry), so it can be kept with the
+// archived game and forked later.
+// It is safe for concurrent use: the engine loop records while others read.
+type Journal struct {
+	mu      sync.Mutex
+	initial *domain.GameState
+	entries []journalItem
+}
+
+// journalItem is one recorded command and when the engine accepted it.
+type journalItem struct {
+	command   Command
+	seq       int64
+	timestamp int64
+}
+
+// NewJournal creates an empty journal. Pass it to the engine with WithJournal.
+func NewJournal() *Journal {
+	return &Journal{}
+}
+
+// Len returns the number of recorded commands.
+func (j *Journal) Len() int {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return len(j.entries)
+}
+
+// Initial returns a copy of the state the game started from (nil if the
+// journal was never attached to an engine).
+func (j *Journal) Initial() *domain.GameState {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	if j.initial == nil {
+		return nil
+	}
+	return j.initial.Clone()
+}
+
+// Commands returns copies of the recorded commands, safe to Apply to another state.
+func (j *Journal) Commands() []Command {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	commands := make([]Command, len(j.entries))
+	for i, item := range j.entries {
+		commands[i] = cloneCommand(item.command)
+	}
+	return commands
+}
+
+// Entries returns the recorded commands in their serialized form.
+func (j *Journal) Entries() ([]JournalEntry, error) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+
+	entries := make([]JournalEntry, len(j.entries))
+	for i, item := range j.entries {
+		entry, err := newJournalEntry(item.command)
+		if err != nil {
+			return nil, fmt.Errorf("command %d: %w", i, err)
+		}
+		entry.Seq = item.seq
+		entry.Timestamp = item.timestamp
+		entries[i] = entry
+	}
+	return entries, nil
+}
+
+// StateAt rebuilds the game as it was after the first n commands.
+func (j *Journal) StateAt(n int) (*domain.GameState, error) {
+	state := j.Initial()
+	if state == nil {
+		return nil, fmt.Errorf("journal has no initial state")
+	}
+
+	commands := j.Commands()
+	if n < 0 || n > len(commands) {
+		return nil, fmt.Errorf("command %d out of range (journal has %d)", n, len(commands))
+	}
+	for i, cmd := range commands[:n] {
+		if _, err := cmd.Apply(state); err != nil {
+			return nil, fmt.Errorf("replaying command %d (%T): %w", i, cmd, err)
+		}
+	}
+	return state, nil
+}
+
+// journalJSON is the serialized journal.
+type journalJSON struct {
+	Initial *domain.GameState `json:"initial"`
+	Entries []JournalEntry    `json:"entries"`
+}
+
+// MarshalJSON implements json.Marshaler.
+func (j *Journal) MarshalJSON() ([]byte, error) {
+	entries, err := j.Entries()
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(journalJSON{Initial: j.Initial(), Entries: entries})
+}
+
+// UnmarshalJSON implements json.Unmarshaler.
+func (j *Journal) UnmarshalJSON(data []byte) error {
+	var decoded journalJSON
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		return err
+	}
+	if decoded.Initial == nil {
+		return fmt.Errorf("journal has no initial state")
+	}
+
+	items := make([]journalItem, len(decoded.Entries))
+	for i, entry := range decoded.Entries {
+		cmd, err := entry.Command()
+		if err != nil {
+			return fmt.Errorf("journal entry %d: %w", i, err)
+		}
+		items[i] = journalItem{command: cmd, seq: entry.Seq, timestamp: entry.Timestamp}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.initial = decoded.Initial
+	j.entries = items
+	return nil
+}
+
+// begin records the state the game starts from.
+func (j *Journal) begin(state *domain.GameState) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.initial = state.Clone()
+	j.entries = nil
+}
+
+// record appends a command the engine just applied to state. seq is the last
+// seq published to players before it, timestamp when it was applied (Unix ms).
+func (j *Journal) record(cmd Command, state *domain.GameState, seq, timestamp int64) {
+	switch c := cmd.(type) {
+	case *ResyncCommand, *StateRequestCommand, *PhaseWarningCommand:
+		return // read-only
+	case *ThoughtCommand:
+		// keep the thought as the game kept it: redacted and cut, so the
+		// journal never holds what the policy removed
+		kept := *c
+		kept.Policy = nil
+		if n := len(state.Thoughts); n > 0 {
+			kept.Thought = state.Thoughts[n-1].Text
+		}
+		cmd = &kept
+	case *PhaseChangeCommand:
+		// replayed without the engine's deadlines, so without the timer's guard
+		cmd = &PhaseChangeCommand{NewPhase: c.NewPhase}
+	}
+
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.entries = append(j.entries, journalItem{command: cloneCommand(cmd), seq: seq, timestamp: timestamp})
+}
+
+// cloneCommand copies the parts of a command the game mutates after Apply,
+// so the command can be applied again to a different state.
+func cloneCommand(cmd Command) Command {
+	switch c := cmd.(type) {
+	case *AddPlayerCommand:
+		clone := *c
+		if c.Player != nil {
+			clone.Player = c.Player.Clone()
+		}
+		return &clone
+	default:
+		// every other command only holds values (or read-only config)
+		return cmd
+	}
+}
+
+// Journal entry kinds
+const (
+	EntryAddPlayer       = "add_player"
+	EntryDeclareModel    = "declare_model"
+	EntryStartGame       = "start_game"
+	EntryVote            = "vote"
+	EntryChat            = "chat"
+	EntryMafiaChat       = "mafia_chat"
+	EntryNightAction     = "night_action"
+	EntryThought         = "thought"
+	EntryPhaseChange     = "phase_change"
+	EntryEliminatePlayer = "eliminate_player"
+)
+
+// JournalEntry is a recorded command in serialized form, with when the engine
+// accepted it. Only the fields of its kind are set.
+type JournalEntry struct {
+	Kind string `json:"kind"`
+
+	// Seq is the last seq published to players before the command: the
+	// command came after that event and before the next one.
+	Seq       int64 `json:"seq"`
+	Timestamp int64 `json:"timestamp"` // Unix ms, on the engine's clock
+
+	PlayerID string `json:"player_id,omitempty"` // who acted (the voter, sender, ...)
+	Role     string `json:"role,omitempty"`      // night actions
+	TargetID string `json:"target,omitempty"`    // votes and night actions
+	Text     string `json:"text,omitempty"`      // chat, thoughts (as kept), model profile, reason
+	Phase    string `json:"phase,omitempty"`     // phase changes
+
+	// add_player and start_game
+	Player      *domain.Player      `json:"player,omitempty"`
+	MinPlayers  int                 `json:"min_players,omitempty"`
+	MaxPlayers  int                 `json:"max_players,omitempty"`
+	Traits      []domain.Trait      `json:"traits,omitempty"`
+	Ruleset     *domain.Ruleset     `json:"ruleset,omitempty"`
+	PinnedRoles map[int]domain.Role `json:"pinned_roles,omitempty"`
+}
+
+// newJournalEntry serializes a command. Agent telemetry is not kept.
+func newJournalEntry(cmd Command) (JournalEntry, error) {
+	switch c := cmd.(type) {
+	case *AddPlayerCommand:
+		return JournalEntry{Kind: EntryAddPlayer, Player: c.Player.Clone(), MaxPlayers: c.MaxPlayers}, nil
+	case *DeclareModelCommand:
+		return JournalEntry{Kind: EntryDeclareModel, PlayerID: c.PlayerID, Text: c.ModelProfile}, nil
+	case *StartGameCommand:
+		return JournalEntry{
+			Kind:        EntryStartGame,
+			MinPlayers:  c.MinPlayers,
+			MaxPlayers:  c.MaxPlayers,
+			Traits:      c.Traits,
+			Ruleset:     c.Ruleset,
+			PinnedRoles: c.PinnedRoles,
+		}, nil
+	case *VoteCommand:
+		return JournalEntry{Kind: EntryVote, PlayerID: c.VoterID, TargetID: c.TargetID}, nil
+	case *ChatCommand:
+		return JournalEntry{Kind: EntryChat, PlayerID: c.SenderID, Text: c.Message}, nil
+	case *MafiaChatCommand:
+		return JournalEntry{Kind: EntryMafiaChat, PlayerID: c.SenderID, Text: c.Message}, nil
+	case *NightActionCommand:
+		return JournalEntry{Kind: EntryNightAction, PlayerID: c.ActorID, Role: c.Role, TargetID: c.TargetID}, nil
+	case *ThoughtCommand:
+		return JournalEntry{Kind: EntryThought, PlayerID: c.PlayerID, Text: c.Thought}, nil
+	case *PhaseChangeCommand:
+		return JournalEntry{Kind: EntryPhaseChange, Phase: c.NewPhase.String()}, nil
+	case *EliminatePlayerCommand:
+		return JournalEntry{Kind: EntryEliminatePlayer, PlayerID: c.PlayerID, Text: c.Reason}, nil
+	default:
+		return JournalEntry{}, fmt.Errorf("command %T can't be serialized", cmd)
+	}
+}
+
+// Command rebuilds the recorded command.
+func (e JournalEntry) Command() (Command, error) {
+	switch e.Kind {
+	case EntryAddPlayer:
+		if e.Player == nil {
+			return nil, fmt.Errorf("%s entry without player", e.Kind)
+		}
+		return &AddPlayerCommand{Player: e.Player.Clone(), MaxPlayers: e.MaxPlayers}, nil
+	case EntryDeclareModel:
+		return &DeclareModelCommand{PlayerID: e.PlayerID, ModelProfile: e.Text}, nil
+	case EntryStartGame:
+		return &StartGameCommand{
+			MinPlayers:  e.MinPlayers,
+			MaxPlayers:  e.MaxPlayers,
+			Traits:      e.Traits,
+			Ruleset:     e.Ruleset,
+			PinnedRoles: e.PinnedRoles,
+		}, nil
+	case EntryVote:
+		return &VoteCommand{VoterID: e.PlayerID, TargetID: e.TargetID}, nil
+	case EntryChat:
+		return &ChatCommand{SenderID: e.PlayerID, Message: e.Text}, nil
+	case EntryMafiaChat:
+		return &MafiaChatCommand{SenderID: e.PlayerID, Message: e.Text}, nil
+	case EntryNightAction:
+		return &NightActionCommand{Role: e.Role, ActorID: e.PlayerID, TargetID: e.TargetID}, nil
+	case EntryThought:
+		return &ThoughtCommand{PlayerID: e.PlayerID, Thought: e.Text}, nil
+	case EntryPhaseChange:
+		phase, ok := domain.ParsePhase(e.Phase)
+		if !ok {
+			return nil, fmt.Errorf("unknown phase %q", e.Phase)
+		}
+		return &PhaseChangeCommand{NewPhase: phase}, nil
+	case EntryEliminatePlayer:
+		return &EliminatePlayerCommand{PlayerID: e.PlayerID, Reason: e.Text}, nil
+	default:
+		return nil, fmt.Errorf("unknown journal entry kind %q", e.Kind)
+	}
+}
@@ -0,0 +1,150 @@
+package engine
+
+import (
+	"encoding/json"
+	"reflect"
+	"testing"
+	"time"
+
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/domain"
+)
+
+func TestJournal_RebuildsGame(t *testing.T) {
+	cfg := testConfig(t)
+	virtual := clock.NewVirtual(time.Unix(1000, 0))
+	journal := NewJournal()
+	state := domain.NewGameState("test")
+
+	eng, err := NewEngine(state, &fakeProducer{}, cfg, WithClock(virtual), WithJournal(journal))
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatalf("AddPlayer failed: %v", err)
+		}
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatalf("StartGame failed: %v", err)
+	}
+	eng.ProcessPending()
+	afterStart := journal.Len()
+
+	// read-only and rejected commands are not recorded
+	eng.cmdCh <- &StateRequestCommand{PlayerID: "player-1"}
+	eng.cmdCh <- &VoteCommand{VoterID: "player-1", TargetID: "player-2"} // not voting yet
+	eng.ProcessPending()
+	if journal.Len() != afterStart {
+		t.Fatalf("expected %d commands, got %d", afterStart, journal.Len())
+	}
+
+	// play a night and a day into voting
+	virtual.Advance(cfg.PhaseNightTimeout)
+	eng.ProcessPending()
+	virtual.Advance(cfg.PhaseDayTimeout)
+	eng.ProcessPending()
+	eng.cmdCh <- &VoteCommand{VoterID: "player-1", TargetID: "player-2"}
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseVoting || len(state.Votes) != 1 {
+		t.Fatalf("expected one vote in the voting phase, got %s %v", state.Phase, state.Votes)
+	}
+
+	rebuilt, err := journal.StateAt(journal.Len())
+	if err != nil {
+		t.Fatalf("StateAt failed: %v", err)
+	}
+	rebuilt.PhaseEndsAt = state.PhaseEndsAt // set by the engine, not by commands
+	if !reflect.DeepEqual(rebuilt, state) {
+		t.Errorf("rebuilt state differs:\n got %+v\nwant %+v", rebuilt, state)
+	}
+
+	// the game as it was right after the start
+	started, err := journal.StateAt(afterStart)
+	if err != nil {
+		t.Fatalf("StateAt failed: %v", err)
+	}
+	if started.Phase != domain.PhaseNight || started.Round != 1 {
+		t.Errorf("expected night 1, got %s %d", started.Phase, started.Round)
+	}
+	for id, player := range started.Players {
+		if player.Role != state.Players[id].Role {
+			t.Errorf("%s: replay dealt %s, game dealt %s", id, player.Role, state.Players[id].Role)
+		}
+	}
+
+	if _, err := journal.StateAt(journal.Len() + 1); err == nil {
+		t.Error("expected error past the end of the journal")
+	}
+
+	// a journal read back from JSON rebuilds the same game
+	data, err := json.Marshal(journal)
+	if err != nil {
+		t.Fatalf("Marshal failed: %v", err)
+	}
+	loaded := NewJournal()
+	if err := json.Unmarshal(data, loaded); err != nil {
+		t.Fatalf("Unmarshal failed: %v", err)
+	}
+	reloaded, err := loaded.StateAt(loaded.Len())
+	if err != nil {
+		t.Fatalf("StateAt on the loaded journal failed: %v", err)
+	}
+	reloaded.PhaseEndsAt = state.PhaseEndsAt
+	if !reflect.DeepEqual(reloaded, state) {
+		t.Errorf("loaded journal rebuilds a different state:\n got %+v\nwant %+v", reloaded, state)
+	}
+
+	// the vote is stamped with when the engine took it
+	entries, _ := loaded.Entries()
+	vote := entries[len(entries)-1]
+	if vote.Kind != EntryVote || vote.PlayerID != "player-1" || vote.TargetID != "player-2" {
+		t.Fatalf("expected player-1's vote last, got %+v", vote)
+	}
+	if vote.Timestamp != virtual.Now().UnixMilli() || vote.Seq != eng.history.LastSeq()-1 {
+		t.Errorf("vote stamped seq %d at %d, want seq %d at %d",
+			vote.Seq, vote.Timestamp, eng.history.LastSeq()-1, virtual.Now().UnixMilli())
+	}
+}
+
+func TestJournal_KeepsThoughtsAsRedacted(t *testing.T) {
+	state := domain.NewGameState("test")
+	state.Phase = domain.PhaseDay
+	state.AddPlayer(&domain.Player{ID: "p1", Name: "Alice", Alive: true})
+	policy, _ := NewThoughtPolicy(0, []string{"secret"})
+
+	journal := NewJournal()
+	journal.begin(state)
+	cmd := &ThoughtCommand{PlayerID: "p1", Thought: "my secret plan", Policy: policy}
+	if _, err := cmd.Apply(state); err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	journal.record(cmd, state, 0, 0)
+
+	entries, err := journal.Entries()
+	if err != nil {
+		t.Fatalf("Entries failed: %v", err)
+	}
+	if entries[0].Text != "my [redacted] plan" {
+		t.Errorf("journal kept %q", entries[0].Text)
+	}
+}
+
+func TestJournal_CommandsAreCopies(t *testing.T) {
+	journal := NewJournal()
+	journal.begin(domain.NewGameState("test"))
+
+	player, _ := domain.NewPlayer("p1", "Alice", domain.RoleUnknown)
+	journal.record(&AddPlayerCommand{Player: player, MaxPlayers: 4}, domain.NewGameState("test"), 0, 0)
+	player.Alive = false // the game keeps mutating its player
+
+	first := journal.Commands()[0].(*AddPlayerCommand)
+	if !first.Player.Alive {
+		t.Error("journal shares the player with the game")
+	}
+	if second := journal.Commands()[0].(*AddPlayerCommand); second.Player == first.Player {
+		t.Error("every call should return fresh copies")
+	}
+}
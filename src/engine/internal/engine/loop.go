@@ -1,10 +1,15 @@
 package engine
 
+import (
+	"time"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
 // run serializes all state mutation and effect execution.
 // It is the only place where GameState is modified.
-// This is a two-phase executor:
-//  1. Decision phase: command mutates state and returns effects
-//  2. Effect phase: engine executes effects (Kafka, timers, etc.)
 func (e *Engine) run() {
 	for {
 		select {
@@ -12,62 +17,159 @@ func (e *Engine) run() {
 			return
 
 		case cmd := <-e.cmdCh:
-			// Phase 1: Apply command (pure state transformation)
-			effects, err := cmd.Apply(e.state)
-			if err != nil {
-				// Command validation failed - do not execute effects
-				// TODO: Add proper logging and error event emission
-				_ = err
-				continue
-			}
+			e.process(cmd)
+		}
+	}
+}
 
-			// Phase 2: Execute effects (side effects happen here)
-			// side effects are any value that modifies an external system
-			// (e.g. kafka publish) and or non-determenistic (e.g. timestamp)
-			for _, effect := range effects {
-				if err := effect.Execute(e.ctx, e.producer); err != nil {
-					// Effect execution failed
-					// TODO: Add retry logic, logging, metrics
-					// Decision: continue with other effects or stop?
-					_ = err
-				}
-			}
+// process handles a single command.
+// This is a two-phase executor:
+//  1. Decision phase: command mutates state and returns effects
+//  2. Effect phase: engine executes effects (Kafka, timers, etc.)
+func (e *Engine) process(cmd Command) {
+	// Commands answering from the event log get it injected here,
+	// so Apply stays a pure function of its inputs
+	switch c := cmd.(type) {
+	case *ResyncCommand:
+		c.History = e.history
+	case *StateRequestCommand:
+		c.History = e.history
+	case *ThoughtCommand:
+		c.Policy = e.thoughts
+	}
+
+	// Phase 1: Apply command (pure state transformation)
+	round, phase := e.state.Round, e.state.Phase
+	effects, err := cmd.Apply(e.state)
+	if err != nil {
+		// Command validation failed - do not execute effects
+		// TODO: Add proper logging and error event emission
+		_ = err
+		return
+	}
+	if e.journal != nil {
+		e.journal.record(cmd, e.state, e.history.LastSeq(), e.clock.Now().UnixMilli())
+	}
+	e.measure(cmd, round, phase)
 
-			// Phase 3: Schedule phase timer if phase changed
-			// Cancel old timer and schedule new one based on current phase
-			if _, isPhaseChange := cmd.(*PhaseChangeCommand); isPhaseChange {
-				e.timers.CancelPhaseTimer()
-
-				// Schedule timeout for the new phase (if applicable)
-				timeout := GetPhaseTimeout(e.state.Phase, e.cfg.PhaseNightTimeout, e.cfg.PhaseDayTimeout, e.cfg.PhaseVotingTimeout)
-				if timeout > 0 {
-					nextPhase := GetNextPhase(e.state.Phase)
-					e.timers.SchedulePhaseTimeout(
-						e.state.Phase,
-						e.state.Round,
-						timeout,
-						nextPhase,
-						e.cmdCh,
-						e.ctx,
-					)
+	// Phase 2: Schedule phase timer if phase changed, before publishing, so
+	// PhaseChanged can tell players when the new phase ends
+	// Cancel old timer (and its warnings) and schedule new one based on current phase
+	switch cmd.(type) {
+	case *PhaseChangeCommand, *StartGameCommand:
+		e.timers.CancelPhaseTimer()
+		timeout := e.schedulePhaseTimer()
+		for _, effect := range effects {
+			if publish, ok := effect.(*PublishEffect); ok {
+				if changed, ok := publish.Event.(*events.PhaseChanged); ok {
+					changed.EndsAt = e.state.PhaseEndsAt
+					changed.Duration = timeout.Milliseconds()
 				}
 			}
+		}
+	}
+
+	// A game that ended mid-phase (e.g. an elimination) must not time out or warn
+	if e.state.Phase == domain.PhaseEnded {
+		e.timers.CancelPhaseTimer()
+	}
+
+	// Phase 3: Execute effects (side effects happen here)
+	// side effects are any value that modifies an external system
+	// (e.g. kafka publish) and or non-determenistic (e.g. timestamp)
+	e.execute(effects)
+
+	// Phase 4: Ask the players of the new phase to act, now that the deadline is known,
+	// or give a busy chat more time
+	switch cmd.(type) {
+	case *PhaseChangeCommand, *StartGameCommand:
+		e.execute(actionRequests(e.state))
+	case *ChatCommand:
+		e.extendPhase()
+	}
+}
 
-			// Also schedule timer when game starts
-			if _, isStartGame := cmd.(*StartGameCommand); isStartGame {
-				timeout := GetPhaseTimeout(e.state.Phase, e.cfg.PhaseNightTimeout, e.cfg.PhaseDayTimeout, e.cfg.PhaseVotingTimeout)
-				if timeout > 0 {
-					nextPhase := GetNextPhase(e.state.Phase)
-					e.timers.SchedulePhaseTimeout(
-						e.state.Phase,
-						e.state.Round,
-						timeout,
-						nextPhase,
-						e.cmdCh,
-						e.ctx,
-					)
+// execute runs effects in order.
+func (e *Engine) execute(effects []Effect) {
+	for _, effect := range effects {
+		// Stamp time/seq/round/phase before publishing. Events are recorded even if
+		// the publish fails, so a resync can still deliver them later.
+		if publish, isPublish := effect.(*PublishEffect); isPublish {
+			publish.Timestamp = e.clock.Now().UnixMilli()
+			if ev, ok := publish.Event.(events.Event); ok {
+				if publish.Topic == kafka.ObserverEventsTopic {
+					stampContext(ev, e.state)
+				} else {
+					e.history.Stamp(ev, e.state)
 				}
 			}
 		}
+
+		if err := effect.Execute(e.ctx, e.producer); err != nil {
+			// Effect execution failed
+			// TODO: Add retry logic, logging, metrics
+			// Decision: continue with other effects or stop?
+			_ = err
+		}
+	}
+}
+
+// schedulePhaseTimer schedules the timeout for the current phase (if applicable)
+// and its countdown warnings, records the deadline in state so players can be
+// told when the phase ends, and returns the timeout (0 if none).
+func (e *Engine) schedulePhaseTimer() time.Duration {
+	e.state.PhaseEndsAt = 0
+
+	// the ruleset's duration policy for the phase, if any, adapts the configured timeout
+	timeout := GetPhaseTimeout(e.state.Phase, e.cfg.PhaseNightTimeout, e.cfg.PhaseDayTimeout, e.cfg.PhaseVotingTimeout)
+	if timeout > 0 {
+		timeout = e.ruleset.PhaseDuration(e.state, timeout)
 	}
+	if timeout <= 0 {
+		return 0
+	}
+
+	e.state.PhaseEndsAt = e.clock.Now().Add(timeout).UnixMilli()
+	e.phaseLength, e.chatMark = timeout, 0
+
+	nextPhase := GetNextPhase(e.state.Phase)
+	e.timers.SchedulePhaseTimeout(
+		e.phaseTimeout(),
+		timeout,
+		nextPhase,
+		e.cmdCh,
+		e.ctx,
+	)
+	e.timers.ScheduleWarnings(e.phaseTimeout(), timeout, e.cfg.PhaseWarnings, e.cmdCh, e.ctx)
+	return timeout
+}
+
+// phaseTimeout identifies the running phase and its current deadline.
+func (e *Engine) phaseTimeout() PhaseTimeout {
+	return PhaseTimeout{Phase: e.state.Phase, Round: e.state.Round, EndsAt: e.state.PhaseEndsAt}
+}
+
+// extendPhase moves the phase timeout (and its warnings) later while the chat
+// is busy, as far as the ruleset's duration policy allows, and tells the players.
+func (e *Engine) extendPhase() {
+	if e.state.PhaseEndsAt == 0 {
+		return
+	}
+	extension := e.ruleset.PhaseExtension(e.state, e.state.PhaseChat-e.chatMark, e.phaseLength)
+	if extension <= 0 {
+		return
+	}
+	e.chatMark = e.state.PhaseChat
+	e.phaseLength += extension
+	e.state.PhaseEndsAt += extension.Milliseconds()
+	remaining := time.UnixMilli(e.state.PhaseEndsAt).Sub(e.clock.Now())
+
+	// a timeout or warning already queued for the old deadline is now stale
+	e.timers.SchedulePhaseTimeout(e.phaseTimeout(), remaining, GetNextPhase(e.state.Phase), e.cmdCh, e.ctx)
+	e.timers.ScheduleWarnings(e.phaseTimeout(), remaining, e.cfg.PhaseWarnings, e.cmdCh, e.ctx)
+	e.execute([]Effect{NewPublishEffect(&events.PhaseExtended{
+		BaseEvent: events.BaseEvent{GameID: e.state.ID, Type: events.TypePhaseExtended},
+		EndsAt:    e.state.PhaseEndsAt,
+		Extension: extension.Milliseconds(),
+	})})
 }
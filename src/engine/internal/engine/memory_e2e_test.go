@@ -0,0 +1,107 @@
+package engine
+
+import (
+	"context"
+	"sync"
+	"testing"
+	"time"
+
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
+// TestEngine_FullGameOnMemoryBroker plays a whole game with built-in bots
+// over the in-memory transport: no Kafka, no external agents.
+func TestEngine_FullGameOnMemoryBroker(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PhaseNightTimeout = 20 * time.Millisecond
+	cfg.PhaseDayTimeout = 20 * time.Millisecond
+	cfg.PhaseVotingTimeout = 20 * time.Millisecond
+
+	broker := kafka.NewMemoryBroker(kafka.DefaultMemoryPartitions)
+	defer broker.Close()
+
+	producer, _ := kafka.NewMemoryProducer(broker)
+	actions, _ := kafka.NewMemoryConsumer(broker, kafka.PlayerActionsTopic, kafka.EngineConsumerGroup)
+	botEvents, _ := kafka.NewMemoryConsumer(broker, kafka.EngineEventsTopic, kafka.BotsConsumerGroup)
+	observer, _ := kafka.NewMemoryConsumer(broker, kafka.EngineEventsTopic, "test-observer")
+
+	state := domain.NewGameState("test")
+	state.Seed = 7
+	eng, err := NewEngine(state, producer, cfg)
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+
+	strategies, err := bots.NewStrategies(cfg.BotStrategies)
+	if err != nil {
+		t.Fatalf("NewStrategies failed: %v", err)
+	}
+	runtime, err := bots.NewRuntime(state.ID, producer, strategies, state.Seed)
+	if err != nil {
+		t.Fatalf("NewRuntime failed: %v", err)
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	var wg sync.WaitGroup
+	run := func(consumer kafka.Consumer, handler kafka.HandlerFunc) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			_ = consumer.Consume(ctx, handler)
+		}()
+	}
+
+	ended := make(chan *events.GameEnded, 1)
+	run(actions, eng.HandleMessage)
+	run(botEvents, runtime.HandleMessage)
+	run(observer, func(_ context.Context, msg kafka.Message) error {
+		ev, err := events.DeserializeEngineEvent(msg.Value)
+		if err != nil {
+			return err
+		}
+		if gameEnded, ok := ev.(*events.GameEnded); ok {
+			ended <- gameEnded
+		}
+		return nil
+	})
+
+	eng.Start()
+	defer func() {
+		cancel()
+		wg.Wait()
+		eng.Stop()
+	}()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatalf("AddPlayer failed: %v", err)
+		}
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatalf("StartGame failed: %v", err)
+	}
+
+	select {
+	case gameEnded := <-ended:
+		if gameEnded.Winner != domain.WinnerVillage.String() && gameEnded.Winner != domain.WinnerMafia.String() {
+			t.Errorf("unexpected winner %q", gameEnded.Winner)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("game did not end")
+	}
+
+	var votes int
+	for _, msg := range broker.Messages(kafka.PlayerActionsTopic) {
+		if ev, err := events.Deserialize(msg.Value); err == nil {
+			if _, ok := ev.(*events.VoteSubmitted); ok {
+				votes++
+			}
+		}
+	}
+	if votes == 0 {
+		t.Error("expected bots to vote through the broker")
+	}
+}
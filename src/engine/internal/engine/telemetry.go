@@ -0,0 +1,74 @@
+package engine
+
+import (
+	"time"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/telemetry"
+)
+
+// WithTelemetry records the usage and response time of every accepted player
+// action in r.
+func WithTelemetry(r *telemetry.Recorder) Option {
+	return func(e *Engine) {
+		e.telemetry = r
+	}
+}
+
+// actionCommand is a player action measured for telemetry.
+type actionCommand interface {
+	Command
+	actor() (playerID string, usage *events.Telemetry)
+}
+
+func (c *VoteCommand) actor() (string, *events.Telemetry)        { return c.VoterID, c.Telemetry }
+func (c *ChatCommand) actor() (string, *events.Telemetry)        { return c.SenderID, c.Telemetry }
+func (c *MafiaChatCommand) actor() (string, *events.Telemetry)   { return c.SenderID, c.Telemetry }
+func (c *NightActionCommand) actor() (string, *events.Telemetry) { return c.ActorID, c.Telemetry }
+
+// responseClock measures how long players take to act: from the phase
+// opening to each player's first accepted action in it.
+type responseClock struct {
+	opened    time.Time
+	responded map[string]bool
+}
+
+// open starts timing a new phase.
+func (c *responseClock) open(now time.Time) {
+	c.opened = now
+	c.responded = make(map[string]bool)
+}
+
+// respond returns how long the player took, if this is their first action
+// since the phase opened.
+func (c *responseClock) respond(playerID string, now time.Time) (time.Duration, bool) {
+	if c.opened.IsZero() || c.responded[playerID] {
+		return 0, false
+	}
+	c.responded[playerID] = true
+	return now.Sub(c.opened), true
+}
+
+// measure records an accepted command's telemetry and restarts the response
+// clock when the command moved the game to a new phase.
+func (e *Engine) measure(cmd Command, round int, phase domain.Phase) {
+	now := e.clock.Now()
+	if action, ok := cmd.(actionCommand); ok && e.telemetry != nil {
+		playerID, usage := action.actor()
+		sample := telemetry.Sample{GameID: e.state.ID, PlayerID: playerID, Usage: usage}
+		if usage != nil && usage.Model != "" {
+			sample.Model = usage.Model
+		} else if player := e.state.GetPlayer(playerID); player != nil {
+			sample.Model = player.ModelProfile
+		}
+		if took, ok := e.responses.respond(playerID, now); ok {
+			sample.Responded, sample.ResponseMs = true, took.Milliseconds()
+		}
+		e.telemetry.Record(sample)
+	}
+
+	if e.state.Round != round || e.state.Phase != phase {
+		e.responses.open(now)
+	}
+}
@@ -0,0 +1,105 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+	"mafia-engine/internal/telemetry"
+)
+
+// Response times run from the phase opening on the engine clock; the model
+// falls back to the player's profile when the agent doesn't name it.
+func TestEngine_RecordsTelemetry(t *testing.T) {
+	cfg := testConfig(t)
+	virtual := clock.NewVirtual(time.Unix(1000, 0))
+	usage := telemetry.NewRecorder()
+	state := domain.NewGameState("test")
+	eng, err := NewEngine(state, &fakeProducer{}, cfg, WithClock(virtual), WithTelemetry(usage))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayerWithProfile("bot:random"); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	eng.ProcessPending()
+
+	send := func(event any) {
+		t.Helper()
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := eng.HandleMessage(context.Background(), kafka.Message{Value: data}); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+	mafia := state.GetPlayersWithRole(domain.RoleMafia)[0]
+	doctor := state.GetPlayersWithRole(domain.RoleDoctor)[0]
+	night := func(actor *domain.Player, target string, usage *events.Telemetry) {
+		send(&events.NightAction{
+			BaseEvent: events.BaseEvent{GameID: state.ID, Type: events.TypeNightAction},
+			Role:      actor.Role.String(),
+			ActorID:   actor.ID,
+			TargetID:  target,
+			Telemetry: usage,
+		})
+	}
+
+	virtual.Advance(2 * time.Second)
+	night(mafia, doctor.ID, &events.Telemetry{Model: "gpt-4o", PromptTokens: 300, CompletionTokens: 40, LatencyMs: 1500})
+	virtual.Advance(time.Second)
+	night(doctor, doctor.ID, nil)
+	send(&events.MafiaChatMessage{ // a second action is no new response
+		BaseEvent: events.BaseEvent{GameID: state.ID, Type: events.TypeMafiaChatMessage},
+		SenderID:  mafia.ID,
+		Message:   "done",
+	})
+
+	report := usage.Report(state.ID)
+	if report == nil {
+		t.Fatal("nothing recorded")
+	}
+	if m := report.Players[mafia.ID]; m.Actions != 2 || m.Reported != 1 || m.Response.Count != 1 || m.Response.Sum != 2000 {
+		t.Errorf("unexpected mafia stats %+v", m)
+	}
+	if d := report.Players[doctor.ID]; d.Response.Sum != 3000 || d.Reported != 0 {
+		t.Errorf("unexpected doctor stats %+v", d)
+	}
+	if gpt := report.Models["gpt-4o"]; gpt == nil || gpt.PromptTokens != 300 || gpt.Latency.Sum != 1500 {
+		t.Errorf("unexpected gpt-4o stats %+v", gpt)
+	}
+	if bots := report.Models["bot:random"]; bots == nil || bots.Actions != 2 {
+		t.Errorf("the model must fall back to the profile: %+v", report.Models)
+	}
+
+	// the clock restarts with the next phase
+	virtual.Advance(cfg.PhaseNightTimeout)
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseDay {
+		t.Fatalf("expected day, got %s", state.Phase)
+	}
+	virtual.Advance(500 * time.Millisecond)
+	send(&events.AllChatMessage{
+		BaseEvent: events.BaseEvent{GameID: state.ID, Type: events.TypeAllChatMessage},
+		SenderID:  mafia.ID,
+		Message:   "morning",
+	})
+	if m := usage.Report(state.ID).Players[mafia.ID]; m.Response.Count != 2 || m.Response.Max != 2000 || m.Response.Sum != 2500 {
+		t.Errorf("unexpected day response %+v", m.Response)
+	}
+}
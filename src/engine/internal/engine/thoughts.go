@@ -0,0 +1,126 @@
+package engine
+
+import (
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"unicode/utf8"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+)
+
+// RedactedText replaces every match of a redaction pattern in a thought.
+const RedactedText = "[redacted]"
+
+// ThoughtPolicy limits what is kept of a player's thoughts, from
+// ENGINE_THOUGHT_MAX_BYTES and ENGINE_THOUGHT_REDACT.
+type ThoughtPolicy struct {
+	MaxBytes int // 0 = no limit
+	Redact   []*regexp.Regexp
+}
+
+// NewThoughtPolicy compiles the redaction patterns.
+func NewThoughtPolicy(maxBytes int, patterns []string) (*ThoughtPolicy, error) {
+	if maxBytes < 0 {
+		return nil, errors.New("max bytes must be >= 0")
+	}
+	policy := &ThoughtPolicy{MaxBytes: maxBytes}
+	for _, pattern := range patterns {
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid pattern %q: %w", pattern, err)
+		}
+		policy.Redact = append(policy.Redact, re)
+	}
+	return policy, nil
+}
+
+// Apply redacts the thought, then cuts it to MaxBytes on a rune boundary.
+// It returns the text, how many matches were redacted and whether it was cut.
+func (p *ThoughtPolicy) Apply(text string) (string, int, bool) {
+	if p == nil {
+		return text, 0, false
+	}
+
+	redactions := 0
+	for _, re := range p.Redact {
+		text = re.ReplaceAllStringFunc(text, func(string) string {
+			redactions++
+			return RedactedText
+		})
+	}
+
+	if p.MaxBytes == 0 || len(text) <= p.MaxBytes {
+		return text, redactions, false
+	}
+	cut := p.MaxBytes
+	for cut > 0 && !utf8.RuneStart(text[cut]) {
+		cut--
+	}
+	return text[:cut], redactions, true
+}
+
+// ThoughtCommand keeps a player's thought and re-publishes it to observers.
+// It never reaches players, and the game doesn't change.
+type ThoughtCommand struct {
+	PlayerID string
+	Thought  string
+
+	// Policy is attached by the engine loop before Apply (nil keeps everything)
+	Policy *ThoughtPolicy
+}
+
+func (c *ThoughtCommand) Apply(state *domain.GameState) ([]Effect, error) {
+	// Validation 1: Game is still running
+	if state.Phase == domain.PhaseEnded {
+		return nil, errors.New("game has ended")
+	}
+
+	// Validation 2: Player exists (dead players may still think)
+	if state.GetPlayer(c.PlayerID) == nil {
+		return nil, fmt.Errorf("player %s not found", c.PlayerID)
+	}
+
+	// Validation 3: Something to keep
+	if strings.TrimSpace(c.Thought) == "" {
+		return nil, errors.New("thought must not be empty")
+	}
+
+	text, redactions, truncated := c.Policy.Apply(c.Thought)
+	thought := state.AddThought(c.PlayerID, text)
+
+	event := &events.PlayerThoughts{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypePlayerThoughts,
+		},
+		Thought:    thought.Text,
+		SenderID:   thought.PlayerID,
+		ThoughtID:  thought.ID,
+		Redactions: redactions,
+		Truncated:  truncated,
+	}
+	return []Effect{NewObserverEffect(event)}, nil
+}
+
+// linkThoughts links the player's pending thoughts to the action they just
+// took, and returns the observer effect announcing it (none without thoughts).
+func linkThoughts(state *domain.GameState, playerID, action, targetID string) []Effect {
+	ids := state.LinkThoughts(playerID, action, targetID)
+	if len(ids) == 0 {
+		return []Effect{}
+	}
+	event := &events.ThoughtLinked{
+		BaseEvent: events.BaseEvent{
+			GameID: state.ID,
+			Type:   events.TypeThoughtLinked,
+		},
+		PlayerID:   playerID,
+		ThoughtIDs: ids,
+		Action:     action,
+		TargetID:   targetID,
+	}
+	return []Effect{NewObserverEffect(event)}
+}
@@ -0,0 +1,160 @@
+package engine
+
+import (
+	"context"
+	"encoding/json"
+	"reflect"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
+func TestThoughtPolicy_Apply(t *testing.T) {
+	policy, err := NewThoughtPolicy(12, []string{`sk-\w+`})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// redacted first, then cut
+	text, redactions, truncated := policy.Apply("key sk-abc1")
+	if text != "key [redacte" || redactions != 1 || !truncated {
+		t.Errorf("got %q, %d redactions, truncated=%v", text, redactions, truncated)
+	}
+
+	// cut on a rune boundary: "é" is two bytes
+	text, _, truncated = policy.Apply("ééééééé")
+	if text != "éééééé" || !truncated {
+		t.Errorf("got %q truncated=%v", text, truncated)
+	}
+
+	if _, err := NewThoughtPolicy(0, []string{"("}); err == nil {
+		t.Error("expected error for an invalid pattern")
+	}
+	var none *ThoughtPolicy
+	if text, _, _ := none.Apply("anything"); text != "anything" {
+		t.Errorf("nil policy changed the thought: %q", text)
+	}
+}
+
+func TestThoughtCommand_Validation(t *testing.T) {
+	state := &domain.GameState{ID: "test", Phase: domain.PhaseDay, Players: make(map[string]*domain.Player)}
+	p1, _ := domain.NewPlayer("p1", "Alice", domain.RoleVillager)
+	state.AddPlayer(p1)
+
+	for name, cmd := range map[string]*ThoughtCommand{
+		"unknown player": {PlayerID: "ghost", Thought: "hm"},
+		"empty":          {PlayerID: "p1", Thought: "  "},
+	} {
+		if _, err := cmd.Apply(state); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+
+	state.Phase = domain.PhaseEnded
+	if _, err := (&ThoughtCommand{PlayerID: "p1", Thought: "gg"}).Apply(state); err == nil {
+		t.Error("expected error after the game ended")
+	}
+}
+
+// Thoughts go to observers only, without seq, and are linked to the next action.
+func TestEngine_ThoughtsToObservers(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.ThoughtRedact = []string{`sk-\w+`}
+	producer := &fakeProducer{}
+	state := domain.NewGameState("test")
+	eng, err := NewEngine(state, producer, cfg)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	eng.ProcessPending()
+
+	mafia := state.GetPlayersWithRole(domain.RoleMafia)[0]
+	var target string
+	for _, p := range state.GetSeatedPlayers() {
+		if p.Role.Faction() == domain.FactionVillage {
+			target = p.ID
+			break
+		}
+	}
+
+	send := func(event any) {
+		t.Helper()
+		data, err := json.Marshal(event)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := eng.HandleMessage(context.Background(), kafka.Message{Value: data}); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+	published := len(producer.messages)
+	send(&events.PlayerThoughts{
+		BaseEvent: events.BaseEvent{GameID: state.ID, Type: events.TypePlayerThoughts},
+		SenderID:  mafia.ID,
+		Thought:   "my key is sk-secret, kill " + target,
+	})
+	send(&events.NightAction{
+		BaseEvent: events.BaseEvent{GameID: state.ID, Type: events.TypeNightAction},
+		Role:      "mafia",
+		ActorID:   mafia.ID,
+		TargetID:  target,
+	})
+
+	// the night's action status goes to observers too
+	messages := producer.messages[published:]
+	if len(messages) != 3 {
+		t.Fatalf("expected a thought, a status and a link, got %d messages", len(messages))
+	}
+	for _, msg := range messages {
+		if msg.Topic != kafka.ObserverEventsTopic {
+			t.Errorf("published to %s, want %s", msg.Topic, kafka.ObserverEventsTopic)
+		}
+	}
+
+	var thought events.PlayerThoughts
+	if err := json.Unmarshal(messages[0].Value, &thought); err != nil {
+		t.Fatal(err)
+	}
+	if thought.Seq != 0 || thought.Round != 1 || thought.Phase != "night" || thought.ThoughtID != 1 {
+		t.Errorf("unexpected header %+v", thought.BaseEvent)
+	}
+	if strings.Contains(thought.Thought, "sk-secret") || thought.Redactions != 1 {
+		t.Errorf("thought not redacted: %+v", thought)
+	}
+
+	var linked events.ThoughtLinked
+	if err := json.Unmarshal(messages[2].Value, &linked); err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(linked.ThoughtIDs, []int64{1}) || linked.Action != events.ActionNightAction || linked.TargetID != target {
+		t.Errorf("unexpected link %+v", linked)
+	}
+
+	if len(state.Thoughts) != 1 || state.Thoughts[0].Action != events.ActionNightAction {
+		t.Errorf("thought not kept with the game: %+v", state.Thoughts)
+	}
+	players := 0
+	for _, msg := range producer.messages {
+		if msg.Topic != kafka.ObserverEventsTopic {
+			players++
+		}
+	}
+	if eng.history.LastSeq() != int64(players) {
+		t.Errorf("observer events took player seq numbers: last seq %d, %d player events", eng.history.LastSeq(), players)
+	}
+}
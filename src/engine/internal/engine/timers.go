@@ -7,30 +7,51 @@ import (
 	"sync"
 	"time"
 
+	"mafia-engine/internal/clock"
 	"mafia-engine/internal/domain"
 )
 
 // TimerManager tracks and manages phase timeout timers.
 // It ensures only one phase timer is active at a time and provides
 // cancellation support for graceful shutdown and manual phase changes.
+// The phase's countdown warnings belong to its timer and go with it.
 type TimerManager struct {
-	mu           sync.Mutex
-	phaseTimer   *time.Timer // current phase timer (nil if none active)
-	phaseTimerID string      // identifier for debugging (e.g., "night-round-2")
+	mu            sync.Mutex
+	clock         clock.Clock
+	phaseTimer    clock.Timer   // current phase timer (nil if none active)
+	phaseTimerID  string        // identifier for debugging (e.g., "night-round-2")
+	warningTimers []clock.Timer // countdown warnings of the current phase
 }
 
-// NewTimerManager creates a new TimerManager with no active timers.
+// NewTimerManager creates a new TimerManager on the wall clock with no active timers.
 func NewTimerManager() *TimerManager {
-	return &TimerManager{}
+	return NewTimerManagerWithClock(clock.Real{})
+}
+
+// NewTimerManagerWithClock creates a TimerManager whose timers run on the given clock.
+func NewTimerManagerWithClock(c clock.Clock) *TimerManager {
+	return &TimerManager{clock: c}
+}
+
+// PhaseTimeout identifies the phase a timer was set for and its deadline.
+type PhaseTimeout struct {
+	Phase  domain.Phase
+	Round  int
+	EndsAt int64 // Unix ms, the state's PhaseEndsAt when the timer was set
+}
+
+// Stale reports whether the timed phase is over, or its deadline moved
+// (see Engine.extendPhase), so the timer's command must be dropped.
+func (t PhaseTimeout) Stale(state *domain.GameState) bool {
+	return state.Phase != t.Phase || state.Round != t.Round || state.PhaseEndsAt != t.EndsAt
 }
 
 // SchedulePhaseTimeout schedules a timer to automatically advance to the next phase.
 // If a previous phase timer exists, it is cancelled first.
-// When the timer fires, it sends a PhaseChangeCommand to the command channel.
-// ctx must not be nil.
+// When the timer fires, it sends a PhaseChangeCommand, guarded by timeout, to
+// the command channel. ctx must not be nil.
 func (tm *TimerManager) SchedulePhaseTimeout(
-	currentPhase domain.Phase,
-	round int,
+	timeout PhaseTimeout,
 	duration time.Duration,
 	nextPhase domain.Phase,
 	cmdCh chan Command,
@@ -44,20 +65,17 @@ func (tm *TimerManager) SchedulePhaseTimeout(
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
-	// Cancel previous phase timer if it exists
-	if tm.phaseTimer != nil {
-		tm.phaseTimer.Stop()
-		tm.phaseTimer = nil
-	}
+	// Cancel previous phase timer (and its warnings) if it exists
+	tm.stopLocked()
 
 	// Create timer ID for debugging (fixed: was using string(rune(round)) which is wrong, converts numbers to unicode)
-	tm.phaseTimerID = currentPhase.String() + "-round-" + strconv.Itoa(round)
+	tm.phaseTimerID = timeout.Phase.String() + "-round-" + strconv.Itoa(timeout.Round)
 
 	// Schedule new timer
 	timerID := tm.phaseTimerID // capture for closure
-	tm.phaseTimer = time.AfterFunc(duration, func() {
+	tm.phaseTimer = tm.clock.AfterFunc(duration, func() {
 		// Send phase change command when timer fires
-		cmd := &PhaseChangeCommand{NewPhase: nextPhase}
+		cmd := &PhaseChangeCommand{NewPhase: nextPhase, Timeout: &timeout}
 
 		// Blocking send with context check only
 		// If channel is full, we block — dropping phase changes silently is dangerous
@@ -79,11 +97,55 @@ func (tm *TimerManager) CancelPhaseTimer() {
 	tm.mu.Lock()
 	defer tm.mu.Unlock()
 
+	tm.stopLocked()
+	tm.phaseTimerID = ""
+}
+
+// ScheduleWarnings schedules a PhaseWarningCommand at each offset before the
+// phase times out (after duration), e.g. 30s and 10s left. Offsets not
+// shorter than the phase are skipped. Call it after SchedulePhaseTimeout:
+// the warnings are cancelled with the phase timer, so a phase that ends
+// early never warns. ctx must not be nil.
+func (tm *TimerManager) ScheduleWarnings(
+	timeout PhaseTimeout,
+	duration time.Duration,
+	offsets []time.Duration,
+	cmdCh chan Command,
+	ctx context.Context,
+) {
+	if ctx == nil {
+		log.Printf("[TIMER] ERROR: nil context passed to ScheduleWarnings, skipping warnings")
+		return
+	}
+
+	tm.mu.Lock()
+	defer tm.mu.Unlock()
+
+	for _, remaining := range offsets {
+		if remaining <= 0 || remaining >= duration {
+			continue
+		}
+		cmd := &PhaseWarningCommand{PhaseTimeout: timeout, Remaining: remaining}
+		timer := tm.clock.AfterFunc(duration-remaining, func() {
+			select {
+			case cmdCh <- cmd:
+			case <-ctx.Done():
+			}
+		})
+		tm.warningTimers = append(tm.warningTimers, timer)
+	}
+}
+
+// stopLocked stops the phase timer and its warnings. tm.mu must be held.
+func (tm *TimerManager) stopLocked() {
 	if tm.phaseTimer != nil {
 		tm.phaseTimer.Stop()
 		tm.phaseTimer = nil
-		tm.phaseTimerID = ""
 	}
+	for _, timer := range tm.warningTimers {
+		timer.Stop()
+	}
+	tm.warningTimers = nil
 }
 
 // Shutdown stops all active timers.
@@ -4,7 +4,9 @@ import (
 	"testing"
 	"time"
 
+	"mafia-engine/internal/clock"
 	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
 )
 
 func TestGetPhaseTimeout(t *testing.T) {
@@ -58,7 +60,7 @@ func TestTimerManagerCancelBeforeFire(t *testing.T) {
 	tm := NewTimerManager()
 	cmdCh := make(chan Command, 1)
 
-	tm.SchedulePhaseTimeout(domain.PhaseNight, 1, 50*time.Millisecond, domain.PhaseDay, cmdCh, nil)
+	tm.SchedulePhaseTimeout(PhaseTimeout{Phase: domain.PhaseNight, Round: 1}, 50*time.Millisecond, domain.PhaseDay, cmdCh, nil)
 	tm.CancelPhaseTimer()
 
 	time.Sleep(100 * time.Millisecond)
@@ -67,3 +69,225 @@ func TestTimerManagerCancelBeforeFire(t *testing.T) {
 		t.Error("Timer fired after cancellation")
 	}
 }
+
+// PhaseChanged carries the deadline; warnings fire at the configured offsets
+// and never for a phase that already ended.
+func TestEngine_PhaseDeadlinesAndWarnings(t *testing.T) {
+	cfg := testConfig(t)
+	cfg.PhaseWarnings = []time.Duration{30 * time.Second, 10 * time.Second, time.Hour}
+	start := time.Unix(1000, 0)
+	virtual := clock.NewVirtual(start)
+	producer := &fakeProducer{}
+	state := domain.NewGameState("test")
+	eng, err := NewEngine(state, producer, cfg, WithClock(virtual))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	eng.ProcessPending()
+
+	// published since the last call
+	seen := 0
+	published := func() (changes []*events.PhaseChanged, warnings []*events.PhaseEndingSoon) {
+		t.Helper()
+		for _, msg := range producer.messages[seen:] {
+			ev, err := events.DeserializeEngineEvent(msg.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			switch e := ev.(type) {
+			case *events.PhaseChanged:
+				changes = append(changes, e)
+			case *events.PhaseEndingSoon:
+				warnings = append(warnings, e)
+			}
+		}
+		seen = len(producer.messages)
+		return changes, warnings
+	}
+
+	changes, _ := published()
+	endsAt := start.Add(cfg.PhaseNightTimeout).UnixMilli()
+	if len(changes) != 1 || changes[0].EndsAt != endsAt || changes[0].Duration != cfg.PhaseNightTimeout.Milliseconds() {
+		t.Fatalf("unexpected PhaseChanged %+v, want ends_at %d", changes, endsAt)
+	}
+
+	virtual.Advance(cfg.PhaseNightTimeout - 30*time.Second)
+	eng.ProcessPending()
+	virtual.Advance(20 * time.Second)
+	eng.ProcessPending()
+	_, warnings := published()
+	if len(warnings) != 2 || warnings[0].Remaining != 30000 || warnings[1].Remaining != 10000 {
+		t.Fatalf("expected warnings at 30s and 10s left (the 1h offset skipped), got %+v", warnings)
+	}
+	if warnings[0].EndsAt != endsAt || warnings[0].Phase != "night" || warnings[0].Seq == 0 {
+		t.Errorf("unexpected warning %+v", warnings[0])
+	}
+
+	// the day is ended early: its warnings must not fire
+	virtual.Advance(10 * time.Second)
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseDay {
+		t.Fatalf("expected day, got %s", state.Phase)
+	}
+	eng.cmdCh <- &PhaseChangeCommand{NewPhase: domain.PhaseVoting}
+	eng.ProcessPending()
+	published()
+	virtual.Advance(cfg.PhaseDayTimeout)
+	eng.ProcessPending()
+	_, warnings = published()
+	for _, warning := range warnings {
+		if warning.Phase == "day" {
+			t.Errorf("warning for a day that already ended: %+v", warning)
+		}
+	}
+
+	stale := &PhaseWarningCommand{PhaseTimeout: PhaseTimeout{Phase: domain.PhaseDay, Round: 1}, Remaining: 10 * time.Second}
+	if _, err := stale.Apply(state); err == nil {
+		t.Error("expected error for a warning about another phase")
+	}
+}
+
+// The ruleset's duration policies are evaluated in place of the fixed timeouts.
+func TestEngine_AdaptivePhaseDurations(t *testing.T) {
+	cfg := testConfig(t)
+	ruleset, err := domain.ParseRuleset([]byte(`{"name":"adaptive","durations":{"night":{"base":"10s","per_alive":"5s"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	start := time.Unix(1000, 0)
+	virtual := clock.NewVirtual(start)
+	state := domain.NewGameState("test")
+	eng, err := NewEngine(state, &fakeProducer{}, cfg, WithClock(virtual), WithRuleset(ruleset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	eng.ProcessPending()
+
+	night := 10*time.Second + time.Duration(cfg.GameMinPlayers)*5*time.Second
+	if want := start.Add(night).UnixMilli(); state.PhaseEndsAt != want {
+		t.Fatalf("night ends at %d, want %d", state.PhaseEndsAt, want)
+	}
+
+	// the day has no policy and keeps the configured timeout
+	virtual.Advance(night)
+	eng.ProcessPending()
+	if want := start.Add(night + cfg.PhaseDayTimeout).UnixMilli(); state.Phase != domain.PhaseDay || state.PhaseEndsAt != want {
+		t.Errorf("day (%s) ends at %d, want %d", state.Phase, state.PhaseEndsAt, want)
+	}
+}
+
+// A busy chat pushes the running phase's timeout back, up to the policy's max.
+func TestEngine_BusyChatExtendsPhase(t *testing.T) {
+	cfg := testConfig(t)
+	ruleset, err := domain.ParseRuleset([]byte(`{"name":"chatty","durations":{"day":{"base":"60s","chat_per_player":1,"chat_extension":"30s","max":"80s"}}}`))
+	if err != nil {
+		t.Fatal(err)
+	}
+	virtual := clock.NewVirtual(time.Unix(1000, 0))
+	producer := &fakeProducer{}
+	state := domain.NewGameState("test")
+	eng, err := NewEngine(state, producer, cfg, WithClock(virtual), WithRuleset(ruleset))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer eng.Stop()
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatal(err)
+		}
+		eng.ProcessPending()
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	eng.ProcessPending()
+	virtual.Advance(cfg.PhaseNightTimeout)
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseDay || state.Round != 1 {
+		t.Fatalf("expected day 1, got %s %d", state.Phase, state.Round)
+	}
+	dayStart := virtual.Now()
+
+	chat := func() {
+		t.Helper()
+		for _, player := range state.GetAlivePlayers() {
+			eng.cmdCh <- &ChatCommand{SenderID: player.ID, Message: "hi"}
+			eng.ProcessPending()
+		}
+	}
+	extensions := func() (found []*events.PhaseExtended) {
+		t.Helper()
+		for _, msg := range producer.messages {
+			ev, err := events.DeserializeEngineEvent(msg.Value)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if extended, ok := ev.(*events.PhaseExtended); ok {
+				found = append(found, extended)
+			}
+		}
+		return found
+	}
+
+	// one message per alive player: 30s more, cut to the 80s maximum
+	chat()
+	endsAt := dayStart.Add(80 * time.Second).UnixMilli()
+	if state.PhaseEndsAt != endsAt {
+		t.Fatalf("day ends at %d, want %d", state.PhaseEndsAt, endsAt)
+	}
+	if found := extensions(); len(found) != 1 || found[0].EndsAt != endsAt || found[0].Extension != 20000 {
+		t.Fatalf("expected one 20s extension, got %+v", found)
+	}
+
+	// at the maximum, more chat changes nothing
+	chat()
+	if len(extensions()) != 1 || state.PhaseEndsAt != endsAt {
+		t.Errorf("extended past the maximum to %d", state.PhaseEndsAt)
+	}
+
+	// a timeout queued for the old deadline is dropped
+	queued := &PhaseChangeCommand{NewPhase: domain.PhaseVoting, Timeout: &PhaseTimeout{
+		Phase: domain.PhaseDay, Round: 1, EndsAt: dayStart.Add(60 * time.Second).UnixMilli(),
+	}}
+	eng.cmdCh <- queued
+	eng.cmdCh <- &PhaseWarningCommand{PhaseTimeout: *queued.Timeout, Remaining: 10 * time.Second}
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseDay {
+		t.Fatalf("a stale timeout ended the day")
+	}
+
+	// the old timeout no longer fires, the new one does
+	virtual.Advance(60 * time.Second)
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseDay {
+		t.Fatalf("the day ended at its original timeout")
+	}
+	virtual.Advance(20 * time.Second)
+	eng.ProcessPending()
+	if state.Phase != domain.PhaseVoting {
+		t.Errorf("expected voting after the extended day, got %s", state.Phase)
+	}
+}
@@ -61,6 +61,33 @@ func UnmarshalPlayerThoughts(data []byte) (*PlayerThoughts, error) {
 	return &event, nil
 }
 
+func UnmarshalResyncRequested(data []byte) (*ResyncRequested, error) {
+	var event ResyncRequested
+	err := json.Unmarshal(data, &event)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func UnmarshalStateRequest(data []byte) (*StateRequest, error) {
+	var event StateRequest
+	err := json.Unmarshal(data, &event)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
+func UnmarshalPlayerJoined(data []byte) (*PlayerJoined, error) {
+	var event PlayerJoined
+	err := json.Unmarshal(data, &event)
+	if err != nil {
+		return nil, err
+	}
+	return &event, nil
+}
+
 // Deserialize takes raw JSON bytes and routes to the appropriate unmarshaler
 // based on the "type" field in the JSON. Returns the concrete event struct.
 //
@@ -85,10 +112,74 @@ func Deserialize(data []byte) (any, error) {
 		return UnmarshalNightAction(data)
 	case TypePlayerThoughts:
 		return UnmarshalPlayerThoughts(data)
+	case TypeResyncRequested:
+		return UnmarshalResyncRequested(data)
+	case TypeStateRequest:
+		return UnmarshalStateRequest(data)
+	case TypePlayerJoined:
+		return UnmarshalPlayerJoined(data)
 	// Engine emits these but doesn't consume them - players do
-	case TypeGameStarted, TypePhaseChanged, TypePlayerEliminated, TypeGameEnded, TypeRoleAssigned:
+	case TypeGameStarted, TypePhaseChanged, TypePlayerEliminated, TypeGameEnded, TypeRoleAssigned,
+		TypeResync, TypePlayerState, TypeInvestigation, TypeFactionRevealed,
+		TypeRoster, TypeVoteResult, TypeThoughtLinked, TypeActionRequested, TypeActionStatus,
+		TypePhaseEndingSoon, TypePhaseExtended:
 		return nil, fmt.Errorf("engine does not consume event type: %s", base.Type)
 	default:
 		return nil, fmt.Errorf("unknown event type: %s", base.Type)
 	}
 }
+
+// DeserializeEngineEvent is the counterpart of Deserialize for events the engine
+// emits (engine -> players). It is used by in-process consumers such as bots
+// and tools reading the event stream. Player events are accepted too, since
+// chat messages travel in both directions.
+func DeserializeEngineEvent(data []byte) (any, error) {
+	var base BaseEvent
+	if err := json.Unmarshal(data, &base); err != nil {
+		return nil, fmt.Errorf("failed to parse event type: %w", err)
+	}
+
+	var event Event
+	switch base.Type {
+	case TypeGameStarted:
+		event = &GameStarted{}
+	case TypeRoster:
+		event = &Roster{}
+	case TypeVoteResult:
+		event = &VoteResult{}
+	case TypePhaseChanged:
+		event = &PhaseChanged{}
+	case TypePlayerEliminated:
+		event = &PlayerEliminated{}
+	case TypeGameEnded:
+		event = &GameEnded{}
+	case TypeRoleAssigned:
+		event = &RoleAssigned{}
+	case TypeFactionRevealed:
+		event = &FactionRevealed{}
+	case TypeInvestigation:
+		event = &InvestigationResult{}
+	case TypePlayerState:
+		event = &PlayerState{}
+	case TypeResync:
+		event = &Resync{}
+	case TypeThoughtLinked:
+		event = &ThoughtLinked{}
+	case TypeActionRequested:
+		event = &ActionRequested{}
+	case TypeActionStatus:
+		event = &ActionStatus{}
+	case TypePhaseEndingSoon:
+		event = &PhaseEndingSoon{}
+	case TypePhaseExtended:
+		event = &PhaseExtended{}
+	default:
+		// player -> engine events share the same decoding
+		return Deserialize(data)
+	}
+
+	if err := json.Unmarshal(data, event); err != nil {
+		return nil, err
+	}
+	return event, nil
+}
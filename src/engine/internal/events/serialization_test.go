@@ -1,6 +1,7 @@
 package events
 
 import (
+	"fmt"
 	"testing"
 )
 
@@ -133,3 +134,72 @@ func searchString(s, substr string) bool {
 	}
 	return false
 }
+
+func TestDeserializeResyncRequested(t *testing.T) {
+	data := []byte(`{"game_id":"g1","type":"resync_requested","player_id":"player-2","last_seq":41}`)
+
+	ev, err := Deserialize(data)
+	if err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+
+	req, ok := ev.(*ResyncRequested)
+	if !ok {
+		t.Fatalf("expected *ResyncRequested, got %T", ev)
+	}
+	if req.PlayerID != "player-2" || req.LastSeq != 41 {
+		t.Errorf("unexpected request: %+v", req)
+	}
+}
+
+func TestDeserializePlayerJoined(t *testing.T) {
+	data := []byte(`{"game_id":"g1","type":"player_joined","player_id":"player-2","model_profile":"llama3.2:1b"}`)
+
+	ev, err := Deserialize(data)
+	if err != nil {
+		t.Fatalf("Deserialize failed: %v", err)
+	}
+
+	joined, ok := ev.(*PlayerJoined)
+	if !ok {
+		t.Fatalf("expected *PlayerJoined, got %T", ev)
+	}
+	if joined.PlayerID != "player-2" || joined.ModelProfile != "llama3.2:1b" {
+		t.Errorf("unexpected event: %+v", joined)
+	}
+}
+
+func TestDeserializeEngineEvent(t *testing.T) {
+	tests := []struct {
+		name string
+		data string
+		want string
+	}{
+		{"phase changed", `{"type":"phase_changed","round":2,"old_phase":"night","new_phase":"day"}`, "*events.PhaseChanged"},
+		{"role assigned", `{"type":"role_assigned","player_id":"p1","role":"mafia"}`, "*events.RoleAssigned"},
+		{"roster", `{"type":"roster","players":[{"id":"p1","name":"A","seat":1,"alive":true}]}`, "*events.Roster"},
+		{"vote result", `{"type":"vote_result","votes":[{"voter":"p1","target":"p2"}],"eliminated":"p2"}`, "*events.VoteResult"},
+		{"chat both ways", `{"type":"all_chat","sender":"p1","message":"hi"}`, "*events.AllChatMessage"},
+		{"thought linked", `{"type":"thought_linked","player_id":"p1","thought_ids":[1],"action":"vote","target":"p2"}`, "*events.ThoughtLinked"},
+		{"action requested", `{"type":"action_requested","player_id":"p1","actions":[{"type":"vote","targets":["p2"],"required":true}],"deadline":5000}`, "*events.ActionRequested"},
+		{"phase ending soon", `{"type":"phase_ending_soon","phase":"day","ends_at":5000,"remaining":10000}`, "*events.PhaseEndingSoon"},
+		{"phase extended", `{"type":"phase_extended","phase":"day","ends_at":65000,"extension":60000}`, "*events.PhaseExtended"},
+		{"action status", `{"type":"action_status","waiting":1,"players":["p1"]}`, "*events.ActionStatus"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			ev, err := DeserializeEngineEvent([]byte(tt.data))
+			if err != nil {
+				t.Fatalf("unexpected error: %v", err)
+			}
+			if got := fmt.Sprintf("%T", ev); got != tt.want {
+				t.Errorf("got %s, want %s", got, tt.want)
+			}
+		})
+	}
+
+	if _, err := DeserializeEngineEvent([]byte(`{"type":"nope"}`)); err == nil {
+		t.Error("expected error for unknown type")
+	}
+}
@@ -1,5 +1,7 @@
 package events
 
+import "encoding/json"
+
 // Event type constants - stable contract strings used for serialization routing.
 // These must match what the Python players and other services emit.
 const (
@@ -13,6 +15,20 @@ const (
 	TypeVoteSubmitted    = "vote_submitted"
 	TypeNightAction      = "night_action"
 	TypeRoleAssigned     = "role_assigned"
+	TypeResyncRequested  = "resync_requested"
+	TypeResync           = "resync"
+	TypeStateRequest     = "state_request"
+	TypePlayerState      = "player_state"
+	TypeInvestigation    = "investigation_result"
+	TypeFactionRevealed  = "faction_revealed"
+	TypeRoster           = "roster"
+	TypeVoteResult       = "vote_result"
+	TypePlayerJoined     = "player_joined"
+	TypeThoughtLinked    = "thought_linked"
+	TypeActionRequested  = "action_requested"
+	TypeActionStatus     = "action_status"
+	TypePhaseEndingSoon  = "phase_ending_soon"
+	TypePhaseExtended    = "phase_extended"
 )
 
 // base data for all events, embedded in all other structs
@@ -20,23 +36,126 @@ const (
 // BaseEvent is the common header for all events.
 // Timestamp is Unix time in milliseconds (int64).
 // Type is a stable event type string (not runtime-configurable).
+// Seq, Round and Phase are stamped by the engine on every event it emits;
+// they stay empty on events produced by players.
 type BaseEvent struct {
 	GameID    string `json:"game_id"`
 	Timestamp int64  `json:"timestamp"` // Unix ms
 	Type      string `json:"type"`      // stable contract string
+
+	// Seq is a per-game, monotonically increasing sequence number (starts at 1).
+	// A jump of more than one between two received events means events were missed.
+	Seq   int64  `json:"seq,omitempty"`
+	Round int    `json:"round,omitempty"`
+	Phase string `json:"phase,omitempty"`
+}
+
+// Header returns the embedded BaseEvent.
+// Promoted to every event struct, it lets the engine read and stamp
+// common fields without a type switch per event.
+func (b *BaseEvent) Header() *BaseEvent {
+	return b
+}
+
+// Event is implemented by every struct embedding BaseEvent (via pointer).
+type Event interface {
+	Header() *BaseEvent
 }
 
 // engine -> players events
+
+// GameStarted lists player IDs in seat order; see Roster for names.
 type GameStarted struct {
 	BaseEvent
 	Players []string `json:"players"`
+
+	// some roles were pinned to seats instead of dealt (which ones is revealed in GameEnded)
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+// Roster is the public player list, emitted at game start and after every elimination.
+type Roster struct {
+	BaseEvent
+	NamePack string        `json:"name_pack,omitempty"`
+	Players  []RosterEntry `json:"players"` // seat order
 }
 
+// RosterEntry is the public view of one player (never includes the role).
+type RosterEntry struct {
+	ID      string            `json:"id"`
+	Name    string            `json:"name"`
+	Seat    int               `json:"seat"`
+	Alive   bool              `json:"alive"`
+	Persona map[string]string `json:"persona,omitempty"`
+}
+
+// PhaseChanged carries the new round in BaseEvent.Round, and when the new
+// phase times out (both empty if it has no timeout, e.g. once the game ended).
 type PhaseChanged struct {
 	BaseEvent
-	Round    int    `json:"round"`
 	OldPhase string `json:"old_phase"`
 	NewPhase string `json:"new_phase"`
+	EndsAt   int64  `json:"ends_at,omitempty"`  // Unix ms
+	Duration int64  `json:"duration,omitempty"` // ms
+}
+
+// PhaseEndingSoon warns that the current phase times out in Remaining ms,
+// at each of the engine's configured offsets (ENGINE_PHASE_WARNINGS).
+type PhaseEndingSoon struct {
+	BaseEvent
+	EndsAt    int64 `json:"ends_at"`   // Unix ms
+	Remaining int64 `json:"remaining"` // ms
+}
+
+// PhaseExtended moves the current phase's timeout Extension ms later, to
+// EndsAt, because the chat is busy (see domain.DurationPolicy.Extension).
+type PhaseExtended struct {
+	BaseEvent
+	EndsAt    int64 `json:"ends_at"`   // Unix ms
+	Extension int64 `json:"extension"` // ms
+}
+
+// VoteResult is the ballot list of a voting phase, emitted as it closes
+// (before the PhaseChanged that ends it). Observers only: votes are secret,
+// players learn the outcome from PlayerEliminated.
+type VoteResult struct {
+	BaseEvent
+	Votes      []Ballot `json:"votes"`                // in the voters' seat order
+	Eliminated string   `json:"eliminated,omitempty"` // empty on a tie or without votes
+}
+
+type Ballot struct {
+	VoterID  string `json:"voter"`
+	TargetID string `json:"target"`
+}
+
+// Private - engine -> one player at the start of a phase: the actions the
+// engine accepts from the player in it, and until when. Players with nothing
+// to do in the phase (e.g. villagers at night) get none.
+type ActionRequested struct {
+	BaseEvent
+	PlayerID string            `json:"player_id"`
+	Actions  []RequestedAction `json:"actions"`
+	Deadline int64             `json:"deadline,omitempty"` // Unix ms, 0 if the phase has no timeout
+}
+
+// RequestedAction is one action a player may take.
+type RequestedAction struct {
+	Type    string   `json:"type"`              // one of the Action constants
+	Role    string   `json:"role,omitempty"`    // night actions: the role to act as
+	Targets []string `json:"targets,omitempty"` // votes and night actions, seat order
+
+	// the phase waits on it (see ActionStatus); optional actions such as chat are not
+	Required bool `json:"required,omitempty"`
+}
+
+// ActionStatus is how many players the phase still waits on, published as
+// the phase opens and after every required action. Night statuses go to
+// observers only: who is still acting at night would give roles away.
+type ActionStatus struct {
+	BaseEvent
+	Waiting int      `json:"waiting"`
+	Players []string `json:"players,omitempty"` // who, seat order
 }
 
 type PlayerEliminated struct {
@@ -45,48 +164,207 @@ type PlayerEliminated struct {
 	Reason   string `json:"reason"`
 }
 
+// GameEnded reveals every player's role and trait, and what happened at
+// night, once the game is over.
 type GameEnded struct {
 	BaseEvent
-	Winner string `json:"winner"`
+	Winner  string         `json:"winner"`
+	Players []PlayerReveal `json:"players,omitempty"` // seat order
+	Nights  []NightReveal  `json:"nights,omitempty"`  // round order
+}
+
+type PlayerReveal struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Seat  int    `json:"seat"`
+	Role  string `json:"role"`
+	Trait string `json:"trait,omitempty"`
+	Alive bool   `json:"alive"`
+
+	// role was pinned to the seat at game start
+	Pinned bool `json:"pinned,omitempty"`
+}
+
+// NightReveal is one night's secret actions. Targets are empty if nobody acted.
+type NightReveal struct {
+	Round         int    `json:"round"`
+	MafiaTarget   string `json:"mafia_target,omitempty"`
+	DoctorTarget  string `json:"doctor_target,omitempty"`
+	SheriffTarget string `json:"sheriff_target,omitempty"`
+	Killed        string `json:"killed,omitempty"`
 }
 
 // players -> players + engine events
 type AllChatMessage struct {
 	BaseEvent
-	Message  string `json:"message"`
-	SenderID string `json:"sender"`
+	Message   string     `json:"message"`
+	SenderID  string     `json:"sender"`
+	Telemetry *Telemetry `json:"telemetry,omitempty"` // inbound only, never re-published
 }
 
 type MafiaChatMessage struct {
 	BaseEvent
-	Message  string `json:"message"`
-	SenderID string `json:"sender"`
+	Message   string     `json:"message"`
+	SenderID  string     `json:"sender"`
+	Telemetry *Telemetry `json:"telemetry,omitempty"` // inbound only, never re-published
+}
+
+// Telemetry is optional usage data a player agent attaches to an action:
+// the model that produced it, its token counts and how long generation took.
+// The engine aggregates it (see package telemetry) and never forwards it.
+type Telemetry struct {
+	Model            string `json:"model,omitempty"`
+	PromptTokens     int64  `json:"prompt_tokens,omitempty"`
+	CompletionTokens int64  `json:"completion_tokens,omitempty"`
+	LatencyMs        int64  `json:"latency_ms,omitempty"` // generation time
 }
 
 // players -> engine events
+// The engine keeps thoughts and re-publishes them to observers only
+// (kafka.ObserverEventsTopic), numbered and after redaction and truncation.
 type PlayerThoughts struct {
 	BaseEvent
 	Thought  string `json:"thought"`
 	SenderID string `json:"sender"`
+
+	// set by the engine when re-publishing
+	ThoughtID  int64 `json:"thought_id,omitempty"`
+	Redactions int   `json:"redactions,omitempty"` // patterns replaced
+	Truncated  bool  `json:"truncated,omitempty"`  // cut to ENGINE_THOUGHT_MAX_BYTES
+}
+
+// Actions a thought can be linked to
+const (
+	ActionVote        = "vote"
+	ActionNightAction = "night_action"
+	ActionChat        = "chat"
+	ActionMafiaChat   = "mafia_chat"
+)
+
+// Observers only - engine -> observers: the action a player took after the
+// listed thoughts, so a transcript can show why next to what.
+type ThoughtLinked struct {
+	BaseEvent
+	PlayerID   string  `json:"player_id"`
+	ThoughtIDs []int64 `json:"thought_ids"`
+	Action     string  `json:"action"`           // one of the Action constants
+	TargetID   string  `json:"target,omitempty"` // votes and night actions
 }
 
 type VoteSubmitted struct {
 	BaseEvent
-	VoterID  string `json:"voter"`
-	TargetID string `json:"target"`
+	VoterID   string     `json:"voter"`
+	TargetID  string     `json:"target"`
+	Telemetry *Telemetry `json:"telemetry,omitempty"`
 }
 
 type NightAction struct {
 	BaseEvent
 	// mafia, sheriff, doctor
-	Role     string `json:"role"`
-	ActorID  string `json:"actor"`
-	TargetID string `json:"target"`
+	Role      string     `json:"role"`
+	ActorID   string     `json:"actor"`
+	TargetID  string     `json:"target"`
+	Telemetry *Telemetry `json:"telemetry,omitempty"`
 }
 
 // Private - sent per-player
+// Trait is the player's personality (playstyle prompt), empty if traits are disabled.
 type RoleAssigned struct {
+	BaseEvent
+	PlayerID         string `json:"player_id"`
+	Role             string `json:"role"`
+	Trait            string `json:"trait,omitempty"`
+	TraitDescription string `json:"trait_description,omitempty"`
+}
+
+// players -> engine: ask for everything emitted after LastSeq.
+// Sent by a player agent after a restart or when it detects a seq gap.
+type ResyncRequested struct {
 	BaseEvent
 	PlayerID string `json:"player_id"`
-	Role     string `json:"role"`
+	LastSeq  int64  `json:"last_seq"`
+}
+
+// Private - engine -> one player, answer to ResyncRequested.
+// Events holds the missed events visible to the player, in seq order.
+// When the missed range is no longer retained, Events is empty and
+// Snapshot describes the current public game state instead.
+type Resync struct {
+	BaseEvent
+	PlayerID string            `json:"player_id"`
+	FromSeq  int64             `json:"from_seq"`
+	ToSeq    int64             `json:"to_seq"`
+	Events   []json.RawMessage `json:"events,omitempty"`
+	Snapshot *GameSnapshot     `json:"snapshot,omitempty"`
+}
+
+// GameSnapshot is the public view of a game at a given seq.
+type GameSnapshot struct {
+	Round   int           `json:"round"`
+	Phase   string        `json:"phase"`
+	Winner  string        `json:"winner"`
+	Players []RosterEntry `json:"players"`
+}
+
+// Private - sent per-player, for factions whose members know each other (e.g. mafia).
+// Members lists the recipient's faction mates, excluding the recipient.
+type FactionRevealed struct {
+	BaseEvent
+	PlayerID string          `json:"player_id"`
+	Faction  string          `json:"faction"`
+	Members  []FactionMember `json:"members"`
+}
+
+// Private - engine -> sheriff, the outcome of a night investigation.
+type InvestigationResult struct {
+	BaseEvent
+	PlayerID string `json:"player_id"` // the sheriff
+	TargetID string `json:"target"`
+	Faction  string `json:"faction"` // faction of the target
+}
+
+// players -> engine: ask for this player's own knowledge view.
+// Sent by a player agent after a restart to rebuild its private context.
+type StateRequest struct {
+	BaseEvent
+	PlayerID string `json:"player_id"`
+}
+
+// players -> engine: a player agent announces itself when it connects, with
+// the model profile behind it (e.g. "llama3.2:3b"). The profile is kept for
+// analysis (archive, ratings) and never shown to other players.
+type PlayerJoined struct {
+	BaseEvent
+	PlayerID     string `json:"player_id"`
+	ModelProfile string `json:"model_profile"`
+}
+
+// Private - engine -> one player, answer to StateRequest.
+// Holds exactly what the player learned from earlier events, nothing more.
+type PlayerState struct {
+	BaseEvent
+	PlayerID     string                `json:"player_id"`
+	Name         string                `json:"name"`
+	Role         string                `json:"role"`
+	Faction      string                `json:"faction"`
+	Trait        string                `json:"trait,omitempty"`
+	Alive        bool                  `json:"alive"`
+	FactionMates []FactionMember       `json:"faction_mates,omitempty"`
+	Results      []InvestigationRecord `json:"results,omitempty"`
+	Players      []RosterEntry         `json:"players"`
+	PhaseEndsAt  int64                 `json:"phase_ends_at,omitempty"` // Unix ms, 0 if no timeout
+	LastSeq      int64                 `json:"last_seq"`
+}
+
+type FactionMember struct {
+	ID    string `json:"id"`
+	Name  string `json:"name"`
+	Role  string `json:"role"`
+	Alive bool   `json:"alive"`
+}
+
+type InvestigationRecord struct {
+	Round    int    `json:"round"`
+	TargetID string `json:"target"`
+	Faction  string `json:"faction"`
 }
@@ -0,0 +1,97 @@
+package events
+
+// Visibility rules decide which viewers are allowed to see an event.
+// Every consumer that re-delivers events (resync, transcripts, exports)
+// must go through CanSee so hidden information never leaks.
+
+// Faction names as they appear on the wire.
+const (
+	FactionVillage = "village"
+	FactionMafia   = "mafia"
+)
+
+// PhaseNight is the night phase as it appears on the wire.
+const PhaseNight = "night"
+
+// Scope is the widest group an event may be shown to.
+type Scope int
+
+const (
+	ScopePublic    Scope = iota // every player
+	ScopeFaction                // members of one faction (e.g. mafia chat)
+	ScopePlayer                 // a single player (e.g. role assignment)
+	ScopeObservers              // nobody in the game, observers only
+)
+
+// Audience is the resolved set of viewers for one event.
+type Audience struct {
+	Scope    Scope
+	PlayerID string // set for ScopePlayer
+	Faction  string // set for ScopeFaction
+}
+
+// Viewer describes who is looking at the event stream.
+// Omniscient viewers (observers, debugging tools) see everything.
+type Viewer struct {
+	PlayerID   string
+	Faction    string
+	Omniscient bool
+}
+
+// AudienceOf returns who may see the given event.
+// Unknown event types default to public.
+func AudienceOf(event any) Audience {
+	switch e := event.(type) {
+	case *RoleAssigned:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *Resync:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *FactionRevealed:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *PlayerState:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *InvestigationResult:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *StateRequest:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *ResyncRequested:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *MafiaChatMessage:
+		return Audience{Scope: ScopeFaction, Faction: FactionMafia}
+	case *NightAction:
+		return Audience{Scope: ScopePlayer, PlayerID: e.ActorID}
+	case *VoteSubmitted:
+		return Audience{Scope: ScopePlayer, PlayerID: e.VoterID}
+	case *ActionRequested:
+		return Audience{Scope: ScopePlayer, PlayerID: e.PlayerID}
+	case *ActionStatus:
+		// who still has to act at night is for observers only
+		if e.Phase == PhaseNight {
+			return Audience{Scope: ScopeObservers}
+		}
+		return Audience{Scope: ScopePublic}
+	case *PlayerThoughts, *ThoughtLinked, *VoteResult:
+		return Audience{Scope: ScopeObservers}
+	default:
+		return Audience{Scope: ScopePublic}
+	}
+}
+
+// CanSee reports whether the viewer is allowed to see the event.
+func (v Viewer) CanSee(event any) bool {
+	if v.Omniscient {
+		return true
+	}
+
+	audience := AudienceOf(event)
+	switch audience.Scope {
+	case ScopePublic:
+		return true
+	case ScopeFaction:
+		return v.Faction != "" && v.Faction == audience.Faction
+	case ScopePlayer:
+		return v.PlayerID != "" && v.PlayerID == audience.PlayerID
+	default:
+		return false
+	}
+}
@@ -0,0 +1,51 @@
+package events
+
+import "testing"
+
+func TestViewerCanSee(t *testing.T) {
+	role := &RoleAssigned{PlayerID: "p1"}
+	mafiaChat := &MafiaChatMessage{SenderID: "m1"}
+	allChat := &AllChatMessage{SenderID: "p2"}
+	thoughts := &PlayerThoughts{SenderID: "p1"}
+	linked := &ThoughtLinked{PlayerID: "p1"}
+	request := &ActionRequested{PlayerID: "p1"}
+	votingStatus := &ActionStatus{BaseEvent: BaseEvent{Phase: "voting"}, Waiting: 2}
+	nightStatus := &ActionStatus{BaseEvent: BaseEvent{Phase: PhaseNight}, Waiting: 2}
+	ballots := &VoteResult{Votes: []Ballot{{VoterID: "p1", TargetID: "m1"}}}
+
+	villager := Viewer{PlayerID: "p1", Faction: FactionVillage}
+	mafia := Viewer{PlayerID: "m1", Faction: FactionMafia}
+	observer := Viewer{Omniscient: true}
+
+	tests := []struct {
+		name   string
+		viewer Viewer
+		event  any
+		want   bool
+	}{
+		{"own role", villager, role, true},
+		{"other role", mafia, role, false},
+		{"public chat", villager, allChat, true},
+		{"mafia chat to mafia", mafia, mafiaChat, true},
+		{"mafia chat to villager", villager, mafiaChat, false},
+		{"thoughts to own player", villager, thoughts, false},
+		{"thoughts to observer", observer, thoughts, true},
+		{"thought link to own player", villager, linked, false},
+		{"thought link to observer", observer, linked, true},
+		{"own action request", villager, request, true},
+		{"other action request", mafia, request, false},
+		{"voting status", villager, votingStatus, true},
+		{"night status to mafia", mafia, nightStatus, false},
+		{"night status to observer", observer, nightStatus, true},
+		{"ballots to voter", villager, ballots, false},
+		{"ballots to observer", observer, ballots, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			if got := tt.viewer.CanSee(tt.event); got != tt.want {
+				t.Errorf("CanSee = %v, want %v", got, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,395 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"hash/fnv"
+	"sync"
+)
+
+// ErrBrokerClosed is returned by in-memory producers and consumers after the broker was closed.
+var ErrBrokerClosed = errors.New("memory broker closed")
+
+// DefaultMemoryPartitions is the partition count used for topics on an in-memory broker.
+const DefaultMemoryPartitions = 3
+
+// MemoryBroker is an in-process stand-in for a Kafka cluster.
+// It keeps the semantics the engine relies on:
+//   - topics are created on first use and split into partitions
+//   - messages with the same key land in the same partition (ordering per game)
+//   - every consumer group sees every message (fan-out between groups)
+//   - consumers in the same group share the partitions (work split inside a group)
+//   - each group tracks a committed offset per partition; a consumer that joins
+//     later resumes from there, like a restarted Kafka consumer
+//
+// Nothing is persisted: the log lives as long as the broker.
+type MemoryBroker struct {
+	mu         sync.Mutex
+	partitions int
+	topics     map[string]*memoryTopic
+	groups     map[groupKey]*memoryGroup
+	closed     bool
+
+	// wake is closed (and replaced) whenever something changes,
+	// waking every consumer blocked in Consume
+	wake chan struct{}
+
+	anonymous int // counter for consumers without a group
+}
+
+type memoryTopic struct {
+	partitions [][]Message
+}
+
+type groupKey struct {
+	topic string
+	group string
+}
+
+type memoryGroup struct {
+	committed []int64 // next offset to deliver after a restart, per partition
+	position  []int64 // next offset to fetch, per partition
+	members   []*MemoryConsumer
+}
+
+// NewMemoryBroker creates an empty broker. partitions <= 0 uses DefaultMemoryPartitions.
+func NewMemoryBroker(partitions int) *MemoryBroker {
+	if partitions <= 0 {
+		partitions = DefaultMemoryPartitions
+	}
+	return &MemoryBroker{
+		partitions: partitions,
+		topics:     make(map[string]*memoryTopic),
+		groups:     make(map[groupKey]*memoryGroup),
+		wake:       make(chan struct{}),
+	}
+}
+
+// Close stops all consumers and rejects further publishes.
+func (b *MemoryBroker) Close() error {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if !b.closed {
+		b.closed = true
+		b.notifyLocked()
+	}
+	return nil
+}
+
+// Messages returns a copy of everything published to a topic, partition by partition.
+// Useful for tests that want to inspect the log without joining a group.
+func (b *MemoryBroker) Messages(topic string) []Message {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	t, ok := b.topics[topic]
+	if !ok {
+		return nil
+	}
+	var out []Message
+	for _, partition := range t.partitions {
+		out = append(out, partition...)
+	}
+	return out
+}
+
+// Committed returns the committed offset of a group for one partition (0 if unknown).
+func (b *MemoryBroker) Committed(topic, group string, partition int) int64 {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[groupKey{topic: topic, group: group}]
+	if !ok || partition < 0 || partition >= len(g.committed) {
+		return 0
+	}
+	return g.committed[partition]
+}
+
+func (b *MemoryBroker) publish(msg Message) error {
+	if msg.Topic == "" {
+		return errors.New("message topic is required")
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return ErrBrokerClosed
+	}
+
+	t := b.topicLocked(msg.Topic)
+	p := b.partitionFor(msg.Key)
+	t.partitions[p] = append(t.partitions[p], Message{
+		Topic: msg.Topic,
+		Key:   append([]byte(nil), msg.Key...),
+		Value: append([]byte(nil), msg.Value...),
+	})
+	b.notifyLocked()
+	return nil
+}
+
+// partitionFor hashes the key like the Kafka producer's Hash balancer does:
+// equal keys always map to the same partition. Messages without key go to partition 0.
+func (b *MemoryBroker) partitionFor(key []byte) int {
+	if len(key) == 0 {
+		return 0
+	}
+	h := fnv.New32a()
+	_, _ = h.Write(key)
+	return int(h.Sum32() % uint32(b.partitions))
+}
+
+func (b *MemoryBroker) topicLocked(name string) *memoryTopic {
+	t, ok := b.topics[name]
+	if !ok {
+		t = &memoryTopic{partitions: make([][]Message, b.partitions)}
+		b.topics[name] = t
+	}
+	return t
+}
+
+func (b *MemoryBroker) groupLocked(key groupKey) *memoryGroup {
+	g, ok := b.groups[key]
+	if !ok {
+		g = &memoryGroup{
+			committed: make([]int64, b.partitions),
+			position:  make([]int64, b.partitions),
+		}
+		b.groups[key] = g
+	}
+	return g
+}
+
+// join adds a consumer to its group. Uncommitted positions are rewound to the
+// committed offsets, as Kafka does on a rebalance.
+func (b *MemoryBroker) join(c *MemoryConsumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g := b.groupLocked(c.key)
+	g.members = append(g.members, c)
+	copy(g.position, g.committed)
+	b.notifyLocked()
+}
+
+func (b *MemoryBroker) leave(c *MemoryConsumer) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g, ok := b.groups[c.key]
+	if !ok {
+		return
+	}
+	for i, member := range g.members {
+		if member == c {
+			g.members = append(g.members[:i], g.members[i+1:]...)
+			copy(g.position, g.committed)
+			b.notifyLocked()
+			return
+		}
+	}
+}
+
+// fetch returns the next message for a consumer from the partitions it owns.
+// Partitions are assigned round-robin over the group's members in join order.
+// ok is false if nothing is available; wake is the channel to wait on in that case.
+func (b *MemoryBroker) fetch(c *MemoryConsumer) (msg Message, partition int, offset int64, ok bool, wake <-chan struct{}, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if b.closed {
+		return Message{}, 0, 0, false, nil, ErrBrokerClosed
+	}
+
+	g := b.groupLocked(c.key)
+	index := -1
+	for i, member := range g.members {
+		if member == c {
+			index = i
+			break
+		}
+	}
+	t := b.topicLocked(c.key.topic)
+
+	if index >= 0 {
+		for p := index; p < b.partitions; p += len(g.members) {
+			if g.position[p] < int64(len(t.partitions[p])) {
+				offset = g.position[p]
+				g.position[p]++
+				return t.partitions[p][offset], p, offset, true, nil, nil
+			}
+		}
+	}
+	return Message{}, 0, 0, false, b.wake, nil
+}
+
+func (b *MemoryBroker) commit(key groupKey, partition int, offset int64) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	g := b.groupLocked(key)
+	if offset+1 > g.committed[partition] {
+		g.committed[partition] = offset + 1
+	}
+}
+
+func (b *MemoryBroker) notifyLocked() {
+	close(b.wake)
+	b.wake = make(chan struct{})
+}
+
+// MemoryProducer publishes to a MemoryBroker.
+type MemoryProducer struct {
+	broker *MemoryBroker
+}
+
+// NewMemoryProducer creates a producer for the given broker.
+func NewMemoryProducer(broker *MemoryBroker) (*MemoryProducer, error) {
+	if broker == nil {
+		return nil, errors.New("broker is required")
+	}
+	return &MemoryProducer{broker: broker}, nil
+}
+
+// Publish appends the message to its topic. It never blocks.
+func (p *MemoryProducer) Publish(ctx context.Context, msg Message) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+	return p.broker.publish(msg)
+}
+
+// Close is a no-op; the broker owns the log.
+func (p *MemoryProducer) Close() error {
+	return nil
+}
+
+// MemoryConsumer reads one topic of a MemoryBroker as a member of a consumer group.
+type MemoryConsumer struct {
+	broker *MemoryBroker
+	key    groupKey
+
+	mu     sync.Mutex
+	closed bool
+	joined bool // joined through Poll
+	done   chan struct{}
+}
+
+// NewMemoryConsumer creates a consumer for topic in group groupID.
+// An empty groupID gives the consumer a private group that starts at the beginning of the topic.
+func NewMemoryConsumer(broker *MemoryBroker, topic string, groupID string) (*MemoryConsumer, error) {
+	if broker == nil {
+		return nil, errors.New("broker is required")
+	}
+	if topic == "" {
+		return nil, fmt.Errorf("topic is required")
+	}
+
+	if groupID == "" {
+		broker.mu.Lock()
+		broker.anonymous++
+		groupID = fmt.Sprintf("anonymous-%d", broker.anonymous)
+		broker.mu.Unlock()
+	}
+
+	return &MemoryConsumer{
+		broker: broker,
+		key:    groupKey{topic: topic, group: groupID},
+		done:   make(chan struct{}),
+	}, nil
+}
+
+// Consume joins the group and delivers messages until the context is canceled,
+// the consumer is closed or the broker is closed.
+// Offsets are committed after the handler succeeds; a failed message is skipped
+// and not retried. It is delivered again after a rebalance only while nothing
+// after it in the partition has been committed: once a later message commits,
+// the committed offset moves past it and the failed message is lost.
+func (c *MemoryConsumer) Consume(ctx context.Context, handler HandlerFunc) error {
+	c.broker.join(c)
+	defer c.broker.leave(c)
+
+	for {
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-c.done:
+			return nil
+		default:
+		}
+
+		msg, partition, offset, ok, wake, err := c.broker.fetch(c)
+		if err != nil {
+			return err
+		}
+		if !ok {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-c.done:
+				return nil
+			case <-wake:
+			}
+			continue
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			// Same as the Kafka consumer: don't commit, move on
+			continue
+		}
+		c.broker.commit(c.key, partition, offset)
+	}
+}
+
+// Poll delivers every message available right now on the caller's goroutine
+// and returns how many were handled successfully. It joins the group on first
+// use and never blocks waiting for new messages, which lets a single-threaded
+// driver (e.g. a simulation) interleave several consumers deterministically.
+// Don't mix Poll and Consume on the same consumer.
+func (c *MemoryConsumer) Poll(ctx context.Context, handler HandlerFunc) (int, error) {
+	c.mu.Lock()
+	if c.closed {
+		c.mu.Unlock()
+		return 0, nil
+	}
+	if !c.joined {
+		c.joined = true
+		c.broker.join(c)
+	}
+	c.mu.Unlock()
+
+	handled := 0
+	for {
+		if err := ctx.Err(); err != nil {
+			return handled, err
+		}
+
+		msg, partition, offset, ok, _, err := c.broker.fetch(c)
+		if err != nil || !ok {
+			return handled, err
+		}
+
+		if err := handler(ctx, msg); err != nil {
+			continue
+		}
+		c.broker.commit(c.key, partition, offset)
+		handled++
+	}
+}
+
+// Close stops Consume. It is safe to call more than once.
+func (c *MemoryConsumer) Close() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if !c.closed {
+		c.closed = true
+		close(c.done)
+		if c.joined {
+			c.broker.leave(c)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,292 @@
+package kafka
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"testing"
+	"time"
+)
+
+// collector is a handler that records messages and signals when n have arrived.
+type collector struct {
+	mu       sync.Mutex
+	messages []Message
+	want     int
+	done     chan struct{}
+}
+
+func newCollector(want int) *collector {
+	return &collector{want: want, done: make(chan struct{})}
+}
+
+func (c *collector) handle(_ context.Context, msg Message) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.messages = append(c.messages, msg)
+	if len(c.messages) == c.want {
+		close(c.done)
+	}
+	return nil
+}
+
+func (c *collector) wait(t *testing.T) []Message {
+	t.Helper()
+	select {
+	case <-c.done:
+	case <-time.After(2 * time.Second):
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		t.Fatalf("timed out: got %d of %d messages", len(c.messages), c.want)
+	}
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]Message(nil), c.messages...)
+}
+
+// consume runs a consumer in the background until the test ends.
+func consume(t *testing.T, consumer Consumer, handler HandlerFunc) {
+	t.Helper()
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() { errCh <- consumer.Consume(ctx, handler) }()
+	t.Cleanup(func() {
+		cancel()
+		<-errCh
+	})
+}
+
+func publishN(t *testing.T, producer Producer, topic, key string, n int) {
+	t.Helper()
+	for i := 0; i < n; i++ {
+		msg := Message{Topic: topic, Key: []byte(key), Value: []byte(fmt.Sprintf("%s-%d", key, i))}
+		if err := producer.Publish(context.Background(), msg); err != nil {
+			t.Fatalf("Publish failed: %v", err)
+		}
+	}
+}
+
+func newMemoryPair(t *testing.T, broker *MemoryBroker, topic, group string) (*MemoryProducer, *MemoryConsumer) {
+	t.Helper()
+	producer, err := NewMemoryProducer(broker)
+	if err != nil {
+		t.Fatalf("NewMemoryProducer failed: %v", err)
+	}
+	consumer, err := NewMemoryConsumer(broker, topic, group)
+	if err != nil {
+		t.Fatalf("NewMemoryConsumer failed: %v", err)
+	}
+	return producer, consumer
+}
+
+func TestMemoryBroker_KeyOrdering(t *testing.T) {
+	broker := NewMemoryBroker(4)
+	producer, consumer := newMemoryPair(t, broker, EngineEventsTopic, "g")
+
+	publishN(t, producer, EngineEventsTopic, "game-a", 10)
+	publishN(t, producer, EngineEventsTopic, "game-b", 10)
+
+	c := newCollector(20)
+	consume(t, consumer, c.handle)
+
+	next := map[string]int{}
+	for _, msg := range c.wait(t) {
+		key := string(msg.Key)
+		if want := fmt.Sprintf("%s-%d", key, next[key]); string(msg.Value) != want {
+			t.Fatalf("out of order: got %s, want %s", msg.Value, want)
+		}
+		next[key]++
+	}
+}
+
+func TestMemoryBroker_FanOutBetweenGroups(t *testing.T) {
+	broker := NewMemoryBroker(0)
+	producer, engine := newMemoryPair(t, broker, EngineEventsTopic, "engine")
+	bots, _ := NewMemoryConsumer(broker, EngineEventsTopic, "bots")
+
+	first, second := newCollector(5), newCollector(5)
+	consume(t, engine, first.handle)
+	consume(t, bots, second.handle)
+
+	publishN(t, producer, EngineEventsTopic, "game", 5)
+
+	if got := len(first.wait(t)); got != 5 {
+		t.Errorf("engine group: expected 5 messages, got %d", got)
+	}
+	if got := len(second.wait(t)); got != 5 {
+		t.Errorf("bots group: expected 5 messages, got %d", got)
+	}
+}
+
+func TestMemoryBroker_GroupSplitsWork(t *testing.T) {
+	broker := NewMemoryBroker(2)
+	producer, first := newMemoryPair(t, broker, PlayerActionsTopic, "engine")
+	second, _ := NewMemoryConsumer(broker, PlayerActionsTopic, "engine")
+
+	// find two keys that land in different partitions
+	keys := []string{"k0"}
+	for i := 1; len(keys) < 2; i++ {
+		key := fmt.Sprintf("k%d", i)
+		if broker.partitionFor([]byte(key)) != broker.partitionFor([]byte(keys[0])) {
+			keys = append(keys, key)
+		}
+	}
+
+	var mu sync.Mutex
+	seen := map[string]map[string]bool{} // consumer -> keys
+	all := newCollector(8)
+	handler := func(name string) HandlerFunc {
+		return func(ctx context.Context, msg Message) error {
+			mu.Lock()
+			if seen[name] == nil {
+				seen[name] = map[string]bool{}
+			}
+			seen[name][string(msg.Key)] = true
+			mu.Unlock()
+			return all.handle(ctx, msg)
+		}
+	}
+	consume(t, first, handler("first"))
+	consume(t, second, handler("second"))
+
+	// wait until both members joined before publishing
+	deadline := time.Now().Add(2 * time.Second)
+	for {
+		broker.mu.Lock()
+		members := 0
+		if g := broker.groups[groupKey{topic: PlayerActionsTopic, group: "engine"}]; g != nil {
+			members = len(g.members)
+		}
+		broker.mu.Unlock()
+		if members == 2 {
+			break
+		}
+		if time.Now().After(deadline) {
+			t.Fatal("consumers did not join")
+		}
+		time.Sleep(time.Millisecond)
+	}
+
+	publishN(t, producer, PlayerActionsTopic, keys[0], 4)
+	publishN(t, producer, PlayerActionsTopic, keys[1], 4)
+	all.wait(t)
+
+	mu.Lock()
+	defer mu.Unlock()
+	if len(seen["first"]) != 1 || len(seen["second"]) != 1 {
+		t.Errorf("expected each member to own one key, got %v", seen)
+	}
+}
+
+func TestMemoryBroker_CommittedOffsetsSurviveRestart(t *testing.T) {
+	broker := NewMemoryBroker(1)
+	producer, consumer := newMemoryPair(t, broker, PlayerActionsTopic, "engine")
+	publishN(t, producer, PlayerActionsTopic, "game", 3)
+
+	// first consumer fails on the last message, so it is not committed
+	c := newCollector(3)
+	ctx, cancel := context.WithCancel(context.Background())
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- consumer.Consume(ctx, func(ctx context.Context, msg Message) error {
+			_ = c.handle(ctx, msg)
+			if string(msg.Value) == "game-2" {
+				return errors.New("boom")
+			}
+			return nil
+		})
+	}()
+	c.wait(t)
+	cancel()
+	<-errCh
+
+	if got := broker.Committed(PlayerActionsTopic, "engine", 0); got != 2 {
+		t.Fatalf("expected committed offset 2, got %d", got)
+	}
+
+	// a restarted member of the same group resumes at the failed message
+	restarted, _ := NewMemoryConsumer(broker, PlayerActionsTopic, "engine")
+	again := newCollector(1)
+	consume(t, restarted, again.handle)
+	if got := again.wait(t); string(got[0].Value) != "game-2" {
+		t.Errorf("expected redelivery of game-2, got %s", got[0].Value)
+	}
+}
+
+func TestMemoryBroker_Close(t *testing.T) {
+	broker := NewMemoryBroker(1)
+	producer, consumer := newMemoryPair(t, broker, EngineEventsTopic, "")
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- consumer.Consume(context.Background(), func(context.Context, Message) error { return nil })
+	}()
+
+	if err := broker.Close(); err != nil {
+		t.Fatalf("Close failed: %v", err)
+	}
+	select {
+	case err := <-errCh:
+		if !errors.Is(err, ErrBrokerClosed) {
+			t.Errorf("expected ErrBrokerClosed, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("consumer did not stop after broker close")
+	}
+
+	if err := producer.Publish(context.Background(), Message{Topic: EngineEventsTopic}); !errors.Is(err, ErrBrokerClosed) {
+		t.Errorf("expected ErrBrokerClosed on publish, got %v", err)
+	}
+}
+
+func TestMemoryConsumer_CloseStopsConsume(t *testing.T) {
+	broker := NewMemoryBroker(1)
+	consumer, _ := NewMemoryConsumer(broker, EngineEventsTopic, "g")
+
+	errCh := make(chan error, 1)
+	go func() {
+		errCh <- consumer.Consume(context.Background(), func(context.Context, Message) error { return nil })
+	}()
+
+	_ = consumer.Close()
+	_ = consumer.Close()
+	select {
+	case err := <-errCh:
+		if err != nil {
+			t.Errorf("expected nil error after Close, got %v", err)
+		}
+	case <-time.After(2 * time.Second):
+		t.Fatal("Consume did not return after Close")
+	}
+}
+
+func TestMemoryConsumer_Poll(t *testing.T) {
+	broker := NewMemoryBroker(2)
+	producer, consumer := newMemoryPair(t, broker, EngineEventsTopic, "sim")
+
+	handled, err := consumer.Poll(context.Background(), func(context.Context, Message) error { return nil })
+	if err != nil || handled != 0 {
+		t.Fatalf("expected nothing to poll, got %d, %v", handled, err)
+	}
+
+	publishN(t, producer, EngineEventsTopic, "game", 3)
+
+	var values []string
+	handled, err = consumer.Poll(context.Background(), func(_ context.Context, msg Message) error {
+		values = append(values, string(msg.Value))
+		return nil
+	})
+	if err != nil || handled != 3 {
+		t.Fatalf("expected 3 messages, got %d, %v", handled, err)
+	}
+	if values[0] != "game-0" || values[2] != "game-2" {
+		t.Errorf("unexpected order: %v", values)
+	}
+
+	// already consumed
+	if handled, _ := consumer.Poll(context.Background(), func(context.Context, Message) error { return nil }); handled != 0 {
+		t.Errorf("expected no redelivery, got %d", handled)
+	}
+}
@@ -10,12 +10,19 @@ const (
 	// PlayerActionsTopic is the stream of player intents
 	// (votes, night actions, thoughts) consumed by the engine.
 	PlayerActionsTopic = "game.player.actions"
+
+	// ObserverEventsTopic is the stream of events for observers only
+	// (player thoughts and what they led to). Players must not consume it.
+	ObserverEventsTopic = "game.observer.events"
 )
 
 // Consumer group names.
 // These identify who is consuming a topic, not what is being consumed.
 const (
 	EngineConsumerGroup = "mafia-engine"
+
+	// BotsConsumerGroup reads engine events for the in-process bots (ENGINE_AGENT_MODE=mock).
+	BotsConsumerGroup = "mafia-engine-bots"
 )
 
 // GameKey returns the Kafka partition key for a given game.
@@ -2,13 +2,15 @@ package names
 
 import (
 	"errors"
+	"math/rand/v2"
 	"sync"
 )
 
 // ErrNoMoreNames is returned when all available names have been used.
 var ErrNoMoreNames = errors.New("no more names available")
 
-// Generator assigns names to players sequentially from a provided list.
+// Generator assigns names to players sequentially from a provided list
+// (or from a shuffled copy, see NewShuffledGenerator).
 // It is thread-safe and tracks which names have been used.
 type Generator struct {
 	names   []string
@@ -28,6 +30,27 @@ func NewGenerator(names []string) (*Generator, error) {
 	}, nil
 }
 
+// NewShuffledGenerator creates a generator that draws names in a random order
+// without replacement. The order only depends on rng, so a seeded rng gives
+// the same cast in the same seats. The input slice is not modified.
+func NewShuffledGenerator(names []string, rng *rand.Rand) (*Generator, error) {
+	if len(names) == 0 {
+		return nil, errors.New("names list must not be empty")
+	}
+	if rng == nil {
+		return nil, errors.New("rng must not be nil")
+	}
+
+	shuffled := append([]string(nil), names...)
+	rng.Shuffle(len(shuffled), func(i, j int) {
+		shuffled[i], shuffled[j] = shuffled[j], shuffled[i]
+	})
+
+	return &Generator{
+		names: shuffled,
+	}, nil
+}
+
 // Next returns the next available name.
 // Returns ErrNoMoreNames if all names have been used.
 // Thread-safe: uses mutex to protect counter.
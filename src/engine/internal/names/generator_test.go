@@ -1,6 +1,7 @@
 package names
 
 import (
+	"math/rand/v2"
 	"testing"
 )
 
@@ -46,6 +47,48 @@ func TestNext(t *testing.T) {
 	}
 }
 
+func TestNewShuffledGenerator(t *testing.T) {
+	names := []string{"Alice", "Bob", "Charlie", "Dana", "Eve", "Frank"}
+
+	draw := func(seed uint64) []string {
+		gen, err := NewShuffledGenerator(names, rand.New(rand.NewPCG(seed, 1)))
+		if err != nil {
+			t.Fatalf("unexpected error: %v", err)
+		}
+		var drawn []string
+		for {
+			name, err := gen.Next()
+			if err == ErrNoMoreNames {
+				return drawn
+			}
+			drawn = append(drawn, name)
+		}
+	}
+
+	first := draw(42)
+	second := draw(42)
+
+	// without replacement: every name exactly once
+	seen := make(map[string]bool)
+	for i, name := range first {
+		if seen[name] {
+			t.Errorf("name %q drawn twice", name)
+		}
+		seen[name] = true
+		if second[i] != name {
+			t.Errorf("same seed drew %q and %q at %d", name, second[i], i)
+		}
+	}
+	if len(seen) != len(names) {
+		t.Errorf("expected %d names, got %d", len(names), len(seen))
+	}
+
+	// input list is left untouched
+	if names[0] != "Alice" {
+		t.Error("input slice was modified")
+	}
+}
+
 func TestReset(t *testing.T) {
 	names := []string{"Alice", "Bob"}
 	gen, _ := NewGenerator(names)
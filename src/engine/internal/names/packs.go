@@ -0,0 +1,153 @@
+package names
+
+import (
+	"bufio"
+	"bytes"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// ErrUnknownPack is returned when a pack name is not a built-in pack.
+var ErrUnknownPack = errors.New("unknown name pack")
+
+// Pack is a themed list of player names.
+type Pack struct {
+	Name  string
+	Names []string
+}
+
+// builtinPacks are the themed packs shipped with the engine.
+// "classic" matches the default ENGINE_PLAYER_NAMES list.
+var builtinPacks = map[string][]string{
+	"classic": {
+		"Gilbert McDonald", "Dorothy Bird", "Ernest Preston", "Vincent Schultz",
+		"Joanne Sloan", "Lana Moran", "Adrienne Fuller", "Greg Bennett",
+		"Curt Simon", "Rachel McMillan", "Dustin Eastman", "Willard Mendez",
+	},
+	"noir": {
+		"Sam Marlowe", "Vera Kane", "Eddie Mars", "Lola Graves", "Johnny Rocco",
+		"Rita Vance", "Frank Doyle", "Mae Sterling", "Vic Moretti", "Iris Blake",
+		"Lou Carver", "Dixie Lane", "Nick Valentine", "Gloria Hayes", "Max Shadow",
+		"Betty Cross",
+	},
+	"medieval": {
+		"Aldric of Thorn", "Isolde Ashford", "Godfrey the Bold", "Rowena Blackwood",
+		"Edmund Fairfax", "Matilda Greycloak", "Osric Stonebridge", "Elowen Marsh",
+		"Bertram Hollow", "Gwendolyn Reeve", "Tristan Vale", "Agnes Thatcher",
+		"Cedric Longbow", "Beatrix Mill", "Wulfric Hale", "Sybil Crowe",
+	},
+	"scifi": {
+		"Nova Reyes", "Orion Vask", "Lyra Chen-9", "Dax Kepler", "Zara Solis",
+		"Kade Mercer", "Ember Quill", "Jax Tycho", "Sera Nyx", "Talon Ardent",
+		"Vega Marr", "Rook Halcyon", "Ione Stratos", "Cass Vireo", "Juno Pike",
+		"Milo Drift",
+	},
+}
+
+// BuiltinPack returns a copy of the built-in pack with the given name.
+func BuiltinPack(name string) (Pack, bool) {
+	names, ok := builtinPacks[name]
+	if !ok {
+		return Pack{}, false
+	}
+	return Pack{Name: name, Names: append([]string(nil), names...)}, true
+}
+
+// BuiltinPackNames returns the names of all built-in packs, sorted.
+func BuiltinPackNames() []string {
+	packNames := make([]string, 0, len(builtinPacks))
+	for name := range builtinPacks {
+		packNames = append(packNames, name)
+	}
+	sort.Strings(packNames)
+	return packNames
+}
+
+// LoadPackFile reads a pack from a text file with one name per line.
+// Blank lines and lines starting with '#' are ignored, and so are repeated
+// names, so every name in the pack is drawn at most once.
+// The pack is named after the file (without extension).
+func LoadPackFile(path string) (Pack, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- path comes from trusted configuration
+	if err != nil {
+		return Pack{}, fmt.Errorf("failed to read name pack %s: %w", path, err)
+	}
+
+	pack := Pack{Name: strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))}
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if line == "" || strings.HasPrefix(line, "#") {
+			continue
+		}
+		pack.Names = append(pack.Names, line)
+	}
+	if err := scanner.Err(); err != nil {
+		return Pack{}, fmt.Errorf("failed to parse name pack %s: %w", path, err)
+	}
+	pack.Names = uniqueNames(pack.Names)
+
+	if len(pack.Names) == 0 {
+		return Pack{}, fmt.Errorf("name pack %s has no names", path)
+	}
+	return pack, nil
+}
+
+// CheckCoverage returns an error if the pack can't name maxPlayers players
+// without repeating a name.
+func (p Pack) CheckCoverage(maxPlayers int) error {
+	unique := make(map[string]bool, len(p.Names))
+	for _, name := range p.Names {
+		if name == "" {
+			return fmt.Errorf("name pack %q contains an empty name", p.Name)
+		}
+		unique[name] = true
+	}
+
+	if len(unique) < maxPlayers {
+		return fmt.Errorf("name pack %q has %d unique names, need at least %d (max players)",
+			p.Name, len(unique), maxPlayers)
+	}
+	return nil
+}
+
+// ResolvePack picks the name pack from configuration:
+// a pack file wins, then a built-in pack name, then the fallback list (named "custom").
+// Repeated names are dropped, keeping the first occurrence.
+func ResolvePack(packName, packFile string, fallback []string) (Pack, error) {
+	if packFile != "" {
+		return LoadPackFile(packFile)
+	}
+
+	if packName != "" {
+		pack, ok := BuiltinPack(packName)
+		if !ok {
+			return Pack{}, fmt.Errorf("%w %q (built-in packs: %s)",
+				ErrUnknownPack, packName, strings.Join(BuiltinPackNames(), ", "))
+		}
+		return pack, nil
+	}
+
+	if len(fallback) == 0 {
+		return Pack{}, errors.New("names list must not be empty")
+	}
+	return Pack{Name: "custom", Names: uniqueNames(fallback)}, nil
+}
+
+// uniqueNames returns a copy of names without repeats, keeping list order
+func uniqueNames(names []string) []string {
+	seen := make(map[string]bool, len(names))
+	unique := make([]string, 0, len(names))
+	for _, name := range names {
+		if seen[name] {
+			continue
+		}
+		seen[name] = true
+		unique = append(unique, name)
+	}
+	return unique
+}
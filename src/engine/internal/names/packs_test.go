@@ -0,0 +1,73 @@
+package names
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestBuiltinPacksCoverDefaultMaxPlayers(t *testing.T) {
+	for _, name := range BuiltinPackNames() {
+		pack, ok := BuiltinPack(name)
+		if !ok {
+			t.Fatalf("pack %s listed but not found", name)
+		}
+		if err := pack.CheckCoverage(12); err != nil {
+			t.Errorf("pack %s: %v", name, err)
+		}
+	}
+}
+
+func TestCheckCoverage(t *testing.T) {
+	pack := Pack{Name: "tiny", Names: []string{"Alice", "Bob", "Bob"}}
+
+	if err := pack.CheckCoverage(2); err != nil {
+		t.Errorf("unexpected error: %v", err)
+	}
+	// duplicates don't count
+	if err := pack.CheckCoverage(3); err == nil {
+		t.Error("expected error when pack is too small")
+	}
+}
+
+func TestLoadPackFile(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "pirates.txt")
+	content := "# pirate crew\nAnne Bonny\n\nBlackbeard\n  Calico Jack  \nBlackbeard\n"
+	if err := os.WriteFile(path, []byte(content), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	pack, err := LoadPackFile(path)
+	if err != nil {
+		t.Fatalf("unexpected error: %v", err)
+	}
+	if pack.Name != "pirates" {
+		t.Errorf("pack name: got %q, expected pirates", pack.Name)
+	}
+	expected := []string{"Anne Bonny", "Blackbeard", "Calico Jack"}
+	if len(pack.Names) != len(expected) {
+		t.Fatalf("got %v, expected %v", pack.Names, expected)
+	}
+	for i := range expected {
+		if pack.Names[i] != expected[i] {
+			t.Errorf("at %d: got %q, expected %q", i, pack.Names[i], expected[i])
+		}
+	}
+}
+
+func TestResolvePack(t *testing.T) {
+	pack, err := ResolvePack("noir", "", []string{"Alice"})
+	if err != nil || pack.Name != "noir" {
+		t.Errorf("expected noir pack, got %q (err=%v)", pack.Name, err)
+	}
+
+	pack, err = ResolvePack("", "", []string{"Alice", "Alice"})
+	if err != nil || pack.Name != "custom" || len(pack.Names) != 1 {
+		t.Errorf("expected custom fallback pack, got %+v (err=%v)", pack, err)
+	}
+
+	if _, err := ResolvePack("western", "", nil); !errors.Is(err, ErrUnknownPack) {
+		t.Errorf("expected ErrUnknownPack, got %v", err)
+	}
+}
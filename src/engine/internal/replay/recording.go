@@ -0,0 +1,259 @@
+// Package replay records live games and plays them back against the current engine.
+//
+// A Recorder taps the engine's transport: every inbound player action and every
+// published event goes to a JSONL file with its time since the recording started,
+// after a header with the game ID, seed and configuration. Replay feeds the
+// recorded inputs to a fresh engine on a virtual clock and diffs its output
+// against the recording, so real games (e.g. LLM games) work as regression
+// fixtures for the rules (see cmd/replay).
+package replay
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"sync"
+	"time"
+
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/kafka"
+)
+
+// Version is the recording format version written to the header.
+const Version = 1
+
+// Entry kinds
+const (
+	KindHeader    = "header"
+	KindInbound   = "in"  // message handed to the engine
+	KindOutbound  = "out" // message the engine published
+	KindAddPlayer = "add_player"
+	KindStartGame = "start_game"
+)
+
+// Entry is one line of a recording.
+type Entry struct {
+	Kind string `json:"kind"`
+	At   int64  `json:"at_ms"` // ms since the recording started
+
+	// inbound and outbound messages
+	Topic string          `json:"topic,omitempty"`
+	Key   string          `json:"key,omitempty"`
+	Value json.RawMessage `json:"value,omitempty"`
+
+	Header *Header `json:"header,omitempty"`
+}
+
+// Header describes the game a recording was taken from.
+type Header struct {
+	Version int            `json:"version"`
+	GameID  string         `json:"game_id"`
+	Seed    int64          `json:"seed"`
+	Start   int64          `json:"start_ms"` // Unix ms, the recording's time zero
+	Config  *config.Config `json:"config"`
+
+	// ruleset loaded from ENGINE_RULESET_FILE, kept so the recording
+	// replays without the file
+	Ruleset *domain.Ruleset `json:"ruleset,omitempty"`
+}
+
+// Recording is a parsed recording file.
+type Recording struct {
+	Header  Header
+	Entries []Entry // everything after the header, in recorded order
+}
+
+// Recorder writes a recording. It is safe for concurrent use: the consumer
+// and the engine loop record at the same time. A nil *Recorder records nothing,
+// so callers don't need to check whether recording is on.
+//
+// Write errors don't stop the game; the first one is kept for Err.
+type Recorder struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+	clock   clock.Clock
+	start   time.Time
+	err     error
+}
+
+// NewRecorder writes the header for the game and returns a recorder writing to w.
+// Create it before the first player joins: its time zero is now.
+func NewRecorder(w io.Writer, state *domain.GameState, cfg *config.Config, clk clock.Clock) (*Recorder, error) {
+	if cfg == nil {
+		return nil, errors.New("config must not be nil")
+	}
+
+	var ruleset *domain.Ruleset
+	if cfg.RulesetFile != "" {
+		loaded, err := engine.LoadRuleset(cfg.RulesetFile)
+		if err != nil {
+			return nil, err
+		}
+		ruleset = loaded
+	}
+
+	r := &Recorder{encoder: json.NewEncoder(w), clock: clk, start: clk.Now()}
+	header := &Header{
+		Version: Version,
+		GameID:  state.ID,
+		Seed:    state.Seed,
+		Start:   r.start.UnixMilli(),
+		Config:  cfg,
+		Ruleset: ruleset,
+	}
+	if err := r.encoder.Encode(Entry{Kind: KindHeader, Header: header}); err != nil {
+		return nil, fmt.Errorf("failed to write recording header: %w", err)
+	}
+	return r, nil
+}
+
+// Producer wraps the engine's producer so every published message is recorded.
+// Give it to the engine only: bots publishing actions must use the plain producer.
+func (r *Recorder) Producer(producer kafka.Producer) kafka.Producer {
+	if r == nil {
+		return producer
+	}
+	return &recordingProducer{Producer: producer, recorder: r}
+}
+
+// Handler wraps the engine's message handler so every inbound message is recorded.
+func (r *Recorder) Handler(handler kafka.HandlerFunc) kafka.HandlerFunc {
+	if r == nil {
+		return handler
+	}
+	return func(ctx context.Context, msg kafka.Message) error {
+		r.message(KindInbound, msg)
+		return handler(ctx, msg)
+	}
+}
+
+// AddPlayer records that the bootstrap added a player.
+func (r *Recorder) AddPlayer() {
+	if r != nil {
+		r.write(Entry{Kind: KindAddPlayer})
+	}
+}
+
+// StartGame records that the bootstrap started the game.
+func (r *Recorder) StartGame() {
+	if r != nil {
+		r.write(Entry{Kind: KindStartGame})
+	}
+}
+
+// Err returns the first write error, if any.
+func (r *Recorder) Err() error {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.err
+}
+
+// message records a message. Values that aren't JSON are skipped:
+// the engine drops them before they reach the game.
+func (r *Recorder) message(kind string, msg kafka.Message) {
+	if !json.Valid(msg.Value) {
+		return
+	}
+	r.write(Entry{
+		Kind:  kind,
+		Topic: msg.Topic,
+		Key:   string(msg.Key),
+		Value: json.RawMessage(msg.Value),
+	})
+}
+
+func (r *Recorder) write(entry Entry) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	entry.At = r.clock.Now().Sub(r.start).Milliseconds()
+	if err := r.encoder.Encode(entry); err != nil && r.err == nil {
+		r.err = err
+	}
+}
+
+// recordingProducer records what the engine published successfully.
+type recordingProducer struct {
+	kafka.Producer
+	recorder *Recorder
+}
+
+func (p *recordingProducer) Publish(ctx context.Context, msg kafka.Message) error {
+	if err := p.Producer.Publish(ctx, msg); err != nil {
+		return err
+	}
+	p.recorder.message(KindOutbound, msg)
+	return nil
+}
+
+// Load reads a recording file.
+func Load(path string) (*Recording, error) {
+	file, err := os.Open(path) // #nosec G304 -- recordings are chosen by the developer
+	if err != nil {
+		return nil, fmt.Errorf("failed to open recording %s: %w", path, err)
+	}
+	defer file.Close()
+
+	rec, err := Parse(file)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return rec, nil
+}
+
+// Parse reads a recording: a header line followed by entries.
+func Parse(r io.Reader) (*Recording, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var rec *Recording
+	line := 0
+	for scanner.Scan() {
+		line++
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var entry Entry
+		if err := json.Unmarshal(scanner.Bytes(), &entry); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+
+		if rec == nil {
+			if entry.Kind != KindHeader || entry.Header == nil {
+				return nil, fmt.Errorf("line %d: recording must start with a header", line)
+			}
+			if entry.Header.Version != Version {
+				return nil, fmt.Errorf("unsupported recording version %d", entry.Header.Version)
+			}
+			if entry.Header.Config == nil {
+				return nil, errors.New("recording header has no config")
+			}
+			rec = &Recording{Header: *entry.Header}
+			continue
+		}
+
+		switch entry.Kind {
+		case KindInbound, KindOutbound, KindAddPlayer, KindStartGame:
+			rec.Entries = append(rec.Entries, entry)
+		default:
+			return nil, fmt.Errorf("line %d: unknown entry kind %q", line, entry.Kind)
+		}
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if rec == nil {
+		return nil, errors.New("empty recording")
+	}
+	return rec, nil
+}
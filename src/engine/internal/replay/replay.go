@@ -0,0 +1,231 @@
+package replay
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"reflect"
+	"time"
+
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/kafka"
+)
+
+// clockFields are event fields taken from the clock. A live engine stamps them
+// a few ms after the recorded arrival time, so they are left out of the diff.
+var clockFields = map[string]bool{
+	"timestamp":     true,
+	"phase_ends_at": true,
+	"ends_at":       true, // PhaseChanged, PhaseEndingSoon
+	"deadline":      true, // ActionRequested
+}
+
+// Result is the outcome of replaying a recording.
+type Result struct {
+	Inbound  int `json:"inbound"`  // messages fed to the engine
+	Recorded int `json:"recorded"` // messages the recorded engine published
+	Replayed int `json:"replayed"` // messages the replayed engine published
+
+	Mismatches []Mismatch `json:"mismatches,omitempty"`
+
+	// Journal holds the commands the replayed engine applied, rebuilt from the
+	// recorded inputs, so a recorded game can be forked (see sim.Branch)
+	Journal *engine.Journal `json:"-"`
+}
+
+// OK returns true if the replay published exactly what was recorded.
+func (r *Result) OK() bool {
+	return len(r.Mismatches) == 0
+}
+
+// Mismatch is a published message that differs between recording and replay.
+type Mismatch struct {
+	Index    int    `json:"index"`              // position among the published messages
+	Recorded *Entry `json:"recorded,omitempty"` // nil: the replay published more
+	Replayed *Entry `json:"replayed,omitempty"` // nil: the replay published less
+}
+
+func (m Mismatch) String() string {
+	switch {
+	case m.Recorded == nil:
+		return fmt.Sprintf("#%d extra: %s", m.Index, m.Replayed.Value)
+	case m.Replayed == nil:
+		return fmt.Sprintf("#%d missing: %s", m.Index, m.Recorded.Value)
+	default:
+		return fmt.Sprintf("#%d\n    recorded: %s\n    replayed: %s", m.Index, m.Recorded.Value, m.Replayed.Value)
+	}
+}
+
+// Replay plays the recording's inputs into a fresh engine and diffs the output.
+//
+// The virtual clock starts at the recording's time zero and is moved to each
+// entry's time before it is applied, so phase timeouts fire where they did
+// live. Messages that arrived in the same millisecond a phase timed out may
+// be applied in a different order than live; that shows up as a mismatch.
+func Replay(rec *Recording) (*Result, error) {
+	if rec == nil {
+		return nil, errors.New("recording must not be nil")
+	}
+
+	runCfg := *rec.Header.Config
+	runCfg.RulesetFile = "" // the header carries the loaded ruleset
+
+	state := domain.NewGameState("replay")
+	state.ID = rec.Header.GameID
+	state.Seed = rec.Header.Seed
+
+	start := time.UnixMilli(rec.Header.Start)
+	virtual := clock.NewVirtual(start)
+	published := &collector{clock: virtual, start: start}
+	journal := engine.NewJournal()
+	eng, err := engine.NewEngine(state, published, &runCfg,
+		engine.WithClock(virtual), engine.WithRuleset(rec.Header.Ruleset), engine.WithJournal(journal))
+	if err != nil {
+		return nil, err
+	}
+	defer eng.Stop()
+
+	p := &player{state: state, engine: eng, clock: virtual, start: start}
+	result := &Result{Journal: journal}
+	var recorded []Entry
+	ctx := context.Background()
+	for _, entry := range rec.Entries {
+		p.runTo(entry.At)
+
+		switch entry.Kind {
+		case KindOutbound:
+			recorded = append(recorded, entry)
+			continue
+		case KindAddPlayer:
+			err = eng.AddPlayer()
+		case KindStartGame:
+			err = eng.StartGame()
+		case KindInbound:
+			result.Inbound++
+			// invalid actions were dropped live too
+			_ = eng.HandleMessage(ctx, kafka.Message{Topic: entry.Topic, Key: []byte(entry.Key), Value: entry.Value})
+		}
+		if err != nil {
+			return nil, fmt.Errorf("%s at %dms: %w", entry.Kind, entry.At, err)
+		}
+		eng.ProcessPending()
+	}
+
+	result.Recorded = len(recorded)
+	result.Replayed = len(published.entries)
+	result.Mismatches, err = Diff(recorded, published.entries)
+	if err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// Diff compares published messages in order, ignoring clock fields.
+func Diff(recorded, replayed []Entry) ([]Mismatch, error) {
+	var mismatches []Mismatch
+	for i := 0; i < max(len(recorded), len(replayed)); i++ {
+		mismatch := Mismatch{Index: i}
+		if i < len(recorded) {
+			mismatch.Recorded = &recorded[i]
+		}
+		if i < len(replayed) {
+			mismatch.Replayed = &replayed[i]
+		}
+		if mismatch.Recorded != nil && mismatch.Replayed != nil {
+			same, err := sameMessage(*mismatch.Recorded, *mismatch.Replayed)
+			if err != nil {
+				return nil, fmt.Errorf("message %d: %w", i, err)
+			}
+			if same {
+				continue
+			}
+		}
+		mismatches = append(mismatches, mismatch)
+	}
+	return mismatches, nil
+}
+
+func sameMessage(a, b Entry) (bool, error) {
+	if a.Topic != b.Topic || a.Key != b.Key {
+		return false, nil
+	}
+	var valueA, valueB any
+	if err := json.Unmarshal(a.Value, &valueA); err != nil {
+		return false, err
+	}
+	if err := json.Unmarshal(b.Value, &valueB); err != nil {
+		return false, err
+	}
+	return reflect.DeepEqual(withoutClockFields(valueA), withoutClockFields(valueB)), nil
+}
+
+// withoutClockFields drops clockFields from every object in a decoded JSON value.
+func withoutClockFields(value any) any {
+	switch v := value.(type) {
+	case map[string]any:
+		for key, field := range v {
+			if clockFields[key] {
+				delete(v, key)
+				continue
+			}
+			v[key] = withoutClockFields(field)
+		}
+	case []any:
+		for i, item := range v {
+			v[i] = withoutClockFields(item)
+		}
+	}
+	return value
+}
+
+// player drives the replayed engine on the virtual clock.
+type player struct {
+	state  *domain.GameState
+	engine *engine.Engine
+	clock  *clock.Virtual
+	start  time.Time
+}
+
+// runTo moves the clock to ms after the start, one phase timeout at a time
+// so every phase change schedules the next timer before the clock moves on.
+func (p *player) runTo(ms int64) {
+	target := p.start.Add(time.Duration(ms) * time.Millisecond)
+	for p.state.PhaseEndsAt != 0 {
+		deadline := time.UnixMilli(p.state.PhaseEndsAt)
+		if deadline.After(target) {
+			break
+		}
+		p.clock.Advance(deadline.Sub(p.clock.Now()))
+		p.engine.ProcessPending()
+		if p.state.PhaseEndsAt == deadline.UnixMilli() {
+			break // the timer didn't change the phase
+		}
+	}
+	if now := p.clock.Now(); target.After(now) {
+		p.clock.Advance(target.Sub(now))
+		p.engine.ProcessPending()
+	}
+}
+
+// collector is the replayed engine's producer.
+type collector struct {
+	clock   clock.Clock
+	start   time.Time
+	entries []Entry
+}
+
+func (c *collector) Publish(_ context.Context, msg kafka.Message) error {
+	c.entries = append(c.entries, Entry{
+		Kind:  KindOutbound,
+		At:    c.clock.Now().Sub(c.start).Milliseconds(),
+		Topic: msg.Topic,
+		Key:   string(msg.Key),
+		Value: json.RawMessage(msg.Value),
+	})
+	return nil
+}
+
+func (c *collector) Close() error { return nil }
@@ -0,0 +1,234 @@
+package replay
+
+import (
+	"bytes"
+	"context"
+	"strings"
+	"testing"
+	"time"
+
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/kafka"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	return cfg
+}
+
+// queue is a producer the test drains by hand.
+type queue struct{ msgs []kafka.Message }
+
+func (q *queue) Publish(_ context.Context, msg kafka.Message) error {
+	q.msgs = append(q.msgs, msg)
+	return nil
+}
+
+func (q *queue) Close() error { return nil }
+
+func (q *queue) pop() (kafka.Message, bool) {
+	if len(q.msgs) == 0 {
+		return kafka.Message{}, false
+	}
+	msg := q.msgs[0]
+	q.msgs = q.msgs[1:]
+	return msg, true
+}
+
+// recordGame plays a bot game through a recorder, as cmd/engine does live.
+// With jitter, the engine takes every action and timeout that long after it
+// arrived, as a live loop lags behind the consumer and the timers.
+func recordGame(t *testing.T, seed int64, jitter time.Duration) []byte {
+	t.Helper()
+	cfg := testConfig(t)
+
+	state := domain.NewGameState("rec")
+	state.Seed = seed
+	virtual := clock.NewVirtual(time.Unix(1000, 0))
+
+	var buf bytes.Buffer
+	rec, err := NewRecorder(&buf, state, cfg, virtual)
+	if err != nil {
+		t.Fatalf("NewRecorder failed: %v", err)
+	}
+	published, actions := &queue{}, &queue{}
+	eng, err := engine.NewEngine(state, rec.Producer(published), cfg, engine.WithClock(virtual))
+	if err != nil {
+		t.Fatalf("NewEngine failed: %v", err)
+	}
+	defer eng.Stop()
+
+	strategies, err := bots.NewStrategies(cfg.BotStrategies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	runtime, err := bots.NewRuntime(state.ID, actions, strategies, seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	for i := 0; i < cfg.GameMinPlayers; i++ {
+		if err := eng.AddPlayer(); err != nil {
+			t.Fatal(err)
+		}
+		rec.AddPlayer()
+		eng.ProcessPending()
+	}
+	if err := eng.StartGame(); err != nil {
+		t.Fatal(err)
+	}
+	rec.StartGame()
+	eng.ProcessPending()
+
+	ctx := context.Background()
+	handle := rec.Handler(eng.HandleMessage)
+	for steps := 0; state.Phase != domain.PhaseEnded; steps++ {
+		if steps > 10000 {
+			t.Fatal("game did not end")
+		}
+		progressed := false
+		for msg, ok := published.pop(); ok; msg, ok = published.pop() {
+			if err := runtime.HandleMessage(ctx, msg); err != nil {
+				t.Fatal(err)
+			}
+			progressed = true
+		}
+		for msg, ok := actions.pop(); ok; msg, ok = actions.pop() {
+			_ = handle(ctx, msg)
+			virtual.Advance(jitter)
+			eng.ProcessPending()
+			progressed = true
+		}
+		if !progressed {
+			if !virtual.AdvanceToNext() {
+				t.Fatalf("game stalled in %s", state.Phase)
+			}
+			virtual.Advance(jitter)
+			eng.ProcessPending()
+		}
+	}
+
+	if err := rec.Err(); err != nil {
+		t.Fatalf("recording failed: %v", err)
+	}
+	return buf.Bytes()
+}
+
+func TestReplay_MatchesRecording(t *testing.T) {
+	rec, err := Parse(bytes.NewReader(recordGame(t, 3, 0)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	if rec.Header.Seed != 3 {
+		t.Errorf("expected seed 3 in header, got %d", rec.Header.Seed)
+	}
+
+	result, err := Replay(rec)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if result.Inbound == 0 || result.Recorded == 0 {
+		t.Fatalf("expected a played game, got %+v", result)
+	}
+	if !result.OK() {
+		t.Fatalf("replay differs from recording (%d recorded, %d replayed):\n%v",
+			result.Recorded, result.Replayed, result.Mismatches[0])
+	}
+}
+
+func TestReplay_IgnoresJitter(t *testing.T) {
+	rec, err := Parse(bytes.NewReader(recordGame(t, 5, 3*time.Millisecond)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// deadlines and phase ends come out a few ms earlier than recorded
+	result, err := Replay(rec)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if !result.OK() {
+		t.Fatalf("replay differs from recording (%d recorded, %d replayed):\n%v",
+			result.Recorded, result.Replayed, result.Mismatches[0])
+	}
+}
+
+func TestReplay_ReportsChangedOutput(t *testing.T) {
+	rec, err := Parse(bytes.NewReader(recordGame(t, 3, 0)))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+
+	// drop the first player action: everything after it plays differently
+	for i, entry := range rec.Entries {
+		if entry.Kind == KindInbound {
+			rec.Entries = append(rec.Entries[:i], rec.Entries[i+1:]...)
+			break
+		}
+	}
+
+	result, err := Replay(rec)
+	if err != nil {
+		t.Fatalf("Replay failed: %v", err)
+	}
+	if result.OK() {
+		t.Fatal("expected mismatches after dropping an action")
+	}
+}
+
+func TestDiff_IgnoresClockFields(t *testing.T) {
+	recorded := []Entry{{Topic: "t", Value: []byte(`{"type":"a","timestamp":1,"deadline":3,"nested":{"phase_ends_at":5}}`)}}
+	replayed := []Entry{{Topic: "t", Value: []byte(`{"type":"a","timestamp":2,"deadline":7,"nested":{"phase_ends_at":9}}`)}}
+	mismatches, err := Diff(recorded, replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 0 {
+		t.Errorf("expected no mismatches, got %v", mismatches)
+	}
+
+	replayed = append(replayed, Entry{Topic: "t", Value: []byte(`{"type":"b"}`)})
+	mismatches, err = Diff(recorded, replayed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(mismatches) != 1 || mismatches[0].Recorded != nil {
+		t.Errorf("expected one extra message, got %v", mismatches)
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	tests := map[string]string{
+		"empty":        "",
+		"no header":    `{"kind":"in","at_ms":0,"value":{}}`,
+		"version":      `{"kind":"header","header":{"version":99,"config":{}}}`,
+		"no config":    `{"kind":"header","header":{"version":1}}`,
+		"unknown kind": `{"kind":"header","header":{"version":1,"config":{}}}` + "\n" + `{"kind":"later","at_ms":0}`,
+	}
+	for name, data := range tests {
+		if _, err := Parse(strings.NewReader(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
+
+func TestRecorder_Nil(t *testing.T) {
+	var rec *Recorder
+	producer := &queue{}
+	if rec.Producer(producer) != kafka.Producer(producer) {
+		t.Error("nil recorder should return the producer unchanged")
+	}
+	rec.AddPlayer()
+	rec.StartGame()
+	if rec.Err() != nil {
+		t.Error("nil recorder should have no error")
+	}
+}
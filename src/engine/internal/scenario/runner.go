@@ -0,0 +1,435 @@
+package scenario
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
+// maxAdvances bounds "advance" with a target phase, in case it is never reached.
+const maxAdvances = 100
+
+// Outcome is the result of one scenario run.
+type Outcome struct {
+	Name     string   `json:"name"`
+	Failures []string `json:"failures,omitempty"`
+	Events   int      `json:"events"` // events the engine emitted
+}
+
+// Passed returns true if every expectation held.
+func (o *Outcome) Passed() bool {
+	return len(o.Failures) == 0
+}
+
+func (o *Outcome) fail(format string, args ...any) {
+	o.Failures = append(o.Failures, fmt.Sprintf(format, args...))
+}
+
+// Run plays the scenario on the real engine loop, stepped on a virtual clock
+// and publishing to an in-memory producer, and checks its expectations.
+// Phase timeouts come from cfg. The error is for scenarios that can't be set
+// up; failed expectations are reported in the outcome.
+func Run(cfg *config.Config, sc *Scenario) (*Outcome, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("config must not be nil")
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+
+	n := len(sc.Players)
+	names := make([]string, 0, n)
+	for _, player := range sc.Players {
+		names = append(names, player.Name)
+	}
+
+	// Seat the roster in order under its own names
+	runCfg := *cfg
+	runCfg.PlayerNames = names
+	runCfg.NamePack, runCfg.NamePackFile = "", ""
+	runCfg.NameShuffle = false
+	runCfg.GameMinPlayers, runCfg.GameMaxPlayers = n, n
+	runCfg.RulesetFile = ""
+
+	state := domain.NewGameState("scenario")
+	state.ID = "scenario"
+	state.Seed = sc.Seed
+
+	ruleset := &domain.Ruleset{
+		Name:          sc.Name,
+		Distributions: map[int]domain.RoleCounts{n: sc.RoleCounts()},
+	}
+	producer := &recorder{}
+	virtual := clock.NewVirtual(time.Unix(0, 0).UTC())
+	eng, err := engine.NewEngine(state, producer, &runCfg, engine.WithClock(virtual), engine.WithRuleset(ruleset))
+	if err != nil {
+		return nil, err
+	}
+	defer eng.Stop()
+
+	r := &runner{
+		state:    state,
+		engine:   eng,
+		clock:    virtual,
+		producer: producer,
+		ids:      make(map[string]string, n),
+		roles:    make(map[string]domain.Role, n),
+		outcome:  &Outcome{Name: sc.Name},
+	}
+
+	for range sc.Players {
+		if err := eng.AddPlayer(); err != nil {
+			return nil, err
+		}
+		eng.ProcessPending()
+	}
+	pinned := make(map[int]domain.Role, n)
+	for i, player := range state.GetSeatedPlayers() {
+		role, _ := domain.ParseRole(sc.Players[i].Role)
+		r.ids[player.Name] = player.ID
+		r.roles[player.ID] = role
+		pinned[player.Seat] = role
+	}
+
+	if err := eng.StartGameWithPinnedRoles(pinned); err != nil {
+		return nil, err
+	}
+	eng.ProcessPending()
+	if state.Phase == domain.PhaseWaiting {
+		return nil, fmt.Errorf("game did not start")
+	}
+
+	for i, step := range sc.Steps {
+		where := fmt.Sprintf("step %d", i+1)
+		r.wait(time.Duration(step.After))
+		if err := r.do(step); err != nil {
+			r.outcome.fail("%s: %v", where, err)
+			break
+		}
+		r.check(where, step.Expect)
+	}
+	r.check("expect", sc.Expect)
+
+	r.outcome.Events = len(producer.messages)
+	return r.outcome, nil
+}
+
+// runner holds one scenario run.
+type runner struct {
+	state    *domain.GameState
+	engine   *engine.Engine
+	clock    *clock.Virtual
+	producer *recorder
+
+	ids   map[string]string      // name -> player ID
+	roles map[string]domain.Role // player ID -> role
+
+	checked int // events already covered by an expectation
+	outcome *Outcome
+}
+
+// do performs one step's action.
+func (r *runner) do(step Step) error {
+	base := events.BaseEvent{GameID: r.state.ID}
+	actor := r.ids[step.Player]
+
+	var event any
+	switch step.Action {
+	case "":
+		return nil
+	case ActionAdvance:
+		return r.advance(step.To)
+	case ActionVote:
+		base.Type = events.TypeVoteSubmitted
+		event = &events.VoteSubmitted{BaseEvent: base, VoterID: actor, TargetID: r.ids[step.Target]}
+	case ActionNight:
+		role := step.Role
+		if role == "" {
+			role = r.roles[actor].String()
+		}
+		base.Type = events.TypeNightAction
+		event = &events.NightAction{BaseEvent: base, Role: role, ActorID: actor, TargetID: r.ids[step.Target]}
+	case ActionChat:
+		base.Type = events.TypeAllChatMessage
+		event = &events.AllChatMessage{BaseEvent: base, SenderID: actor, Message: step.Message}
+	case ActionMafiaChat:
+		base.Type = events.TypeMafiaChatMessage
+		event = &events.MafiaChatMessage{BaseEvent: base, SenderID: actor, Message: step.Message}
+	default:
+		return fmt.Errorf("unknown action %q", step.Action)
+	}
+
+	// through the same entrypoint as a player's Kafka message
+	data, err := events.Marshal(event)
+	if err != nil {
+		return err
+	}
+	msg := kafka.Message{Topic: kafka.PlayerActionsTopic, Key: kafka.GameKey(r.state.ID), Value: data}
+	if err := r.engine.HandleMessage(context.Background(), msg); err != nil {
+		return err
+	}
+	r.engine.ProcessPending()
+	return nil
+}
+
+// wait moves the clock forward by d, letting every phase timeout on the way fire.
+func (r *runner) wait(d time.Duration) {
+	for d > 0 {
+		untilDeadline := r.untilDeadline()
+		if untilDeadline <= 0 || untilDeadline > d {
+			r.clock.Advance(d)
+			r.engine.ProcessPending()
+			return
+		}
+		r.clock.Advance(untilDeadline)
+		r.engine.ProcessPending()
+		d -= untilDeadline
+	}
+}
+
+// advance runs the clock to the current phase timeout, or until phase `to`.
+func (r *runner) advance(to string) error {
+	for i := 0; i < maxAdvances; i++ {
+		untilDeadline := r.untilDeadline()
+		if untilDeadline <= 0 {
+			return fmt.Errorf("phase %s has no timeout to advance to", r.state.Phase)
+		}
+		r.clock.Advance(untilDeadline)
+		r.engine.ProcessPending()
+
+		if to == "" || r.state.Phase.String() == to {
+			return nil
+		}
+	}
+	return fmt.Errorf("phase %s not reached after %d advances", to, maxAdvances)
+}
+
+// untilDeadline returns the time left in the current phase (0 if it has no timeout).
+func (r *runner) untilDeadline() time.Duration {
+	if r.state.PhaseEndsAt == 0 {
+		return 0
+	}
+	return time.UnixMilli(r.state.PhaseEndsAt).Sub(r.clock.Now())
+}
+
+// check verifies an expectation against the events emitted since the last one.
+func (r *runner) check(where string, expect *Expect) {
+	if expect == nil {
+		return
+	}
+	emitted, err := r.decode(r.producer.messages[r.checked:])
+	r.checked = len(r.producer.messages)
+	if err != nil {
+		r.outcome.fail("%s: %v", where, err)
+		return
+	}
+
+	next := 0
+	for _, want := range expect.Events {
+		found := false
+		for next < len(emitted) {
+			ev := emitted[next]
+			next++
+			if r.matches(want, ev) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			r.outcome.fail("%s: expected %s (in order)", where, describe(want))
+			break
+		}
+	}
+
+	for _, unwanted := range expect.Absent {
+		for _, ev := range emitted {
+			if r.matches(unwanted, ev) {
+				r.outcome.fail("%s: unexpected %s", where, describe(unwanted))
+				break
+			}
+		}
+	}
+
+	if expect.State != nil {
+		r.checkState(where, expect.State)
+	}
+}
+
+func (r *runner) checkState(where string, want *StateMatch) {
+	state := r.state
+	if want.Phase != "" && state.Phase.String() != want.Phase {
+		r.outcome.fail("%s: phase is %s, expected %s", where, state.Phase, want.Phase)
+	}
+	if want.Round != 0 && state.Round != want.Round {
+		r.outcome.fail("%s: round is %d, expected %d", where, state.Round, want.Round)
+	}
+	if want.Winner != "" && state.Winner.String() != want.Winner {
+		r.outcome.fail("%s: winner is %s, expected %s", where, state.Winner, want.Winner)
+	}
+	for _, name := range want.Alive {
+		if !state.GetPlayer(r.ids[name]).Alive {
+			r.outcome.fail("%s: %s is dead, expected alive", where, name)
+		}
+	}
+	for _, name := range want.Dead {
+		if state.GetPlayer(r.ids[name]).Alive {
+			r.outcome.fail("%s: %s is alive, expected dead", where, name)
+		}
+	}
+	if want.Votes != nil {
+		votes := make(map[string]string, len(want.Votes))
+		for voter, target := range want.Votes {
+			votes[r.ids[voter]] = r.ids[target]
+		}
+		if len(votes) != len(state.Votes) {
+			r.outcome.fail("%s: %d votes recorded, expected %d", where, len(state.Votes), len(votes))
+			return
+		}
+		for voter, target := range votes {
+			if state.Votes[voter] != target {
+				r.outcome.fail("%s: vote of %s is %q, expected %q", where, voter, state.Votes[voter], target)
+			}
+		}
+	}
+}
+
+// emittedEvent is an engine event both as JSON fields and as its type.
+type emittedEvent struct {
+	fields map[string]any
+	event  any
+}
+
+func (r *runner) decode(messages []kafka.Message) ([]emittedEvent, error) {
+	decoded := make([]emittedEvent, 0, len(messages))
+	for _, msg := range messages {
+		var fields map[string]any
+		if err := json.Unmarshal(msg.Value, &fields); err != nil {
+			return nil, fmt.Errorf("engine emitted invalid JSON: %w", err)
+		}
+		event, err := events.DeserializeEngineEvent(msg.Value)
+		if err != nil {
+			return nil, err
+		}
+		decoded = append(decoded, emittedEvent{fields: fields, event: event})
+	}
+	return decoded, nil
+}
+
+// matches returns true if the event has the type, fields and visibility wanted.
+func (r *runner) matches(want EventMatch, ev emittedEvent) bool {
+	if ev.fields["type"] != want.Type {
+		return false
+	}
+	if want.Match != nil && !subset(r.resolve(want.Match), ev.fields) {
+		return false
+	}
+	for _, name := range want.VisibleTo {
+		if !r.viewer(name).CanSee(ev.event) {
+			return false
+		}
+	}
+	for _, name := range want.HiddenFrom {
+		if r.viewer(name).CanSee(ev.event) {
+			return false
+		}
+	}
+	return true
+}
+
+func (r *runner) viewer(name string) events.Viewer {
+	id := r.ids[name]
+	return events.Viewer{PlayerID: id, Faction: r.roles[id].Faction().String()}
+}
+
+// resolve replaces "@Name" references with player IDs.
+func (r *runner) resolve(value any) any {
+	switch v := value.(type) {
+	case string:
+		if name, ok := strings.CutPrefix(v, PlayerRefPrefix); ok {
+			if id, ok := r.ids[name]; ok {
+				return id
+			}
+		}
+		return v
+	case map[string]any:
+		resolved := make(map[string]any, len(v))
+		for key, field := range v {
+			resolved[key] = r.resolve(field)
+		}
+		return resolved
+	case []any:
+		resolved := make([]any, len(v))
+		for i, item := range v {
+			resolved[i] = r.resolve(item)
+		}
+		return resolved
+	default:
+		return v
+	}
+}
+
+// subset returns true if every field of want is in got with the same value.
+func subset(want, got any) bool {
+	switch w := want.(type) {
+	case map[string]any:
+		g, ok := got.(map[string]any)
+		if !ok {
+			return false
+		}
+		for key, field := range w {
+			if !subset(field, g[key]) {
+				return false
+			}
+		}
+		return true
+	case []any:
+		g, ok := got.([]any)
+		if !ok || len(g) != len(w) {
+			return false
+		}
+		for i := range w {
+			if !subset(w[i], g[i]) {
+				return false
+			}
+		}
+		return true
+	default:
+		return want == got
+	}
+}
+
+func describe(match EventMatch) string {
+	desc := match.Type
+	if match.Match != nil {
+		fields, _ := json.Marshal(match.Match)
+		desc += " " + string(fields)
+	}
+	if len(match.VisibleTo) > 0 {
+		desc += fmt.Sprintf(" visible to %v", match.VisibleTo)
+	}
+	if len(match.HiddenFrom) > 0 {
+		desc += fmt.Sprintf(" hidden from %v", match.HiddenFrom)
+	}
+	return desc
+}
+
+// recorder is the fake producer: it keeps what the engine publishes.
+type recorder struct {
+	messages []kafka.Message
+}
+
+func (p *recorder) Publish(_ context.Context, msg kafka.Message) error {
+	p.messages = append(p.messages, msg)
+	return nil
+}
+
+func (p *recorder) Close() error { return nil }
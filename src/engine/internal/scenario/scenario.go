@@ -0,0 +1,264 @@
+// Package scenario runs scripted games: a fixed roster with fixed roles,
+// timed player actions and phase advances, and assertions on the emitted
+// events and the final state. Scenarios are JSON files, so rule regressions
+// can be written down without Go code (see cmd/scenario and scenarios/).
+package scenario
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+
+	"mafia-engine/internal/domain"
+)
+
+// Step actions
+const (
+	ActionVote      = "vote"
+	ActionNight     = "night"
+	ActionChat      = "chat"
+	ActionMafiaChat = "mafia_chat"
+	ActionAdvance   = "advance" // run the clock to the current phase timeout
+)
+
+// PlayerRefPrefix marks a player name inside event matches ("@Alice"),
+// replaced by the player's ID before comparing.
+const PlayerRefPrefix = "@"
+
+// DefaultSeed seeds scenarios that don't set one (traits are still dealt by the RNG).
+const DefaultSeed = 1
+
+// Scenario is one scripted game.
+type Scenario struct {
+	Name        string   `json:"name"`
+	Description string   `json:"description,omitempty"`
+	Seed        int64    `json:"seed,omitempty"`
+	Players     []Player `json:"players"` // seat order
+	Steps       []Step   `json:"steps"`
+
+	// Expect is checked once all steps ran
+	Expect *Expect `json:"expect,omitempty"`
+}
+
+// Player is a seat with a fixed name and role.
+type Player struct {
+	Name string `json:"name"`
+	Role string `json:"role"`
+}
+
+// Step is one thing that happens in the game, optionally after some time passed.
+// A step may only carry Expect, to check the game at that point.
+type Step struct {
+	After   Duration `json:"after,omitempty"` // advance the clock first (timers fire)
+	Action  string   `json:"action,omitempty"`
+	Player  string   `json:"player,omitempty"` // actor name
+	Target  string   `json:"target,omitempty"` // target name (vote, night)
+	Role    string   `json:"role,omitempty"`   // night: act as this role (default the actor's)
+	Message string   `json:"message,omitempty"`
+	To      string   `json:"to,omitempty"` // advance: keep advancing until this phase
+
+	Expect *Expect `json:"expect,omitempty"`
+}
+
+// Expect asserts on the events emitted since the previous Expect and on the state.
+type Expect struct {
+	// Events must be emitted in this order; other events may come in between
+	Events []EventMatch `json:"events,omitempty"`
+	// Absent events must not be emitted
+	Absent []EventMatch `json:"absent,omitempty"`
+	State  *StateMatch  `json:"state,omitempty"`
+}
+
+// EventMatch selects events by type and a subset of their JSON fields.
+// Nested objects match as subsets too; arrays must match element by element.
+type EventMatch struct {
+	Type  string         `json:"type"`
+	Match map[string]any `json:"match,omitempty"`
+
+	// players (by name) who must or must not be able to see the event
+	VisibleTo  []string `json:"visible_to,omitempty"`
+	HiddenFrom []string `json:"hidden_from,omitempty"`
+}
+
+// StateMatch checks the game state. Empty fields are not checked.
+type StateMatch struct {
+	Phase  string            `json:"phase,omitempty"`
+	Round  int               `json:"round,omitempty"`
+	Winner string            `json:"winner,omitempty"`
+	Alive  []string          `json:"alive,omitempty"` // these players are alive
+	Dead   []string          `json:"dead,omitempty"`  // these players are dead
+	Votes  map[string]string `json:"votes,omitempty"` // voter -> target, exactly these votes
+}
+
+// Duration is a time.Duration written as a string in JSON ("90s", "2m").
+type Duration = domain.Duration
+
+// Load reads and validates a scenario file.
+func Load(path string) (*Scenario, error) {
+	data, err := os.ReadFile(path) // #nosec G304 -- scenario files are chosen by the developer
+	if err != nil {
+		return nil, fmt.Errorf("failed to read scenario %s: %w", path, err)
+	}
+	sc, err := Parse(data)
+	if err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+	return sc, nil
+}
+
+// Parse decodes a JSON scenario and validates it. Unknown fields are rejected,
+// so a typo doesn't silently turn an assertion off.
+func Parse(data []byte) (*Scenario, error) {
+	var sc Scenario
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(&sc); err != nil {
+		return nil, fmt.Errorf("failed to parse scenario: %w", err)
+	}
+	if sc.Seed == 0 {
+		sc.Seed = DefaultSeed
+	}
+	if err := sc.Validate(); err != nil {
+		return nil, err
+	}
+	return &sc, nil
+}
+
+// Validate checks the scenario refers to its own players and known actions.
+func (sc *Scenario) Validate() error {
+	if sc.Name == "" {
+		return errors.New("scenario name must not be empty")
+	}
+	if len(sc.Players) == 0 {
+		return errors.New("scenario needs players")
+	}
+
+	names := make(map[string]bool, len(sc.Players))
+	for _, player := range sc.Players {
+		if player.Name == "" {
+			return errors.New("player name must not be empty")
+		}
+		if names[player.Name] {
+			return fmt.Errorf("duplicate player %q", player.Name)
+		}
+		names[player.Name] = true
+		if _, ok := domain.ParseRole(player.Role); !ok {
+			return fmt.Errorf("player %s: unknown role %q", player.Name, player.Role)
+		}
+	}
+	if err := sc.RoleCounts().Validate(len(sc.Players)); err != nil {
+		return err
+	}
+
+	checkPlayer := func(where, name string) error {
+		if !names[name] {
+			return fmt.Errorf("%s: unknown player %q", where, name)
+		}
+		return nil
+	}
+
+	for i, step := range sc.Steps {
+		where := fmt.Sprintf("step %d", i+1)
+		switch step.Action {
+		case ActionVote, ActionNight:
+			if err := checkPlayer(where, step.Player); err != nil {
+				return err
+			}
+			if err := checkPlayer(where, step.Target); err != nil {
+				return err
+			}
+		case ActionChat, ActionMafiaChat:
+			if err := checkPlayer(where, step.Player); err != nil {
+				return err
+			}
+		case ActionAdvance:
+			if _, ok := domain.ParsePhase(step.To); step.To != "" && !ok {
+				return fmt.Errorf("%s: unknown phase %q", where, step.To)
+			}
+		case "":
+			if step.Expect == nil && step.After == 0 {
+				return fmt.Errorf("%s: needs an action, a delay or an expect", where)
+			}
+		default:
+			return fmt.Errorf("%s: unknown action %q", where, step.Action)
+		}
+		if step.After < 0 {
+			return fmt.Errorf("%s: negative delay", where)
+		}
+		if err := step.Expect.validate(where, names); err != nil {
+			return err
+		}
+	}
+
+	return sc.Expect.validate("expect", names)
+}
+
+// RoleCounts returns the distribution the scenario's roster deals.
+func (sc *Scenario) RoleCounts() domain.RoleCounts {
+	roles := make([]domain.Role, len(sc.Players))
+	for i, player := range sc.Players {
+		roles[i], _ = domain.ParseRole(player.Role)
+	}
+	return domain.RoleCountsOf(roles)
+}
+
+func (e *Expect) validate(where string, names map[string]bool) error {
+	if e == nil {
+		return nil
+	}
+	for _, match := range append(append([]EventMatch(nil), e.Events...), e.Absent...) {
+		if match.Type == "" {
+			return fmt.Errorf("%s: event match needs a type", where)
+		}
+		for _, name := range append(append([]string(nil), match.VisibleTo...), match.HiddenFrom...) {
+			if !names[name] {
+				return fmt.Errorf("%s: unknown player %q", where, name)
+			}
+		}
+		if err := checkRefs(where, match.Match, names); err != nil {
+			return err
+		}
+	}
+
+	if state := e.State; state != nil {
+		if _, ok := domain.ParsePhase(state.Phase); state.Phase != "" && !ok {
+			return fmt.Errorf("%s: unknown phase %q", where, state.Phase)
+		}
+		players := append(append([]string(nil), state.Alive...), state.Dead...)
+		for voter, target := range state.Votes {
+			players = append(players, voter, target)
+		}
+		for _, name := range players {
+			if !names[name] {
+				return fmt.Errorf("%s: unknown player %q", where, name)
+			}
+		}
+	}
+	return nil
+}
+
+// checkRefs makes sure every "@Name" in a match refers to a player.
+func checkRefs(where string, value any, names map[string]bool) error {
+	switch v := value.(type) {
+	case string:
+		if name, ok := strings.CutPrefix(v, PlayerRefPrefix); ok && !names[name] {
+			return fmt.Errorf("%s: unknown player %q", where, v)
+		}
+	case map[string]any:
+		for _, field := range v {
+			if err := checkRefs(where, field, names); err != nil {
+				return err
+			}
+		}
+	case []any:
+		for _, item := range v {
+			if err := checkRefs(where, item, names); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
@@ -0,0 +1,146 @@
+package scenario
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/config"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	return cfg
+}
+
+const roster = `"players": [
+	{"name": "Alice", "role": "mafia"},
+	{"name": "Bruno", "role": "mafia"},
+	{"name": "Cleo", "role": "doctor"},
+	{"name": "Dmitri", "role": "sheriff"},
+	{"name": "Esme", "role": "villager"},
+	{"name": "Finn", "role": "villager"}
+]`
+
+func parse(t *testing.T, body string) *Scenario {
+	t.Helper()
+	sc, err := Parse([]byte(`{"name": "test", ` + roster + `, ` + body + `}`))
+	if err != nil {
+		t.Fatalf("Parse failed: %v", err)
+	}
+	return sc
+}
+
+// TestShippedScenarios runs the regression scenarios in scenarios/.
+func TestShippedScenarios(t *testing.T) {
+	paths, err := filepath.Glob("../../scenarios/*.json")
+	if err != nil || len(paths) == 0 {
+		t.Fatalf("no scenarios found: %v", err)
+	}
+
+	cfg := testConfig(t)
+	for _, path := range paths {
+		t.Run(filepath.Base(path), func(t *testing.T) {
+			sc, err := Load(path)
+			if err != nil {
+				t.Fatalf("Load failed: %v", err)
+			}
+			outcome, err := Run(cfg, sc)
+			if err != nil {
+				t.Fatalf("Run failed: %v", err)
+			}
+			for _, failure := range outcome.Failures {
+				t.Error(failure)
+			}
+		})
+	}
+}
+
+func TestRun_ReportsFailures(t *testing.T) {
+	sc := parse(t, `"steps": [
+		{"action": "night", "player": "Alice", "target": "Esme"},
+		{"action": "advance"}
+	],
+	"expect": {
+		"events": [{"type": "player_eliminated", "match": {"player_id": "@Finn"}}],
+		"absent": [{"type": "phase_changed"}],
+		"state": {"phase": "voting", "alive": ["Esme"]}
+	}`)
+
+	outcome, err := Run(testConfig(t), sc)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	want := []string{
+		`expect: expected player_eliminated {"player_id":"@Finn"} (in order)`,
+		"expect: unexpected phase_changed",
+		"expect: phase is day, expected voting",
+		"expect: Esme is dead, expected alive",
+	}
+	if strings.Join(outcome.Failures, "\n") != strings.Join(want, "\n") {
+		t.Errorf("unexpected failures:\n%s", strings.Join(outcome.Failures, "\n"))
+	}
+}
+
+func TestRun_ExpectationsOnlySeeNewEvents(t *testing.T) {
+	sc := parse(t, `"steps": [
+		{"action": "chat", "player": "Esme", "message": "hi",
+		 "expect": {"events": [{"type": "all_chat", "match": {"sender": "@Esme"}}]}},
+		{"action": "chat", "player": "Finn", "message": "hello"}
+	],
+	"expect": {
+		"events": [{"type": "all_chat", "match": {"sender": "@Finn"}}],
+		"absent": [{"type": "all_chat", "match": {"sender": "@Esme"}}]
+	}`)
+
+	outcome, err := Run(testConfig(t), sc)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !outcome.Passed() {
+		t.Errorf("unexpected failures: %v", outcome.Failures)
+	}
+}
+
+func TestRun_DelaysFireTimeouts(t *testing.T) {
+	cfg := testConfig(t)
+	// the whole night plus half the day
+	after := (cfg.PhaseNightTimeout + cfg.PhaseDayTimeout/2).String()
+	sc := parse(t, `"steps": [{"after": "`+after+`", "expect": {"state": {"phase": "day", "round": 1}}}]`)
+
+	outcome, err := Run(cfg, sc)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if !outcome.Passed() {
+		t.Errorf("unexpected failures: %v", outcome.Failures)
+	}
+}
+
+func TestParse_Rejects(t *testing.T) {
+	tests := map[string]string{
+		"unknown field":   `{"name": "x", ` + roster + `, "stpes": []}`,
+		"no name":         `{` + roster + `}`,
+		"unknown role":    `{"name": "x", "players": [{"name": "A", "role": "jester"}]}`,
+		"no mafia":        `{"name": "x", "players": [{"name": "A", "role": "villager"}, {"name": "B", "role": "villager"}]}`,
+		"unknown player":  `{"name": "x", ` + roster + `, "steps": [{"action": "vote", "player": "Zed", "target": "Alice"}]}`,
+		"unknown action":  `{"name": "x", ` + roster + `, "steps": [{"action": "dance"}]}`,
+		"unknown phase":   `{"name": "x", ` + roster + `, "steps": [{"action": "advance", "to": "dusk"}]}`,
+		"bad duration":    `{"name": "x", ` + roster + `, "steps": [{"after": "soon"}]}`,
+		"empty step":      `{"name": "x", ` + roster + `, "steps": [{}]}`,
+		"unknown ref":     `{"name": "x", ` + roster + `, "expect": {"events": [{"type": "all_chat", "match": {"sender": "@Zed"}}]}}`,
+		"unknown viewer":  `{"name": "x", ` + roster + `, "expect": {"events": [{"type": "all_chat", "visible_to": ["Zed"]}]}}`,
+		"untyped match":   `{"name": "x", ` + roster + `, "expect": {"absent": [{"match": {}}]}}`,
+		"unknown voter":   `{"name": "x", ` + roster + `, "expect": {"state": {"votes": {"Zed": "Alice"}}}}`,
+		"duplicate names": `{"name": "x", "players": [{"name": "A", "role": "mafia"}, {"name": "A", "role": "villager"}, {"name": "B", "role": "villager"}]}`,
+	}
+	for name, data := range tests {
+		if _, err := Parse([]byte(data)); err == nil {
+			t.Errorf("%s: expected error", name)
+		}
+	}
+}
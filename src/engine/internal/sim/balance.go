@@ -0,0 +1,188 @@
+package sim
+
+import (
+	"errors"
+	"fmt"
+	"math"
+	"sort"
+
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+)
+
+// BalanceOptions configures a balance analysis.
+type BalanceOptions struct {
+	MinPlayers, MaxPlayers int
+	GamesPerSetup          int      // games played for each candidate setup
+	Seed                   int64    // seed of the first game of every candidate
+	Strategies             []string // reference bots
+	MaxRounds              int
+}
+
+// Setup is one candidate role distribution and how it played.
+type Setup struct {
+	Players int               `json:"players"`
+	Roles   domain.RoleCounts `json:"roles"`
+
+	Games     int `json:"games"`
+	Completed int `json:"completed"`
+	MafiaWins int `json:"mafia_wins"`
+
+	// MafiaWinRate and its 95% Wilson score interval, over completed games
+	MafiaWinRate float64 `json:"mafia_win_rate"`
+	Low          float64 `json:"ci_low"`
+	High         float64 `json:"ci_high"`
+}
+
+// Imbalance is the distance of the mafia win rate from a fair 50%.
+func (s *Setup) Imbalance() float64 {
+	return math.Abs(s.MafiaWinRate - 0.5)
+}
+
+// BalanceReport lists every candidate per player count and the recommended one.
+type BalanceReport struct {
+	Candidates  map[int][]*Setup `json:"candidates"`
+	Recommended map[int]*Setup   `json:"recommended"`
+}
+
+// CandidateSetups lists the role distributions worth trying for n players:
+// every mafia count that doesn't win at once, with and without doctor and sheriff.
+func CandidateSetups(n int) []domain.RoleCounts {
+	var candidates []domain.RoleCounts
+	for mafia := 1; 2*mafia < n; mafia++ {
+		for doctor := 0; doctor <= 1; doctor++ {
+			for sheriff := 0; sheriff <= 1; sheriff++ {
+				counts := domain.RoleCounts{Mafia: mafia, Doctor: doctor, Sheriff: sheriff}
+				if counts.Validate(n) == nil {
+					candidates = append(candidates, counts)
+				}
+			}
+		}
+	}
+	return candidates
+}
+
+// AnalyzeBalance plays every candidate setup for every player count and picks,
+// per player count, the setup whose mafia win rate is closest to 50%.
+// Each candidate plays the same seeds, so setups are compared on equal footing.
+func AnalyzeBalance(cfg *config.Config, opts BalanceOptions) (*BalanceReport, error) {
+	if opts.MinPlayers <= 0 || opts.MaxPlayers < opts.MinPlayers {
+		return nil, fmt.Errorf("invalid player range %d..%d", opts.MinPlayers, opts.MaxPlayers)
+	}
+	if opts.GamesPerSetup <= 0 {
+		return nil, errors.New("games per setup must be > 0")
+	}
+
+	report := &BalanceReport{
+		Candidates:  make(map[int][]*Setup),
+		Recommended: make(map[int]*Setup),
+	}
+
+	for n := opts.MinPlayers; n <= opts.MaxPlayers; n++ {
+		for _, counts := range CandidateSetups(n) {
+			setup, err := playSetup(cfg, opts, n, counts)
+			if err != nil {
+				return nil, err
+			}
+			report.Candidates[n] = append(report.Candidates[n], setup)
+		}
+
+		if best := recommend(report.Candidates[n]); best != nil {
+			report.Recommended[n] = best
+		}
+	}
+
+	return report, nil
+}
+
+// Ruleset turns the recommendations into a loadable ruleset.
+func (r *BalanceReport) Ruleset(name string) *domain.Ruleset {
+	ruleset := &domain.Ruleset{
+		Name:          name,
+		Distributions: make(map[int]domain.RoleCounts, len(r.Recommended)),
+	}
+	for n, setup := range r.Recommended {
+		ruleset.Distributions[n] = setup.Roles
+	}
+	return ruleset
+}
+
+func playSetup(cfg *config.Config, opts BalanceOptions, n int, counts domain.RoleCounts) (*Setup, error) {
+	ruleset := &domain.Ruleset{
+		Name:          fmt.Sprintf("%dp-%dm-%dd-%ds", n, counts.Mafia, counts.Doctor, counts.Sheriff),
+		Distributions: map[int]domain.RoleCounts{n: counts},
+	}
+
+	setup := &Setup{Players: n, Roles: counts}
+	for i := 0; i < opts.GamesPerSetup; i++ {
+		result, err := Run(cfg, Game{
+			Seed:       opts.Seed + int64(i),
+			Players:    n,
+			Ruleset:    ruleset,
+			Strategies: opts.Strategies,
+			MaxRounds:  opts.MaxRounds,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", ruleset.Name, err)
+		}
+
+		setup.Games++
+		if result.Completed {
+			setup.Completed++
+			if result.Winner == domain.WinnerMafia.String() {
+				setup.MafiaWins++
+			}
+		}
+	}
+
+	if setup.Completed > 0 {
+		setup.MafiaWinRate = float64(setup.MafiaWins) / float64(setup.Completed)
+	}
+	setup.Low, setup.High = WilsonInterval(setup.MafiaWins, setup.Completed, Z95)
+	return setup, nil
+}
+
+// recommend picks the setup closest to 50%; ties go to the tighter interval,
+// then to the setup with more special roles (more to do for the players).
+func recommend(setups []*Setup) *Setup {
+	var played []*Setup
+	for _, setup := range setups {
+		if setup.Completed > 0 {
+			played = append(played, setup)
+		}
+	}
+	if len(played) == 0 {
+		return nil
+	}
+
+	sort.SliceStable(played, func(i, j int) bool {
+		a, b := played[i], played[j]
+		if a.Imbalance() != b.Imbalance() {
+			return a.Imbalance() < b.Imbalance()
+		}
+		if width := (a.High - a.Low) - (b.High - b.Low); width != 0 {
+			return width < 0
+		}
+		return a.Roles.Doctor+a.Roles.Sheriff > b.Roles.Doctor+b.Roles.Sheriff
+	})
+	return played[0]
+}
+
+// Z95 is the normal quantile for a two-sided 95% confidence interval,
+// to pass to WilsonInterval.
+const Z95 = 1.959964
+
+// WilsonInterval returns the Wilson score interval for successes out of n trials.
+// Unlike the normal approximation it stays inside [0, 1] for rates near 0 or 1.
+func WilsonInterval(successes, n int, z float64) (low, high float64) {
+	if n == 0 {
+		return 0, 1
+	}
+	p := float64(successes) / float64(n)
+	nf := float64(n)
+	z2 := z * z
+
+	center := (p + z2/(2*nf)) / (1 + z2/nf)
+	margin := z / (1 + z2/nf) * math.Sqrt(p*(1-p)/nf+z2/(4*nf*nf))
+	return math.Max(0, center-margin), math.Min(1, center+margin)
+}
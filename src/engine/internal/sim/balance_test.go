@@ -0,0 +1,108 @@
+package sim
+
+import (
+	"encoding/json"
+	"math"
+	"testing"
+
+	"mafia-engine/internal/domain"
+)
+
+func TestWilsonInterval(t *testing.T) {
+	low, high := WilsonInterval(50, 100, Z95)
+	if math.Abs(low-0.404) > 0.001 || math.Abs(high-0.596) > 0.001 {
+		t.Errorf("50/100: got [%.3f, %.3f], want about [0.404, 0.596]", low, high)
+	}
+
+	// stays inside [0, 1] at the edges
+	low, high = WilsonInterval(0, 10, Z95)
+	if low != 0 || high <= 0 || high >= 1 {
+		t.Errorf("0/10: got [%v, %v]", low, high)
+	}
+	low, high = WilsonInterval(10, 10, Z95)
+	if math.Abs(high-1) > 1e-9 || low <= 0 {
+		t.Errorf("10/10: got [%v, %v]", low, high)
+	}
+
+	if low, high := WilsonInterval(0, 0, Z95); low != 0 || high != 1 {
+		t.Errorf("no games should give [0, 1], got [%v, %v]", low, high)
+	}
+}
+
+func TestCandidateSetups(t *testing.T) {
+	// 6 players: 1 or 2 mafia, doctor and sheriff each optional
+	if got := CandidateSetups(6); len(got) != 8 {
+		t.Errorf("expected 8 candidates for 6 players, got %d", len(got))
+	}
+	for _, counts := range CandidateSetups(7) {
+		if err := counts.Validate(7); err != nil {
+			t.Errorf("invalid candidate %+v: %v", counts, err)
+		}
+	}
+}
+
+func TestRecommend(t *testing.T) {
+	fair := &Setup{Roles: domain.RoleCounts{Mafia: 1}, Completed: 100, MafiaWinRate: 0.52, Low: 0.42, High: 0.62}
+	fairer := &Setup{Roles: domain.RoleCounts{Mafia: 2}, Completed: 100, MafiaWinRate: 0.49, Low: 0.39, High: 0.59}
+	unplayed := &Setup{Roles: domain.RoleCounts{Mafia: 3}}
+
+	if got := recommend([]*Setup{fair, unplayed, fairer}); got != fairer {
+		t.Errorf("expected the setup closest to 50%%, got %+v", got)
+	}
+
+	// equal rates: more special roles wins
+	plain := &Setup{Roles: domain.RoleCounts{Mafia: 1}, Completed: 10, MafiaWinRate: 0.5, Low: 0.2, High: 0.8}
+	rich := &Setup{Roles: domain.RoleCounts{Mafia: 1, Doctor: 1}, Completed: 10, MafiaWinRate: 0.5, Low: 0.2, High: 0.8}
+	if got := recommend([]*Setup{plain, rich}); got != rich {
+		t.Errorf("expected the setup with more roles, got %+v", got)
+	}
+
+	if recommend([]*Setup{unplayed}) != nil {
+		t.Error("expected no recommendation without completed games")
+	}
+}
+
+func TestAnalyzeBalance(t *testing.T) {
+	report, err := AnalyzeBalance(testConfig(t), BalanceOptions{
+		MinPlayers:    6,
+		MaxPlayers:    7,
+		GamesPerSetup: 4,
+		Seed:          1,
+		Strategies:    []string{"random"},
+	})
+	if err != nil {
+		t.Fatalf("AnalyzeBalance failed: %v", err)
+	}
+
+	for _, n := range []int{6, 7} {
+		if len(report.Candidates[n]) != len(CandidateSetups(n)) {
+			t.Errorf("%d players: expected every candidate to be played", n)
+		}
+		if report.Recommended[n] == nil {
+			t.Errorf("%d players: no recommendation", n)
+		}
+	}
+
+	// the output must load as a ruleset
+	data, err := json.Marshal(report.Ruleset("balanced"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ruleset, err := domain.ParseRuleset(data)
+	if err != nil {
+		t.Fatalf("generated ruleset doesn't load: %v", err)
+	}
+	if ruleset.Distributions[6] != report.Recommended[6].Roles {
+		t.Errorf("ruleset doesn't match the recommendation: %+v", ruleset.Distributions)
+	}
+}
+
+func TestAnalyzeBalance_Validation(t *testing.T) {
+	cfg := testConfig(t)
+	if _, err := AnalyzeBalance(cfg, BalanceOptions{MinPlayers: 8, MaxPlayers: 6, GamesPerSetup: 1}); err == nil {
+		t.Error("expected error for an empty player range")
+	}
+	if _, err := AnalyzeBalance(cfg, BalanceOptions{MinPlayers: 6, MaxPlayers: 6}); err == nil {
+		t.Error("expected error without games")
+	}
+}
@@ -0,0 +1,219 @@
+package sim
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/kafka"
+)
+
+// Branch is a what-if on a recorded game: "what if the doctor had saved X".
+//
+// The journal is replayed through an engine up to command At, the decision
+// there is changed, the rest of that phase plays out as recorded (commands the
+// change made invalid are skipped) and bots play every later phase. The branch
+// runs many times with different bot seeds to estimate the outcome distribution.
+// Every run starts from a copy of the recorded initial state and plays on a
+// virtual clock, as Run does.
+type Branch struct {
+	Config  *config.Config // timeouts and limits of the engine; the ruleset and pins come from the journal
+	Journal *engine.Journal
+	At      int // index of the recorded command to change
+
+	// Override replaces the command at At; nil keeps the recorded game (the baseline).
+	// With Insert, the override is applied before the command at At instead.
+	Override []engine.Command
+	Insert   bool
+
+	Strategies []string // bots for the rest of the game, dealt by seat
+	Runs       int
+	Seed       int64 // run i uses bot seed Seed+i
+	MaxRounds  int   // 0 = DefaultMaxRounds
+}
+
+// BranchReport compares a branch to the recorded game, played on the same bot seeds.
+type BranchReport struct {
+	Baseline *Report `json:"baseline"`
+	Branch   *Report `json:"branch"`
+
+	// Shift is each faction's win rate in the branch minus the baseline
+	Shift map[string]float64 `json:"shift"`
+}
+
+// RunBranch plays the branch b.Runs times.
+func RunBranch(b Branch) ([]*Result, error) {
+	if b.Config == nil {
+		return nil, errors.New("config must not be nil")
+	}
+	if b.Journal == nil {
+		return nil, errors.New("journal must not be nil")
+	}
+	if b.Runs <= 0 {
+		return nil, errors.New("runs must be > 0")
+	}
+	if b.At < 0 || b.At > b.Journal.Len() {
+		return nil, fmt.Errorf("command %d out of range (journal has %d)", b.At, b.Journal.Len())
+	}
+
+	results := make([]*Result, 0, b.Runs)
+	for i := 0; i < b.Runs; i++ {
+		result, err := playBranch(b, b.Seed+int64(i))
+		if err != nil {
+			return nil, err
+		}
+		results = append(results, result)
+	}
+	return results, nil
+}
+
+// CompareBranch runs the branch and the unchanged game and reports the difference.
+func CompareBranch(b Branch) (*BranchReport, error) {
+	if b.Override == nil {
+		return nil, errors.New("branch has no override")
+	}
+
+	branched, err := RunBranch(b)
+	if err != nil {
+		return nil, err
+	}
+	baseline := b
+	baseline.Override = nil
+	unchanged, err := RunBranch(baseline)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &BranchReport{
+		Baseline: Summarize(unchanged),
+		Branch:   Summarize(branched),
+		Shift:    make(map[string]float64),
+	}
+	for faction, rate := range report.Branch.WinRate {
+		report.Shift[faction] = rate - report.Baseline.WinRate[faction]
+	}
+	return report, nil
+}
+
+// WriteText prints the baseline, the branch and the shift in win rates.
+func (r *BranchReport) WriteText(w io.Writer) {
+	r.Baseline.writeText(w, "as recorded")
+	r.Branch.writeText(w, "branch")
+	fmt.Fprintf(w, "== win rate shift: village %+.1f%%, mafia %+.1f%%\n",
+		100*r.Shift[domain.WinnerVillage.String()], 100*r.Shift[domain.WinnerMafia.String()])
+}
+
+// playBranch plays one run of the branch.
+func playBranch(b Branch, seed int64) (*Result, error) {
+	maxRounds := b.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = DefaultMaxRounds
+	}
+
+	strategies, err := bots.NewStrategies(b.Strategies)
+	if err != nil {
+		return nil, err
+	}
+
+	state := b.Journal.Initial()
+	if state == nil {
+		return nil, errors.New("journal has no initial state")
+	}
+	commands := b.Journal.Commands()
+
+	// The recorded start carries the ruleset and the pinned roles
+	runCfg := *b.Config
+	runCfg.RulesetFile, runCfg.PinnedRoles = "", nil
+	var ruleset *domain.Ruleset
+	for _, cmd := range commands {
+		if start, ok := cmd.(*engine.StartGameCommand); ok {
+			ruleset = start.Ruleset
+			break
+		}
+	}
+
+	result := &Result{Seed: seed, State: state}
+	t, err := newTable(state, &runCfg, ruleset, strategies, seed, result)
+	if err != nil {
+		return nil, err
+	}
+	defer t.close()
+
+	// submit applies a recorded command and reports whether the game accepted
+	// it. Bots watch to learn their roles and what happened, but the recorded
+	// players act for them, so their actions are dropped.
+	ctx := context.Background()
+	discard := func(context.Context, kafka.Message) error { return nil }
+	submit := func(cmd engine.Command) (bool, error) {
+		applied := result.Journal.Len()
+		if err := t.engine.Submit(cmd); err != nil {
+			return false, err
+		}
+		t.engine.ProcessPending()
+		if _, err := t.botEvents.Poll(ctx, t.bots.HandleMessage); err != nil {
+			return false, err
+		}
+		if _, err := t.actions.Poll(ctx, discard); err != nil {
+			return false, err
+		}
+		return result.Journal.Len() > applied, nil
+	}
+
+	// Replay up to the fork
+	for i, cmd := range commands[:b.At] {
+		accepted, err := submit(cmd)
+		if err != nil {
+			return nil, err
+		}
+		if !accepted {
+			return nil, fmt.Errorf("replaying command %d (%T): rejected", i, cmd)
+		}
+	}
+	if state.Phase == domain.PhaseWaiting {
+		return nil, errors.New("fork point is before the game started")
+	}
+	phase, round := state.Phase, state.Round
+
+	rest := commands[b.At:]
+	if b.Override != nil {
+		for _, cmd := range b.Override {
+			accepted, err := submit(cmd)
+			if err != nil {
+				return nil, err
+			}
+			if !accepted {
+				return nil, fmt.Errorf("override %T rejected in %s of round %d", cmd, phase, round)
+			}
+		}
+		if !b.Insert && len(rest) > 0 {
+			rest = rest[1:]
+		}
+	}
+
+	// The rest of the phase as recorded (unless the override ended it).
+	// Commands the override invalidated (e.g. a vote for a dead player) are dropped.
+	for _, cmd := range rest {
+		if state.Phase != phase || state.Round != round {
+			break
+		}
+		if _, isPhaseChange := cmd.(*engine.PhaseChangeCommand); isPhaseChange {
+			break
+		}
+		if _, err := submit(cmd); err != nil {
+			return nil, err
+		}
+	}
+
+	// Bots from here on: the phase times out and they play every later one
+	if err := t.play(maxRounds); err != nil {
+		return nil, err
+	}
+	result.Players = len(state.Players)
+	result.finish(state)
+	return result, nil
+}
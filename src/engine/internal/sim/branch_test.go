@@ -0,0 +1,172 @@
+package sim
+
+import (
+	"reflect"
+	"testing"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+)
+
+// firstNight finds a recorded game where the mafia killed on night 1 and
+// returns it with the branch "what if the doctor had saved the victim".
+func firstNight(t *testing.T) (*Result, Branch) {
+	t.Helper()
+	cfg := testConfig(t)
+
+	for seed := int64(1); seed <= 50; seed++ {
+		result, err := Run(cfg, testGame(seed))
+		if err != nil {
+			t.Fatalf("Run failed: %v", err)
+		}
+		night := result.State.Nights[0]
+		doctors := result.State.GetPlayersWithRole(domain.RoleDoctor)
+		if night.Killed == "" || len(doctors) == 0 {
+			continue
+		}
+
+		save := &engine.NightActionCommand{
+			Role:     domain.RoleDoctor.String(),
+			ActorID:  doctors[0].ID,
+			TargetID: night.Killed,
+		}
+		branch := Branch{
+			Config:     cfg,
+			Journal:    result.Journal,
+			Override:   []engine.Command{save},
+			Strategies: testGame(seed).Strategies,
+			Runs:       5,
+			Seed:       100,
+		}
+
+		// replace the doctor's recorded action, or add one before the night ends
+		for i, cmd := range result.Journal.Commands() {
+			switch c := cmd.(type) {
+			case *engine.NightActionCommand:
+				if c.Role == domain.RoleDoctor.String() {
+					branch.At = i
+					return result, branch
+				}
+			case *engine.PhaseChangeCommand:
+				if c.NewPhase == domain.PhaseDay {
+					branch.At, branch.Insert = i, true
+					return result, branch
+				}
+			}
+		}
+	}
+	t.Fatal("no seed with a night 1 kill")
+	return nil, Branch{}
+}
+
+func TestRunBranch_OverrideChangesTheNight(t *testing.T) {
+	recorded, branch := firstNight(t)
+	victim := recorded.State.Nights[0].Killed
+
+	results, err := RunBranch(branch)
+	if err != nil {
+		t.Fatalf("RunBranch failed: %v", err)
+	}
+	if len(results) != branch.Runs {
+		t.Fatalf("expected %d results, got %d", branch.Runs, len(results))
+	}
+	for _, result := range results {
+		if !result.Completed {
+			t.Errorf("seed %d: branch did not finish", result.Seed)
+		}
+		if night := result.State.Nights[0]; !night.Saved() || night.Killed != "" {
+			t.Errorf("seed %d: expected %s saved on night 1, got %+v", result.Seed, victim, night)
+		}
+
+		// an engine played the branch: it asked for actions and journaled the game
+		requested := false
+		for _, raw := range result.Events {
+			ev, err := events.DeserializeEngineEvent(raw)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if _, ok := ev.(*events.ActionRequested); ok {
+				requested = true
+			}
+		}
+		if !requested {
+			t.Errorf("seed %d: no action requests", result.Seed)
+		}
+		final, err := result.Journal.StateAt(result.Journal.Len())
+		if err != nil || final.Winner != result.State.Winner {
+			t.Errorf("seed %d: the branch's journal doesn't rebuild it (%v)", result.Seed, err)
+		}
+	}
+
+	// the recorded game is untouched
+	if recorded.State.Players[victim].Alive {
+		t.Error("branching revived the victim in the recorded game")
+	}
+}
+
+func TestRunBranch_Baseline(t *testing.T) {
+	recorded, branch := firstNight(t)
+	branch.Override = nil
+
+	results, err := RunBranch(branch)
+	if err != nil {
+		t.Fatalf("RunBranch failed: %v", err)
+	}
+	for _, result := range results {
+		if got := result.State.Nights[0]; got != recorded.State.Nights[0] {
+			t.Errorf("baseline changed night 1: got %+v, recorded %+v", got, recorded.State.Nights[0])
+		}
+	}
+
+	// same bot seeds, same futures
+	again, err := RunBranch(branch)
+	if err != nil {
+		t.Fatalf("RunBranch failed: %v", err)
+	}
+	for i := range results {
+		results[i].State, again[i].State = nil, nil
+		if !reflect.DeepEqual(results[i], again[i]) {
+			t.Errorf("run %d differs between identical branches", i)
+		}
+	}
+}
+
+func TestCompareBranch(t *testing.T) {
+	_, branch := firstNight(t)
+
+	report, err := CompareBranch(branch)
+	if err != nil {
+		t.Fatalf("CompareBranch failed: %v", err)
+	}
+	if report.Baseline.Games != branch.Runs || report.Branch.Games != branch.Runs {
+		t.Errorf("expected %d games on both sides, got %d and %d", branch.Runs, report.Baseline.Games, report.Branch.Games)
+	}
+	for faction, shift := range report.Shift {
+		if want := report.Branch.WinRate[faction] - report.Baseline.WinRate[faction]; shift != want {
+			t.Errorf("%s: shift %v, want %v", faction, shift, want)
+		}
+	}
+}
+
+func TestRunBranch_Errors(t *testing.T) {
+	_, branch := firstNight(t)
+
+	invalid := branch
+	invalid.Override = []engine.Command{&engine.VoteCommand{VoterID: "player-1", TargetID: "player-2"}}
+	if _, err := RunBranch(invalid); err == nil {
+		t.Error("expected error for an override the rules reject")
+	}
+
+	early := branch
+	early.At = 0
+	if _, err := RunBranch(early); err == nil {
+		t.Error("expected error for a fork before the game started")
+	}
+
+	outOfRange := branch
+	outOfRange.At = branch.Journal.Len() + 1
+	if _, err := RunBranch(outOfRange); err == nil {
+		t.Error("expected error for a fork point past the end")
+	}
+}
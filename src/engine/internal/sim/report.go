@@ -0,0 +1,147 @@
+package sim
+
+import (
+	"fmt"
+	"io"
+	"sort"
+	"strings"
+
+	"mafia-engine/internal/domain"
+)
+
+// Report aggregates the results of many games.
+type Report struct {
+	Games     int `json:"games"`
+	Completed int `json:"completed"` // games that produced a winner
+
+	Wins    map[string]int     `json:"wins"`     // faction -> games won
+	WinRate map[string]float64 `json:"win_rate"` // faction -> share of completed games
+
+	AvgRounds float64 `json:"avg_rounds"`
+
+	// EliminationOrder[i] is the share of roles among the i-th eliminated players
+	EliminationOrder []map[string]float64 `json:"elimination_order"`
+
+	KillAttempts   int     `json:"kill_attempts"`
+	DoctorSaves    int     `json:"doctor_saves"`
+	DoctorSaveRate float64 `json:"doctor_save_rate"` // saves per mafia kill attempt
+}
+
+// Summary is the report over all games plus one per ruleset.
+type Summary struct {
+	Overall   *Report            `json:"overall"`
+	ByRuleset map[string]*Report `json:"by_ruleset"`
+}
+
+// Summarize aggregates results.
+func Summarize(results []*Result) *Report {
+	report := &Report{
+		Games:   len(results),
+		Wins:    make(map[string]int),
+		WinRate: make(map[string]float64),
+	}
+
+	var rounds int
+	var positions []map[string]int
+	var positionTotals []int
+
+	for _, result := range results {
+		rounds += result.Rounds
+		report.KillAttempts += result.KillAttempts
+		report.DoctorSaves += result.DoctorSaves
+
+		if result.Completed {
+			report.Completed++
+			report.Wins[result.Winner]++
+		}
+
+		for i, elimination := range result.Eliminations {
+			if i == len(positions) {
+				positions = append(positions, make(map[string]int))
+				positionTotals = append(positionTotals, 0)
+			}
+			positions[i][elimination.Role]++
+			positionTotals[i]++
+		}
+	}
+
+	if report.Games > 0 {
+		report.AvgRounds = float64(rounds) / float64(report.Games)
+	}
+	for _, faction := range []string{domain.WinnerVillage.String(), domain.WinnerMafia.String()} {
+		if _, ok := report.Wins[faction]; !ok {
+			report.Wins[faction] = 0 // always report both factions
+		}
+		if report.Completed > 0 {
+			report.WinRate[faction] = float64(report.Wins[faction]) / float64(report.Completed)
+		}
+	}
+	if report.KillAttempts > 0 {
+		report.DoctorSaveRate = float64(report.DoctorSaves) / float64(report.KillAttempts)
+	}
+
+	for i, counts := range positions {
+		shares := make(map[string]float64, len(counts))
+		for role, count := range counts {
+			shares[role] = float64(count) / float64(positionTotals[i])
+		}
+		report.EliminationOrder = append(report.EliminationOrder, shares)
+	}
+
+	return report
+}
+
+// SummarizeByRuleset builds the overall report and one report per ruleset.
+func SummarizeByRuleset(results []*Result) *Summary {
+	grouped := make(map[string][]*Result)
+	for _, result := range results {
+		grouped[result.Ruleset] = append(grouped[result.Ruleset], result)
+	}
+
+	summary := &Summary{
+		Overall:   Summarize(results),
+		ByRuleset: make(map[string]*Report, len(grouped)),
+	}
+	for name, group := range grouped {
+		summary.ByRuleset[name] = Summarize(group)
+	}
+	return summary
+}
+
+// WriteText prints a human readable summary.
+func (s *Summary) WriteText(w io.Writer) {
+	names := make([]string, 0, len(s.ByRuleset))
+	for name := range s.ByRuleset {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	s.Overall.writeText(w, "all games")
+	if len(names) > 1 {
+		for _, name := range names {
+			s.ByRuleset[name].writeText(w, "ruleset "+name)
+		}
+	}
+}
+
+func (r *Report) writeText(w io.Writer, title string) {
+	fmt.Fprintf(w, "== %s: %d games (%d completed)\n", title, r.Games, r.Completed)
+	fmt.Fprintf(w, "  win rate:         village %.1f%%, mafia %.1f%%\n",
+		100*r.WinRate[domain.WinnerVillage.String()], 100*r.WinRate[domain.WinnerMafia.String()])
+	fmt.Fprintf(w, "  avg rounds:       %.2f\n", r.AvgRounds)
+	fmt.Fprintf(w, "  doctor save rate: %.1f%% (%d of %d kill attempts)\n", 100*r.DoctorSaveRate, r.DoctorSaves, r.KillAttempts)
+
+	for i, shares := range r.EliminationOrder {
+		roles := make([]string, 0, len(shares))
+		for role := range shares {
+			roles = append(roles, role)
+		}
+		sort.Strings(roles)
+
+		parts := make([]string, 0, len(roles))
+		for _, role := range roles {
+			parts = append(parts, fmt.Sprintf("%s %.0f%%", role, 100*shares[role]))
+		}
+		fmt.Fprintf(w, "  elimination #%-2d   %s\n", i+1, strings.Join(parts, ", "))
+	}
+}
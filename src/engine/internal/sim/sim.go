@@ -0,0 +1,317 @@
+// Package sim runs complete games headlessly: the real engine, commands and
+// domain rules, in-process bots, the in-memory transport and a virtual clock,
+// all stepped on one goroutine so a seed fully determines the game.
+package sim
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/clock"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/kafka"
+)
+
+// DefaultRuleset names games played without a ruleset file.
+const DefaultRuleset = "default"
+
+// DefaultMaxRounds stops a game that doesn't finish (e.g. bots that never agree).
+const DefaultMaxRounds = 50
+
+// Game configures one simulated game.
+type Game struct {
+	Seed        int64
+	Players     int             // number of seats, must fit the config's name pack
+	RulesetFile string          // optional ruleset (role distribution, traits)
+	Ruleset     *domain.Ruleset // in-memory ruleset, wins over RulesetFile
+	Strategies  []string        // bot strategies dealt round-robin, see bots.StrategyNames
+	MaxRounds   int             // 0 = DefaultMaxRounds
+
+	// PinnedRoles fixes roles by seat instead of ENGINE_PINNED_ROLES;
+	// the rest are dealt from the seed
+	PinnedRoles map[int]domain.Role
+}
+
+// Result is the outcome of one simulated game.
+type Result struct {
+	Seed      int64  `json:"seed"`
+	Ruleset   string `json:"ruleset"`
+	Players   int    `json:"players"`
+	Winner    string `json:"winner"` // "none" if the game hit the round limit
+	Rounds    int    `json:"rounds"`
+	Completed bool   `json:"completed"`
+
+	// Eliminations in the order they happened
+	Eliminations []Elimination `json:"eliminations"`
+
+	// Night outcomes: how often the mafia tried to kill and the doctor saved the target
+	KillAttempts int `json:"kill_attempts"`
+	DoctorSaves  int `json:"doctor_saves"`
+
+	// Final state, the commands that led to it and the published events, for
+	// callers that need more than the summary (e.g. to fork the game with
+	// RunBranch, or to archive it; not serialized)
+	State   *domain.GameState `json:"-"`
+	Rules   *domain.Ruleset   `json:"-"` // nil for the default rules
+	Journal *engine.Journal   `json:"-"`
+	Events  []json.RawMessage `json:"-"`
+}
+
+// Elimination is one player leaving the game.
+type Elimination struct {
+	PlayerID string `json:"player_id"`
+	Role     string `json:"role"`
+	Reason   string `json:"reason"`
+}
+
+// Run plays one game to the end (or to the round limit) and returns its result.
+// cfg is copied; the original is not modified.
+func Run(cfg *config.Config, game Game) (*Result, error) {
+	if cfg == nil {
+		return nil, errors.New("config must not be nil")
+	}
+	if game.Players <= 0 {
+		return nil, errors.New("players must be > 0")
+	}
+	maxRounds := game.MaxRounds
+	if maxRounds <= 0 {
+		maxRounds = DefaultMaxRounds
+	}
+
+	runCfg := *cfg
+	runCfg.RulesetFile = "" // loaded here and passed to the engine directly
+	runCfg.GameMinPlayers = game.Players
+	if runCfg.GameMaxPlayers < game.Players {
+		runCfg.GameMaxPlayers = game.Players
+	}
+
+	ruleset := game.Ruleset
+	if ruleset == nil && game.RulesetFile != "" {
+		loaded, err := engine.LoadRuleset(game.RulesetFile)
+		if err != nil {
+			return nil, err
+		}
+		ruleset = loaded
+	}
+	rulesetName := DefaultRuleset
+	if ruleset != nil {
+		rulesetName = ruleset.Name
+	}
+
+	strategies, err := bots.NewStrategies(game.Strategies)
+	if err != nil {
+		return nil, err
+	}
+
+	state := domain.NewGameState("sim")
+	state.ID = fmt.Sprintf("sim-%d", game.Seed)
+	state.Seed = game.Seed
+
+	result := &Result{
+		Seed:    game.Seed,
+		Ruleset: rulesetName,
+		Players: game.Players,
+		State:   state,
+		Rules:   ruleset,
+	}
+	t, err := newTable(state, &runCfg, ruleset, strategies, game.Seed, result)
+	if err != nil {
+		return nil, err
+	}
+	defer t.close()
+	eng := t.engine
+
+	for i := 0; i < game.Players; i++ {
+		// the runtime deals strategies to seats in the same order
+		if err := eng.AddPlayerWithProfile(bots.Profile(game.Strategies[i%len(game.Strategies)])); err != nil {
+			return nil, fmt.Errorf("failed to add player: %w", err)
+		}
+		eng.ProcessPending()
+	}
+	start := eng.StartGame // ENGINE_PINNED_ROLES, if any
+	if game.PinnedRoles != nil {
+		start = func() error { return eng.StartGameWithPinnedRoles(game.PinnedRoles) }
+	}
+	if err := start(); err != nil {
+		return nil, err
+	}
+	eng.ProcessPending()
+	if state.Phase == domain.PhaseWaiting {
+		return nil, fmt.Errorf("game did not start with %d players", game.Players)
+	}
+
+	if err := t.play(maxRounds); err != nil {
+		return nil, err
+	}
+	result.finish(state)
+	return result, nil
+}
+
+// table is a game in progress: the engine on a virtual clock, the bots and
+// the in-memory transport between them, all stepped on the caller's goroutine.
+type table struct {
+	state     *domain.GameState
+	engine    *engine.Engine
+	clock     *clock.Virtual
+	bots      *bots.Runtime
+	broker    *kafka.MemoryBroker
+	botEvents *kafka.MemoryConsumer // engine events for the bots
+	actions   *kafka.MemoryConsumer // the bots' actions for the engine
+	log       *eventLog
+}
+
+// newTable seats the bots at an engine over state. Every event the engine
+// publishes and every command it applies go to result.
+func newTable(state *domain.GameState, cfg *config.Config, ruleset *domain.Ruleset, strategies []bots.Strategy, seed int64, result *Result) (*table, error) {
+	broker := kafka.NewMemoryBroker(1)
+	producer, _ := kafka.NewMemoryProducer(broker)
+	actions, _ := kafka.NewMemoryConsumer(broker, kafka.PlayerActionsTopic, kafka.EngineConsumerGroup)
+	botEvents, _ := kafka.NewMemoryConsumer(broker, kafka.EngineEventsTopic, kafka.BotsConsumerGroup)
+	log := &eventLog{Producer: producer, result: result}
+
+	virtual := clock.NewVirtual(time.Unix(0, 0).UTC())
+	result.Journal = engine.NewJournal()
+	eng, err := engine.NewEngine(state, log, cfg,
+		engine.WithClock(virtual), engine.WithRuleset(ruleset), engine.WithJournal(result.Journal))
+	if err != nil {
+		broker.Close()
+		return nil, err
+	}
+
+	runtime, err := bots.NewRuntime(state.ID, producer, strategies, seed)
+	if err != nil {
+		eng.Stop()
+		broker.Close()
+		return nil, err
+	}
+
+	return &table{
+		state:     state,
+		engine:    eng,
+		clock:     virtual,
+		bots:      runtime,
+		broker:    broker,
+		botEvents: botEvents,
+		actions:   actions,
+		log:       log,
+	}, nil
+}
+
+// close stops the engine and the transport.
+func (t *table) close() {
+	t.engine.Stop()
+	t.broker.Close()
+}
+
+// step delivers the pending events to the bots and their actions to the
+// engine, until nothing moves any more. It returns how much was handled.
+func (t *table) step(ctx context.Context) (int, error) {
+	handleAction := func(ctx context.Context, msg kafka.Message) error {
+		if err := t.engine.HandleMessage(ctx, msg); err != nil {
+			return err
+		}
+		t.engine.ProcessPending()
+		return nil
+	}
+
+	progressed := 0
+	for _, step := range []func() (int, error){
+		func() (int, error) { return t.botEvents.Poll(ctx, t.bots.HandleMessage) },
+		func() (int, error) { return t.actions.Poll(ctx, handleAction) },
+	} {
+		n, err := step()
+		if err != nil {
+			return progressed, err
+		}
+		progressed += n
+	}
+	return progressed + t.engine.ProcessPending(), nil
+}
+
+// play runs the game until it ends or passes maxRounds: step until nothing
+// moves any more, then let the clock run to the next phase timeout. Bots
+// always act before a phase times out.
+func (t *table) play(maxRounds int) error {
+	ctx := context.Background()
+	for t.state.Phase != domain.PhaseEnded && t.state.Round <= maxRounds {
+		progressed, err := t.step(ctx)
+		if err != nil {
+			return err
+		}
+		if progressed == 0 && !t.clock.AdvanceToNext() {
+			return fmt.Errorf("game %s stalled in %s", t.state.ID, t.state.Phase)
+		}
+		t.engine.ProcessPending()
+	}
+	return t.log.err
+}
+
+// eventLog wraps the engine's producer so the result gets every event the
+// engine publishes, observer events (ballots) included, in publish order.
+// The first event it can't decode is kept for Run to return.
+type eventLog struct {
+	kafka.Producer
+	result *Result
+	err    error
+}
+
+func (l *eventLog) Publish(ctx context.Context, msg kafka.Message) error {
+	if err := l.Producer.Publish(ctx, msg); err != nil {
+		return err
+	}
+	if msg.Topic != kafka.EngineEventsTopic && msg.Topic != kafka.ObserverEventsTopic {
+		return nil
+	}
+	if err := l.result.observe(msg); err != nil && l.err == nil {
+		l.err = err
+	}
+	return nil
+}
+
+// observe records what an observer of the game learns.
+func (r *Result) observe(msg kafka.Message) error {
+	ev, err := events.DeserializeEngineEvent(msg.Value)
+	if err != nil {
+		return err
+	}
+	r.Events = append(r.Events, append(json.RawMessage(nil), msg.Value...))
+	r.record(ev)
+	return nil
+}
+
+// record notes the eliminations among the game's events.
+func (r *Result) record(ev any) {
+	if eliminated, ok := ev.(*events.PlayerEliminated); ok {
+		r.Eliminations = append(r.Eliminations, Elimination{
+			PlayerID: eliminated.PlayerID,
+			Reason:   eliminated.Reason,
+		})
+	}
+}
+
+// finish fills in the outcome from the final state.
+func (r *Result) finish(state *domain.GameState) {
+	r.Completed = state.Phase == domain.PhaseEnded
+	r.Winner = state.Winner.String()
+	r.Rounds = state.Round
+	for _, night := range state.Nights {
+		if night.MafiaTarget != "" {
+			r.KillAttempts++
+		}
+		if night.Saved() {
+			r.DoctorSaves++
+		}
+	}
+	for i := range r.Eliminations {
+		if player := state.GetPlayer(r.Eliminations[i].PlayerID); player != nil {
+			r.Eliminations[i].Role = player.Role.String()
+		}
+	}
+}
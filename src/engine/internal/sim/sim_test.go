@@ -0,0 +1,149 @@
+package sim
+
+import (
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+)
+
+func testConfig(t *testing.T) *config.Config {
+	t.Helper()
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatalf("failed to load default config: %v", err)
+	}
+	return cfg
+}
+
+func testGame(seed int64) Game {
+	return Game{
+		Seed:       seed,
+		Players:    6,
+		Strategies: []string{"random", "sheriff-follower", "aggressive-accuser"},
+	}
+}
+
+func TestRun_CompletesGame(t *testing.T) {
+	result, err := Run(testConfig(t), testGame(1))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if !result.Completed {
+		t.Fatalf("expected the game to finish, stopped in round %d", result.Rounds)
+	}
+	if result.Winner != domain.WinnerVillage.String() && result.Winner != domain.WinnerMafia.String() {
+		t.Errorf("unexpected winner %q", result.Winner)
+	}
+	if result.Ruleset != DefaultRuleset {
+		t.Errorf("expected default ruleset, got %q", result.Ruleset)
+	}
+	if len(result.Eliminations) == 0 {
+		t.Fatal("expected eliminations")
+	}
+	for _, elimination := range result.Eliminations {
+		if elimination.Role == "" || elimination.Reason == "" {
+			t.Errorf("incomplete elimination: %+v", elimination)
+		}
+	}
+	if result.KillAttempts < result.DoctorSaves {
+		t.Errorf("more saves (%d) than kill attempts (%d)", result.DoctorSaves, result.KillAttempts)
+	}
+}
+
+func TestRun_SameSeedSameGame(t *testing.T) {
+	cfg := testConfig(t)
+
+	first, err := Run(cfg, testGame(42))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	second, err := Run(cfg, testGame(42))
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	first.State, second.State = nil, nil
+	if !reflect.DeepEqual(first, second) {
+		t.Errorf("same seed produced different games:\n%+v\n%+v", first, second)
+	}
+}
+
+func TestRun_UsesRuleset(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "lean.json")
+	data := `{"name":"lean","distributions":{"6":{"mafia":1,"doctor":0,"sheriff":0}}}`
+	if err := os.WriteFile(path, []byte(data), 0o600); err != nil {
+		t.Fatal(err)
+	}
+
+	game := testGame(3)
+	game.RulesetFile = path
+	result, err := Run(testConfig(t), game)
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+
+	if result.Ruleset != "lean" {
+		t.Errorf("expected ruleset lean, got %q", result.Ruleset)
+	}
+	if got := len(result.State.GetPlayersWithRole(domain.RoleMafia)); got != 1 {
+		t.Errorf("expected 1 mafia from the ruleset, got %d", got)
+	}
+	if result.DoctorSaves != 0 {
+		t.Errorf("no doctor, but %d saves", result.DoctorSaves)
+	}
+}
+
+func TestRun_Validation(t *testing.T) {
+	if _, err := Run(nil, testGame(1)); err == nil {
+		t.Error("expected error for nil config")
+	}
+
+	game := testGame(1)
+	game.Players = 0
+	if _, err := Run(testConfig(t), game); err == nil {
+		t.Error("expected error for zero players")
+	}
+
+	game = testGame(1)
+	game.Strategies = []string{"telepath"}
+	if _, err := Run(testConfig(t), game); err == nil {
+		t.Error("expected error for unknown strategy")
+	}
+}
+
+func TestSummarize(t *testing.T) {
+	results := []*Result{
+		{Ruleset: "a", Completed: true, Winner: "mafia", Rounds: 2, KillAttempts: 2, DoctorSaves: 1,
+			Eliminations: []Elimination{{Role: "villager"}, {Role: "doctor"}}},
+		{Ruleset: "a", Completed: true, Winner: "village", Rounds: 4, KillAttempts: 2,
+			Eliminations: []Elimination{{Role: "mafia"}}},
+		{Ruleset: "b", Completed: false, Winner: "none", Rounds: 6},
+	}
+
+	report := Summarize(results)
+	if report.Games != 3 || report.Completed != 2 {
+		t.Fatalf("unexpected counts: %+v", report)
+	}
+	if report.WinRate["mafia"] != 0.5 || report.WinRate["village"] != 0.5 {
+		t.Errorf("unexpected win rate: %v", report.WinRate)
+	}
+	if report.AvgRounds != 4 {
+		t.Errorf("expected 4 avg rounds, got %v", report.AvgRounds)
+	}
+	if report.DoctorSaveRate != 0.25 {
+		t.Errorf("expected save rate 0.25, got %v", report.DoctorSaveRate)
+	}
+	if len(report.EliminationOrder) != 2 || report.EliminationOrder[0]["mafia"] != 0.5 || report.EliminationOrder[1]["doctor"] != 1 {
+		t.Errorf("unexpected elimination order: %v", report.EliminationOrder)
+	}
+
+	summary := SummarizeByRuleset(results)
+	if len(summary.ByRuleset) != 2 || summary.ByRuleset["a"].Games != 2 {
+		t.Errorf("unexpected per-ruleset reports: %+v", summary.ByRuleset)
+	}
+}
@@ -0,0 +1,242 @@
+// Package stats aggregates archived games into the numbers used to judge
+// whether a prompt or model change made the agents better: win rates per
+// faction, role and model profile, lynch accuracy, doctor save rate, sheriff
+// hit rate and survival by seat and trait (see cmd/stats).
+//
+// Every rate comes with a 95% Wilson interval, so small samples show as wide
+// intervals rather than as confident numbers.
+package stats
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"strconv"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/sim"
+)
+
+// Rate is hits out of a total, with its 95% Wilson interval.
+type Rate struct {
+	Hits  int     `json:"hits"`
+	Total int     `json:"total"`
+	Rate  float64 `json:"rate"`
+	Low   float64 `json:"low"`
+	High  float64 `json:"high"`
+}
+
+// add counts one trial.
+func (r *Rate) add(hit bool) {
+	r.Total++
+	if hit {
+		r.Hits++
+	}
+}
+
+// finish computes the rate and its interval from the counts.
+func (r *Rate) finish() {
+	if r.Total > 0 {
+		r.Rate = float64(r.Hits) / float64(r.Total)
+	}
+	r.Low, r.High = sim.WilsonInterval(r.Hits, r.Total, sim.Z95)
+}
+
+func (r *Rate) String() string {
+	if r.Total == 0 {
+		return "n/a"
+	}
+	return fmt.Sprintf("%5.1f%% [%4.1f-%5.1f] (%d/%d)", 100*r.Rate, 100*r.Low, 100*r.High, r.Hits, r.Total)
+}
+
+// Report aggregates many games. Build it with New, Add and Finish.
+type Report struct {
+	Games int `json:"games"`
+
+	// faction -> games won out of all games
+	Factions map[string]*Rate `json:"factions"`
+	// role -> players of that role on the winning side, out of players dealt it
+	Roles map[string]*Rate `json:"roles"`
+	// model profile -> seats it played on the winning side, out of seats it played
+	Models map[string]*Rate `json:"models"`
+
+	// day eliminations that hit the mafia, out of day eliminations
+	LynchAccuracy Rate `json:"lynch_accuracy"`
+	// mafia kills the doctor prevented, out of mafia kill attempts
+	DoctorSaves Rate `json:"doctor_saves"`
+	// investigations that found a mafia member, out of investigations
+	SheriffHits Rate `json:"sheriff_hits"`
+
+	// players alive at the end, out of players, per seat and per trait
+	SurvivalBySeat  map[int]*Rate    `json:"survival_by_seat"`
+	SurvivalByTrait map[string]*Rate `json:"survival_by_trait"`
+}
+
+// New returns an empty report.
+func New() *Report {
+	return &Report{
+		Factions:        make(map[string]*Rate),
+		Roles:           make(map[string]*Rate),
+		Models:          make(map[string]*Rate),
+		SurvivalBySeat:  make(map[int]*Rate),
+		SurvivalByTrait: make(map[string]*Rate),
+	}
+}
+
+// Build reads the games matching the query from the archive and reports on them.
+func Build(store *archive.Store, q archive.Query) (*Report, error) {
+	games, err := store.Find(q)
+	if err != nil {
+		return nil, err
+	}
+	report := New()
+	for _, game := range games {
+		evs, err := store.Events(game.ID)
+		if err != nil {
+			return nil, fmt.Errorf("game %s: %w", game.ID, err)
+		}
+		if err := report.Add(game, evs); err != nil {
+			return nil, err
+		}
+	}
+	report.Finish()
+	return report, nil
+}
+
+// Add counts one archived game and its events.
+func (r *Report) Add(game *archive.Game, evs []json.RawMessage) error {
+	r.Games++
+	for _, faction := range []string{domain.WinnerVillage.String(), domain.WinnerMafia.String()} {
+		rate(r.Factions, faction).add(game.Winner == faction)
+	}
+
+	factions := make(map[string]string, len(game.Players)) // player ID -> faction
+	for _, player := range game.Players {
+		role, _ := domain.ParseRole(player.Role)
+		faction := role.Faction().String()
+		factions[player.ID] = faction
+		won := faction == game.Winner
+
+		rate(r.Roles, player.Role).add(won)
+		if player.Model != "" {
+			rate(r.Models, player.Model).add(won)
+		}
+		rate(r.SurvivalBySeat, player.Seat).add(player.Alive)
+		if player.Trait != "" {
+			rate(r.SurvivalByTrait, player.Trait).add(player.Alive)
+		}
+	}
+	isMafia := func(id string) bool { return factions[id] == domain.FactionMafia.String() }
+
+	for i, raw := range evs {
+		decoded, err := events.DeserializeEngineEvent(raw)
+		if err != nil {
+			return fmt.Errorf("game %s: event %d: %w", game.ID, i, err)
+		}
+		switch ev := decoded.(type) {
+		case *events.VoteResult:
+			if ev.Eliminated != "" {
+				r.LynchAccuracy.add(isMafia(ev.Eliminated))
+			}
+		case *events.GameEnded:
+			for _, night := range ev.Nights {
+				if night.MafiaTarget != "" {
+					r.DoctorSaves.add(night.MafiaTarget == night.DoctorTarget)
+				}
+				if night.SheriffTarget != "" {
+					r.SheriffHits.add(isMafia(night.SheriffTarget))
+				}
+			}
+		}
+	}
+	return nil
+}
+
+// Finish computes every rate from the counts. Call it once after the last Add.
+func (r *Report) Finish() {
+	for _, group := range []map[string]*Rate{r.Factions, r.Roles, r.Models, r.SurvivalByTrait} {
+		for _, stat := range group {
+			stat.finish()
+		}
+	}
+	for _, stat := range r.SurvivalBySeat {
+		stat.finish()
+	}
+	r.LynchAccuracy.finish()
+	r.DoctorSaves.finish()
+	r.SheriffHits.finish()
+}
+
+// Leaderboard returns the model profiles by win rate, best first.
+// Ties go to the lower bound of the interval (more evidence), then to the name.
+func (r *Report) Leaderboard() []string {
+	models := sortedKeys(r.Models)
+	sort.SliceStable(models, func(i, j int) bool {
+		a, b := r.Models[models[i]], r.Models[models[j]]
+		if a.Rate != b.Rate {
+			return a.Rate > b.Rate
+		}
+		return a.Low > b.Low
+	})
+	return models
+}
+
+// WriteText prints a human readable report.
+func (r *Report) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "== %d games\n", r.Games)
+
+	fmt.Fprintln(w, "win rate by faction:")
+	for _, faction := range sortedKeys(r.Factions) {
+		fmt.Fprintf(w, "  %-26s %s\n", faction, r.Factions[faction])
+	}
+	fmt.Fprintln(w, "win rate by role:")
+	for _, role := range sortedKeys(r.Roles) {
+		fmt.Fprintf(w, "  %-26s %s\n", role, r.Roles[role])
+	}
+	if len(r.Models) > 0 {
+		fmt.Fprintln(w, "leaderboard (win rate by model profile):")
+		for i, model := range r.Leaderboard() {
+			fmt.Fprintf(w, "  %-26s %s\n", fmt.Sprintf("%d. %s", i+1, model), r.Models[model])
+		}
+	}
+
+	fmt.Fprintf(w, "lynch accuracy:              %s\n", &r.LynchAccuracy)
+	fmt.Fprintf(w, "doctor save rate:            %s\n", &r.DoctorSaves)
+	fmt.Fprintf(w, "sheriff hit rate:            %s\n", &r.SheriffHits)
+
+	fmt.Fprintln(w, "survival by seat:")
+	seats := make([]int, 0, len(r.SurvivalBySeat))
+	for seat := range r.SurvivalBySeat {
+		seats = append(seats, seat)
+	}
+	sort.Ints(seats)
+	for _, seat := range seats {
+		fmt.Fprintf(w, "  %-26s %s\n", "seat "+strconv.Itoa(seat), r.SurvivalBySeat[seat])
+	}
+	if len(r.SurvivalByTrait) > 0 {
+		fmt.Fprintln(w, "survival by trait:")
+		for _, trait := range sortedKeys(r.SurvivalByTrait) {
+			fmt.Fprintf(w, "  %-26s %s\n", trait, r.SurvivalByTrait[trait])
+		}
+	}
+}
+
+// rate returns the group's rate for key, creating it on first use.
+func rate[K comparable](group map[K]*Rate, key K) *Rate {
+	if group[key] == nil {
+		group[key] = &Rate{}
+	}
+	return group[key]
+}
+
+func sortedKeys(group map[string]*Rate) []string {
+	keys := make([]string, 0, len(group))
+	for key := range group {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,125 @@
+package stats
+
+import (
+	"bytes"
+	"encoding/json"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/archive"
+)
+
+func players(models ...string) []archive.Player {
+	roles := []string{"mafia", "doctor", "sheriff", "villager"}
+	var list []archive.Player
+	for i, model := range models {
+		list = append(list, archive.Player{
+			ID: string(rune('a' + i)), Seat: i + 1, Role: roles[i], Model: model, Trait: "timid", Alive: true,
+		})
+	}
+	return list
+}
+
+func raw(lines ...string) []json.RawMessage {
+	evs := make([]json.RawMessage, len(lines))
+	for i, line := range lines {
+		evs[i] = json.RawMessage(line)
+	}
+	return evs
+}
+
+// Game 1: the village lynches the mafia (a); the doctor saved the only kill
+// attempt and the sheriff checked the mafia.
+// Game 2: the village lynches the sheriff (c); the mafia kills the doctor (b)
+// and the sheriff checked the villager first.
+func testGames() ([]*archive.Game, [][]json.RawMessage) {
+	game1 := &archive.Game{ID: "g1", Winner: "village", Players: players("m1", "m2", "m2", "m2")}
+	game1.Players[0].Alive = false
+	evs1 := raw(
+		`{"type":"vote_result","votes":[{"voter":"b","target":"a"}],"eliminated":"a"}`,
+		`{"type":"game_ended","winner":"village","nights":[{"round":1,"mafia_target":"d","doctor_target":"d","sheriff_target":"a"}]}`,
+	)
+
+	game2 := &archive.Game{ID: "g2", Winner: "mafia", Players: players("m1", "m2", "m2", "m2")}
+	game2.Players[1].Alive = false
+	game2.Players[2].Alive = false
+	evs2 := raw(
+		`{"type":"vote_result","votes":[],"eliminated":""}`,
+		`{"type":"vote_result","votes":[{"voter":"a","target":"c"}],"eliminated":"c"}`,
+		`{"type":"game_ended","winner":"mafia","nights":[{"round":1,"mafia_target":"b","doctor_target":"d","sheriff_target":"d","killed":"b"}]}`,
+	)
+	return []*archive.Game{game1, game2}, [][]json.RawMessage{evs1, evs2}
+}
+
+func TestReport(t *testing.T) {
+	games, evs := testGames()
+	report := New()
+	for i, game := range games {
+		if err := report.Add(game, evs[i]); err != nil {
+			t.Fatalf("Add failed: %v", err)
+		}
+	}
+	report.Finish()
+
+	tests := []struct {
+		name        string
+		rate        *Rate
+		hits, total int
+	}{
+		{"village wins", report.Factions["village"], 1, 2},
+		{"mafia role wins", report.Roles["mafia"], 1, 2},
+		{"m2 wins", report.Models["m2"], 3, 6},
+		{"lynch accuracy", &report.LynchAccuracy, 1, 2},
+		{"doctor saves", &report.DoctorSaves, 1, 2},
+		{"sheriff hits", &report.SheriffHits, 1, 2},
+		{"seat 2 survival", report.SurvivalBySeat[2], 1, 2},
+		{"timid survival", report.SurvivalByTrait["timid"], 5, 8},
+	}
+	for _, tt := range tests {
+		if tt.rate == nil || tt.rate.Hits != tt.hits || tt.rate.Total != tt.total {
+			t.Errorf("%s: got %+v, want %d/%d", tt.name, tt.rate, tt.hits, tt.total)
+			continue
+		}
+		if math.Abs(tt.rate.Rate-float64(tt.hits)/float64(tt.total)) > 1e-9 || tt.rate.Low > tt.rate.Rate || tt.rate.High < tt.rate.Rate {
+			t.Errorf("%s: bad rate or interval %+v", tt.name, tt.rate)
+		}
+	}
+
+	if got := report.Leaderboard(); strings.Join(got, ",") != "m2,m1" {
+		// m1 and m2 both win half, m2 has more games and so the tighter interval
+		t.Errorf("unexpected leaderboard %v", got)
+	}
+
+	var buf bytes.Buffer
+	report.WriteText(&buf)
+	for _, want := range []string{"== 2 games", "lynch accuracy:", " 1. m2", "seat 4"} {
+		if !strings.Contains(buf.String(), want) {
+			t.Errorf("missing %q in:\n%s", want, buf.String())
+		}
+	}
+}
+
+func TestBuild_FromArchive(t *testing.T) {
+	store, err := archive.Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	games, evs := testGames()
+	for i, game := range games {
+		if err := store.Put(game, evs[i]); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	report, err := Build(store, archive.Query{Winner: "mafia"})
+	if err != nil {
+		t.Fatalf("Build failed: %v", err)
+	}
+	if report.Games != 1 || report.LynchAccuracy.Total != 1 || report.LynchAccuracy.Hits != 0 {
+		t.Errorf("unexpected report %+v", report)
+	}
+}
@@ -0,0 +1,122 @@
+package telemetry
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// metric is one value of a Stats, exported as a Prometheus family.
+type metric struct {
+	name  string
+	help  string
+	kind  string // counter or summary
+	value func(*Stats) []sampleValue
+}
+
+type sampleValue struct {
+	suffix string // "", "_sum" or "_count"
+	labels string // extra labels, e.g. `kind="prompt"`
+	value  int64
+}
+
+var metrics = []metric{
+	{"actions_total", "Player actions accepted by the engine.", "counter",
+		func(s *Stats) []sampleValue { return []sampleValue{{value: s.Actions}} }},
+	{"reported_actions_total", "Accepted actions that came with usage telemetry.", "counter",
+		func(s *Stats) []sampleValue { return []sampleValue{{value: s.Reported}} }},
+	{"tokens_total", "Tokens reported by the agents.", "counter",
+		func(s *Stats) []sampleValue {
+			return []sampleValue{
+				{labels: `kind="prompt"`, value: s.PromptTokens},
+				{labels: `kind="completion"`, value: s.CompletionTokens},
+			}
+		}},
+	{"generation_latency_ms", "Generation latency reported by the agents.", "summary", summaryValues(func(s *Stats) Summary { return s.Latency })},
+	{"response_time_ms", "Time from the phase opening (or the prompt) to the first action, measured by the engine.", "summary", summaryValues(func(s *Stats) Summary { return s.Response })},
+}
+
+func summaryValues(get func(*Stats) Summary) func(*Stats) []sampleValue {
+	return func(s *Stats) []sampleValue {
+		summary := get(s)
+		return []sampleValue{{suffix: "_sum", value: summary.Sum}, {suffix: "_count", value: summary.Count}}
+	}
+}
+
+// WritePrometheus writes every game's player telemetry and the per-model
+// totals across games in the Prometheus text format: mafia_player_* series
+// labelled by game and player, mafia_model_* series labelled by model.
+func (r *Recorder) WritePrometheus(w io.Writer) error {
+	type series struct {
+		labels string
+		stats  *Stats
+	}
+	var players, models []series
+
+	r.mu.Lock()
+	byModel := make(map[string]*Stats)
+	for _, gameID := range sortedKeys(r.games) {
+		report := r.games[gameID]
+		for _, playerID := range sortedKeys(report.Players) {
+			stats := *report.Players[playerID]
+			players = append(players, series{fmt.Sprintf(`game="%s",player="%s"`, escape(gameID), escape(playerID)), &stats})
+		}
+		for model, s := range report.Models {
+			total := stats(byModel, model)
+			total.Actions += s.Actions
+			total.Reported += s.Reported
+			total.PromptTokens += s.PromptTokens
+			total.CompletionTokens += s.CompletionTokens
+			total.Latency.Count += s.Latency.Count
+			total.Latency.Sum += s.Latency.Sum
+			total.Response.Count += s.Response.Count
+			total.Response.Sum += s.Response.Sum
+		}
+	}
+	r.mu.Unlock()
+	for _, model := range sortedKeys(byModel) {
+		models = append(models, series{fmt.Sprintf(`model="%s"`, escape(model)), byModel[model]})
+	}
+
+	out := bufio.NewWriter(w)
+	for _, scope := range []struct {
+		prefix string
+		series []series
+	}{{"mafia_player_", players}, {"mafia_model_", models}} {
+		for _, m := range metrics {
+			name := scope.prefix + m.name
+			fmt.Fprintf(out, "# HELP %s %s\n# TYPE %s %s\n", name, m.help, name, m.kind)
+			for _, s := range scope.series {
+				for _, v := range m.value(s.stats) {
+					labels := s.labels
+					if v.labels != "" {
+						labels += "," + v.labels
+					}
+					fmt.Fprintf(out, "%s%s{%s} %d\n", name, v.suffix, labels, v.value)
+				}
+			}
+		}
+	}
+	return out.Flush()
+}
+
+// Handler serves the metrics.
+func (r *Recorder) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != http.MethodGet {
+			w.Header().Set("Allow", http.MethodGet)
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		_ = r.WritePrometheus(w)
+	})
+}
+
+var labelEscaper = strings.NewReplacer(`\`, `\\`, `"`, `\"`, "\n", `\n`)
+
+func escape(value string) string {
+	return labelEscaper.Replace(value)
+}
@@ -0,0 +1,180 @@
+// Package telemetry aggregates how fast and how expensive player agents are:
+// the usage they report with their actions (model, tokens, generation
+// latency, see events.Telemetry) and the response time the engine measures
+// itself, from the phase opening (or the player being prompted) to the
+// player's first accepted action in it.
+//
+// Usage is kept per game, per player and per model. The engine records into a
+// Recorder (see engine.WithTelemetry); the archive stores each game's Report
+// and the engine serves the totals as Prometheus metrics (ENGINE_METRICS_ADDR).
+package telemetry
+
+import (
+	"sort"
+	"sync"
+
+	"mafia-engine/internal/events"
+)
+
+// UnknownModel labels actions whose model neither the agent nor the player's
+// profile named.
+const UnknownModel = "unknown"
+
+// Sample is one accepted player action.
+type Sample struct {
+	GameID   string
+	PlayerID string
+	Model    string
+	Usage    *events.Telemetry // nil if the agent reported nothing
+
+	// Responded is set for the player's first action after the phase opened
+	// or they were prompted; ResponseMs is then how long that took
+	Responded  bool
+	ResponseMs int64
+}
+
+// Summary is a count, total and maximum of durations in milliseconds.
+type Summary struct {
+	Count int64 `json:"count"`
+	Sum   int64 `json:"sum"`
+	Max   int64 `json:"max"`
+}
+
+// Mean returns the mean duration (0 if empty).
+func (s Summary) Mean() float64 {
+	if s.Count == 0 {
+		return 0
+	}
+	return float64(s.Sum) / float64(s.Count)
+}
+
+func (s *Summary) add(ms int64) {
+	s.Count++
+	s.Sum += ms
+	s.Max = max(s.Max, ms)
+}
+
+// Stats aggregates the samples of one player, model or game.
+type Stats struct {
+	Actions          int64   `json:"actions"`
+	Reported         int64   `json:"reported"` // actions with usage from the agent
+	PromptTokens     int64   `json:"prompt_tokens"`
+	CompletionTokens int64   `json:"completion_tokens"`
+	Latency          Summary `json:"generation_latency_ms"` // reported by the agent
+	Response         Summary `json:"response_time_ms"`      // measured by the engine
+}
+
+func (s *Stats) add(sample Sample) {
+	s.Actions++
+	if usage := sample.Usage; usage != nil {
+		s.Reported++
+		s.PromptTokens += max(usage.PromptTokens, 0)
+		s.CompletionTokens += max(usage.CompletionTokens, 0)
+		if usage.LatencyMs > 0 {
+			s.Latency.add(usage.LatencyMs)
+		}
+	}
+	if sample.Responded {
+		s.Response.add(max(sample.ResponseMs, 0))
+	}
+}
+
+// Report is the telemetry of one game.
+type Report struct {
+	Game    Stats             `json:"game"`
+	Players map[string]*Stats `json:"players"` // by player ID
+	Models  map[string]*Stats `json:"models"`
+}
+
+func newReport() *Report {
+	return &Report{Players: make(map[string]*Stats), Models: make(map[string]*Stats)}
+}
+
+func (r *Report) clone() *Report {
+	clone := newReport()
+	clone.Game = r.Game
+	for id, stats := range r.Players {
+		copied := *stats
+		clone.Players[id] = &copied
+	}
+	for model, stats := range r.Models {
+		copied := *stats
+		clone.Models[model] = &copied
+	}
+	return clone
+}
+
+// Recorder collects samples. It is safe for concurrent use (the engine loop
+// records while the metrics endpoint reads); a nil *Recorder records nothing.
+type Recorder struct {
+	mu    sync.Mutex
+	games map[string]*Report
+}
+
+// NewRecorder returns an empty recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{games: make(map[string]*Report)}
+}
+
+// Record adds one accepted action.
+func (r *Recorder) Record(sample Sample) {
+	if r == nil {
+		return
+	}
+	if sample.Model == "" {
+		sample.Model = UnknownModel
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := r.games[sample.GameID]
+	if report == nil {
+		report = newReport()
+		r.games[sample.GameID] = report
+	}
+	report.Game.add(sample)
+	stats(report.Players, sample.PlayerID).add(sample)
+	stats(report.Models, sample.Model).add(sample)
+}
+
+// Report returns a copy of the game's telemetry, nil if nothing was recorded.
+func (r *Recorder) Report(gameID string) *Report {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	report := r.games[gameID]
+	if report == nil {
+		return nil
+	}
+	return report.clone()
+}
+
+// Games returns the IDs of the games with telemetry, sorted.
+func (r *Recorder) Games() []string {
+	if r == nil {
+		return nil
+	}
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return sortedKeys(r.games)
+}
+
+func stats(m map[string]*Stats, key string) *Stats {
+	s := m[key]
+	if s == nil {
+		s = &Stats{}
+		m[key] = s
+	}
+	return s
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,84 @@
+package telemetry
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/events"
+)
+
+func TestRecorder_Aggregates(t *testing.T) {
+	r := NewRecorder()
+	r.Record(Sample{GameID: "g1", PlayerID: "p1", Model: "gpt-4o",
+		Usage: &events.Telemetry{PromptTokens: 100, CompletionTokens: 20, LatencyMs: 800}, Responded: true, ResponseMs: 1200})
+	r.Record(Sample{GameID: "g1", PlayerID: "p1", Model: "gpt-4o",
+		Usage: &events.Telemetry{PromptTokens: 50, CompletionTokens: 10, LatencyMs: 400}})
+	r.Record(Sample{GameID: "g1", PlayerID: "p2", Responded: true, ResponseMs: 3000})
+	r.Record(Sample{GameID: "g2", PlayerID: "p1", Model: "gpt-4o"})
+
+	report := r.Report("g1")
+	if report == nil {
+		t.Fatal("no report for g1")
+	}
+	if game := report.Game; game.Actions != 3 || game.Reported != 2 || game.PromptTokens != 150 || game.Response.Count != 2 {
+		t.Errorf("unexpected game stats %+v", game)
+	}
+	p1 := report.Players["p1"]
+	if p1.CompletionTokens != 30 || p1.Latency.Mean() != 600 || p1.Latency.Max != 800 || p1.Response.Sum != 1200 {
+		t.Errorf("unexpected p1 stats %+v", p1)
+	}
+	if unknown := report.Models[UnknownModel]; unknown == nil || unknown.Actions != 1 || unknown.Response.Max != 3000 {
+		t.Errorf("actions without a model must count as %q: %+v", UnknownModel, report.Models)
+	}
+
+	// the report is a copy
+	report.Players["p1"].Actions = 99
+	if r.Report("g1").Players["p1"].Actions != 2 {
+		t.Error("changing a report changed the recorder")
+	}
+	if games := r.Games(); len(games) != 2 || games[0] != "g1" {
+		t.Errorf("unexpected games %v", games)
+	}
+	if r.Report("none") != nil {
+		t.Error("expected no report for an unknown game")
+	}
+
+	var none *Recorder
+	none.Record(Sample{GameID: "g1"})
+	if none.Report("g1") != nil || none.Games() != nil {
+		t.Error("a nil recorder must record nothing")
+	}
+}
+
+func TestRecorder_Handler(t *testing.T) {
+	r := NewRecorder()
+	r.Record(Sample{GameID: "g1", PlayerID: "p1", Model: `bot:"x"`,
+		Usage: &events.Telemetry{PromptTokens: 100, LatencyMs: 800}, Responded: true, ResponseMs: 1200})
+	r.Record(Sample{GameID: "g2", PlayerID: "p1", Model: `bot:"x"`, Usage: &events.Telemetry{PromptTokens: 5}})
+
+	rec := httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	if rec.Code != http.StatusOK || !strings.HasPrefix(rec.Header().Get("Content-Type"), "text/plain") {
+		t.Fatalf("unexpected response %d %q", rec.Code, rec.Header().Get("Content-Type"))
+	}
+	body := rec.Body.String()
+	for _, want := range []string{
+		"# TYPE mafia_player_actions_total counter",
+		`mafia_player_tokens_total{game="g1",player="p1",kind="prompt"} 100`,
+		`mafia_player_response_time_ms_sum{game="g1",player="p1"} 1200`,
+		`mafia_model_tokens_total{model="bot:\"x\"",kind="prompt"} 105`,
+		`mafia_model_generation_latency_ms_count{model="bot:\"x\""} 1`,
+	} {
+		if !strings.Contains(body, want) {
+			t.Errorf("metrics missing %q:\n%s", want, body)
+		}
+	}
+
+	rec = httptest.NewRecorder()
+	r.Handler().ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/metrics", nil))
+	if rec.Code != http.StatusMethodNotAllowed {
+		t.Errorf("POST: got %d", rec.Code)
+	}
+}
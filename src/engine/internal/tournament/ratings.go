@@ -0,0 +1,172 @@
+package tournament
+
+import (
+	"fmt"
+	"io"
+	"math"
+	"sort"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/domain"
+)
+
+// Rating defaults
+const (
+	DefaultRating = 1500.0
+	DefaultK      = 32.0
+)
+
+// Track is a profile's record in one faction.
+type Track struct {
+	Rating float64 `json:"rating"`
+	Games  int     `json:"games"`
+	Wins   int     `json:"wins"`
+}
+
+// Standing is a profile's record in both factions.
+type Standing struct {
+	Profile string `json:"profile"`
+	Mafia   Track  `json:"mafia"`
+	Village Track  `json:"village"`
+}
+
+// Rating is the mean of the mafia and village ratings.
+func (s *Standing) Rating() float64 {
+	return (s.Mafia.Rating + s.Village.Rating) / 2
+}
+
+// Ratings are Elo ratings per profile, kept apart for mafia and village play:
+// a good liar isn't necessarily a good detective, and the factions don't win
+// equally often, so one number would mostly measure the deal.
+//
+// Each game is one match between the mafia and the village. A side's rating
+// is the mean of its seats' ratings in that faction; every seat then moves by
+// K times the difference between the result and the expected score. A profile
+// playing several seats gets an update for each of them.
+type Ratings struct {
+	K        float64              `json:"k"`
+	Games    int                  `json:"games"`
+	Profiles map[string]*Standing `json:"profiles"`
+}
+
+// NewRatings returns empty ratings; k <= 0 uses DefaultK.
+func NewRatings(k float64) *Ratings {
+	if k <= 0 {
+		k = DefaultK
+	}
+	return &Ratings{K: k, Profiles: make(map[string]*Standing)}
+}
+
+// Update rates one archived game. Games without a winner are skipped, and so
+// are seats without a model profile (they still count toward their side).
+// It returns false if the game was skipped.
+func (r *Ratings) Update(game *archive.Game) bool {
+	mafiaWon := game.Winner == domain.WinnerMafia.String()
+	if !mafiaWon && game.Winner != domain.WinnerVillage.String() {
+		return false
+	}
+
+	type seat struct {
+		track *Track // nil for seats without a profile
+		mafia bool
+	}
+	var seats []seat
+	var mafiaSum, villageSum float64
+	var mafiaCount, villageCount int
+	for _, player := range game.Players {
+		role, _ := domain.ParseRole(player.Role)
+		isMafia := role.Faction() == domain.FactionMafia
+
+		var track *Track
+		rating := DefaultRating
+		if player.Model != "" {
+			track = r.track(player.Model, isMafia)
+			rating = track.Rating
+		}
+		seats = append(seats, seat{track: track, mafia: isMafia})
+		if isMafia {
+			mafiaSum += rating
+			mafiaCount++
+		} else {
+			villageSum += rating
+			villageCount++
+		}
+	}
+	if mafiaCount == 0 || villageCount == 0 {
+		return false
+	}
+
+	// expected score of the mafia, from the ratings before the game
+	expected := 1 / (1 + math.Pow(10, (villageSum/float64(villageCount)-mafiaSum/float64(mafiaCount))/400))
+	delta := r.K * (score(mafiaWon) - expected)
+
+	for _, s := range seats {
+		if s.track == nil {
+			continue
+		}
+		won := s.mafia == mafiaWon
+		s.track.Games++
+		if won {
+			s.track.Wins++
+		}
+		if s.mafia {
+			s.track.Rating += delta
+		} else {
+			s.track.Rating -= delta
+		}
+	}
+	r.Games++
+	return true
+}
+
+// Standings returns the profiles by mean rating, best first.
+func (r *Ratings) Standings() []*Standing {
+	standings := make([]*Standing, 0, len(r.Profiles))
+	for _, standing := range r.Profiles {
+		standings = append(standings, standing)
+	}
+	sort.Slice(standings, func(i, j int) bool {
+		if standings[i].Rating() != standings[j].Rating() {
+			return standings[i].Rating() > standings[j].Rating()
+		}
+		return standings[i].Profile < standings[j].Profile
+	})
+	return standings
+}
+
+// WriteText prints the standings table.
+func (r *Ratings) WriteText(w io.Writer) {
+	fmt.Fprintf(w, "standings after %d games:\n", r.Games)
+	fmt.Fprintf(w, "  %-4s %-26s %7s  %7s %9s  %7s %9s\n", "#", "profile", "rating", "mafia", "won", "village", "won")
+	for i, s := range r.Standings() {
+		fmt.Fprintf(w, "  %-4d %-26s %7.0f  %7.0f %9s  %7.0f %9s\n", i+1, s.Profile, s.Rating(),
+			s.Mafia.Rating, record(s.Mafia), s.Village.Rating, record(s.Village))
+	}
+}
+
+func (r *Ratings) track(profile string, mafia bool) *Track {
+	standing := r.Profiles[profile]
+	if standing == nil {
+		standing = &Standing{
+			Profile: profile,
+			Mafia:   Track{Rating: DefaultRating},
+			Village: Track{Rating: DefaultRating},
+		}
+		r.Profiles[profile] = standing
+	}
+	if mafia {
+		return &standing.Mafia
+	}
+	return &standing.Village
+}
+
+func score(won bool) float64 {
+	if won {
+		return 1
+	}
+	return 0
+}
+
+func record(t Track) string {
+	return fmt.Sprintf("%d/%d", t.Wins, t.Games)
+}
@@ -0,0 +1,102 @@
+// Package tournament ranks agents by model profile: it schedules many games,
+// rotating which profile plays which seat and faction, and keeps Elo ratings
+// per profile, separately for mafia and village play (see cmd/tournament).
+//
+// Bot tournaments are played headlessly with the built-in bots (profile
+// "bot:<strategy>") through package sim. Pairings of other profiles (e.g.
+// LLM agents) are played on live engines configured with Pairing.Env; their
+// archived games are rated with the same Ratings (see RateArchive).
+package tournament
+
+import (
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+
+	"mafia-engine/internal/domain"
+)
+
+// Pairing is one scheduled game: who plays each seat and the role pinned to it.
+type Pairing struct {
+	Game  int                 `json:"game"` // 0-based
+	Seed  int64               `json:"seed"`
+	Seats []string            `json:"seats"` // profile per seat, seat 1 first
+	Roles map[int]domain.Role `json:"-"`     // seat -> role
+}
+
+// Env is the engine configuration that plays the pairing live (see
+// cmd/engine): the seed, one reserved seat per profile and the pinned roles,
+// if any (the seed deals the others).
+// Agents joining a seat must declare its profile, so the archived game is
+// rated for the scheduled profiles.
+func (p Pairing) Env() []string {
+	seats := make([]int, 0, len(p.Roles))
+	for seat := range p.Roles {
+		seats = append(seats, seat)
+	}
+	sort.Ints(seats)
+	pins := make([]string, len(seats))
+	for i, seat := range seats {
+		pins[i] = strconv.Itoa(seat) + "=" + p.Roles[seat].String()
+	}
+
+	env := []string{
+		"ENGINE_GAME_SEED=" + strconv.FormatInt(p.Seed, 10),
+		"ENGINE_GAME_MIN_PLAYERS=" + strconv.Itoa(len(p.Seats)),
+		"ENGINE_SEAT_PROFILES=" + strings.Join(p.Seats, ","),
+	}
+	if len(pins) > 0 {
+		env = append(env, "ENGINE_PINNED_ROLES="+strings.Join(pins, ","))
+	}
+	return env
+}
+
+// Schedule plans games for the profiles.
+//
+// Roles are pinned so every seat plays every role once per block of as many
+// games as there are seats; between blocks the profiles move one seat along.
+// Over the tournament each profile plays each seat and each role about as
+// often as the others, so no profile is rated on a lucky deal.
+func Schedule(profiles []string, players int, ruleset *domain.Ruleset, games int, seed int64) ([]Pairing, error) {
+	if len(profiles) == 0 {
+		return nil, errors.New("at least one profile is required")
+	}
+	if players <= 0 || games <= 0 {
+		return nil, errors.New("players and games must be > 0")
+	}
+
+	roles := dealOrder(ruleset.RoleDistribution(players))
+	if len(roles) != players {
+		return nil, fmt.Errorf("role distribution deals %d roles for %d players", len(roles), players)
+	}
+
+	pairings := make([]Pairing, games)
+	for g := range pairings {
+		pairing := Pairing{
+			Game:  g,
+			Seed:  seed + int64(g),
+			Seats: make([]string, players),
+			Roles: make(map[int]domain.Role, players),
+		}
+		block := g / players
+		for s := 0; s < players; s++ {
+			pairing.Seats[s] = profiles[(s+block)%len(profiles)]
+			pairing.Roles[s+1] = roles[(s+g)%players]
+		}
+		pairings[g] = pairing
+	}
+	return pairings, nil
+}
+
+// dealOrder lists the roles of a distribution, mafia first.
+func dealOrder(distribution map[domain.Role]int) []domain.Role {
+	var roles []domain.Role
+	for _, role := range []domain.Role{domain.RoleMafia, domain.RoleDoctor, domain.RoleSheriff, domain.RoleVillager} {
+		for range distribution[role] {
+			roles = append(roles, role)
+		}
+	}
+	return roles
+}
@@ -0,0 +1,102 @@
+package tournament
+
+import (
+	"errors"
+	"fmt"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/sim"
+)
+
+// Tournament configures a bot tournament.
+type Tournament struct {
+	Strategies  []string // the competing bot strategies, see bots.StrategyNames
+	Players     int
+	Games       int
+	Seed        int64   // seed of the first game, game i uses Seed+i
+	RulesetFile string  // optional
+	MaxRounds   int     // 0 = sim.DefaultMaxRounds
+	K           float64 // Elo K factor, 0 = DefaultK
+
+	// Store, if set, archives every game (so cmd/stats can analyse them)
+	Store *archive.Store
+
+	// AfterGame, if set, is called after every game with the updated ratings
+	AfterGame func(pairing Pairing, game *archive.Game, ratings *Ratings)
+}
+
+// Run plays the tournament and returns the final ratings.
+func Run(cfg *config.Config, t Tournament) (*Ratings, error) {
+	if cfg == nil {
+		return nil, errors.New("config must not be nil")
+	}
+
+	var ruleset *domain.Ruleset
+	if t.RulesetFile != "" {
+		loaded, err := engine.LoadRuleset(t.RulesetFile)
+		if err != nil {
+			return nil, err
+		}
+		ruleset = loaded
+	}
+
+	profiles := make([]string, len(t.Strategies))
+	for i, strategy := range t.Strategies {
+		profiles[i] = bots.Profile(strategy)
+	}
+	pairings, err := Schedule(profiles, t.Players, ruleset, t.Games, t.Seed)
+	if err != nil {
+		return nil, err
+	}
+
+	ratings := NewRatings(t.K)
+	for _, pairing := range pairings {
+		strategies := make([]string, len(pairing.Seats))
+		for i, profile := range pairing.Seats {
+			strategies[i], _ = bots.StrategyOf(profile)
+		}
+		result, err := sim.Run(cfg, sim.Game{
+			Seed:        pairing.Seed,
+			Players:     t.Players,
+			Ruleset:     ruleset,
+			Strategies:  strategies,
+			MaxRounds:   t.MaxRounds,
+			PinnedRoles: pairing.Roles,
+		})
+		if err != nil {
+			return nil, fmt.Errorf("game %d (seed %d): %w", pairing.Game, pairing.Seed, err)
+		}
+
+		game := archive.NewGame(result.State, ruleset, result.Events)
+		ratings.Update(game)
+		if t.Store != nil && result.Completed {
+			if err := t.Store.Put(game, result.Events); err != nil {
+				return nil, fmt.Errorf("failed to archive game %d: %w", pairing.Game, err)
+			}
+			if err := t.Store.PutJournal(game.ID, result.Journal); err != nil {
+				return nil, fmt.Errorf("failed to archive game %d: %w", pairing.Game, err)
+			}
+		}
+		if t.AfterGame != nil {
+			t.AfterGame(pairing, game, ratings)
+		}
+	}
+	return ratings, nil
+}
+
+// RateArchive rates the archived games matching the query, oldest first.
+func RateArchive(store *archive.Store, q archive.Query, k float64) (*Ratings, error) {
+	games, err := store.Find(q)
+	if err != nil {
+		return nil, err
+	}
+	ratings := NewRatings(k)
+	for i := len(games) - 1; i >= 0; i-- {
+		ratings.Update(games[i])
+	}
+	return ratings, nil
+}
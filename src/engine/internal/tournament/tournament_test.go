@@ -0,0 +1,165 @@
+package tournament
+
+import (
+	"bytes"
+	"math"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/bots"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/domain"
+)
+
+func TestSchedule_RotatesSeatsAndRoles(t *testing.T) {
+	profiles := []string{"a", "b", "c"}
+	pairings, err := Schedule(profiles, 6, nil, 36, 100)
+	if err != nil {
+		t.Fatalf("Schedule failed: %v", err)
+	}
+
+	mafiaSeats := make(map[string]int) // profile -> seats played as mafia
+	seatsPlayed := make(map[string]map[int]bool)
+	for _, pairing := range pairings {
+		if pairing.Seed != 100+int64(pairing.Game) {
+			t.Errorf("game %d: unexpected seed %d", pairing.Game, pairing.Seed)
+		}
+		if err := domain.ValidatePinnedRoles(pairing.Roles, domain.GetRoleDistribution(6)); err != nil {
+			t.Fatalf("game %d: invalid pins: %v", pairing.Game, err)
+		}
+		for s, profile := range pairing.Seats {
+			if pairing.Roles[s+1] == domain.RoleMafia {
+				mafiaSeats[profile]++
+			}
+			if seatsPlayed[profile] == nil {
+				seatsPlayed[profile] = make(map[int]bool)
+			}
+			seatsPlayed[profile][s+1] = true
+		}
+	}
+
+	for _, profile := range profiles {
+		if mafiaSeats[profile] != mafiaSeats["a"] {
+			t.Errorf("profiles should play mafia equally often: %v", mafiaSeats)
+		}
+		if len(seatsPlayed[profile]) != 6 {
+			t.Errorf("%s should play every seat, played %v", profile, seatsPlayed[profile])
+		}
+	}
+
+	if _, err := Schedule(nil, 6, nil, 1, 0); err == nil {
+		t.Error("expected error without profiles")
+	}
+}
+
+func TestPairing_Env(t *testing.T) {
+	pairing := Pairing{
+		Seed:  7,
+		Seats: []string{"llama3.2:1b", "bot:random", "llama3.2:3b"},
+		Roles: map[int]domain.Role{3: domain.RoleVillager, 1: domain.RoleMafia, 2: domain.RoleDoctor},
+	}
+	want := []string{
+		"ENGINE_GAME_SEED=7",
+		"ENGINE_GAME_MIN_PLAYERS=3",
+		"ENGINE_SEAT_PROFILES=llama3.2:1b,bot:random,llama3.2:3b",
+		"ENGINE_PINNED_ROLES=1=mafia,2=doctor,3=villager",
+	}
+	if got := pairing.Env(); strings.Join(got, " ") != strings.Join(want, " ") {
+		t.Errorf("got %q, want %q", got, want)
+	}
+}
+
+func game(winner string, seats ...string) *archive.Game {
+	g := &archive.Game{Winner: winner}
+	for i, seat := range seats {
+		role, model, _ := strings.Cut(seat, "=")
+		g.Players = append(g.Players, archive.Player{Seat: i + 1, Role: role, Model: model})
+	}
+	return g
+}
+
+func TestRatings_Update(t *testing.T) {
+	ratings := NewRatings(32)
+
+	if !ratings.Update(game("mafia", "mafia=a", "villager=b", "doctor=b", "sheriff=")) {
+		t.Fatal("game should be rated")
+	}
+	a, b := ratings.Profiles["a"], ratings.Profiles["b"]
+	// equal ratings: the mafia was expected to score 0.5 and won
+	if a.Mafia.Rating != DefaultRating+16 || a.Mafia.Wins != 1 || a.Village.Games != 0 {
+		t.Errorf("unexpected mafia track %+v", a.Mafia)
+	}
+	if b.Village.Rating != DefaultRating-2*16 || b.Village.Games != 2 || b.Village.Wins != 0 {
+		t.Errorf("every seat moves, got %+v", b.Village)
+	}
+	if b.Mafia.Rating != DefaultRating {
+		t.Errorf("the mafia track of a village player must not move, got %v", b.Mafia.Rating)
+	}
+
+	// the favourite winning moves less than the underdog winning
+	before := a.Mafia.Rating
+	ratings.Update(game("mafia", "mafia=a", "villager=b"))
+	gain := a.Mafia.Rating - before
+	if gain <= 0 || gain >= 16 {
+		t.Errorf("expected a small gain for the favourite, got %v", gain)
+	}
+
+	if ratings.Update(game("none", "mafia=a", "villager=b")) {
+		t.Error("unfinished games must be skipped")
+	}
+	if ratings.Games != 2 {
+		t.Errorf("expected 2 rated games, got %d", ratings.Games)
+	}
+
+	standings := ratings.Standings()
+	if standings[0].Profile != "a" || math.Abs(standings[0].Rating()-(a.Mafia.Rating+a.Village.Rating)/2) > 1e-9 {
+		t.Errorf("unexpected standings %+v", standings[0])
+	}
+	var buf bytes.Buffer
+	ratings.WriteText(&buf)
+	if !strings.Contains(buf.String(), "standings after 2 games") {
+		t.Errorf("unexpected text:\n%s", buf.String())
+	}
+}
+
+func TestRun_BotTournament(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := archive.Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	var after int
+	ratings, err := Run(cfg, Tournament{
+		Strategies: []string{"random", "sheriff-follower"},
+		Players:    6,
+		Games:      12,
+		Seed:       1,
+		Store:      store,
+		AfterGame:  func(Pairing, *archive.Game, *Ratings) { after++ },
+	})
+	if err != nil {
+		t.Fatalf("Run failed: %v", err)
+	}
+	if after != 12 {
+		t.Errorf("AfterGame called %d times, want 12", after)
+	}
+	if len(ratings.Profiles) != 2 || ratings.Profiles[bots.Profile("random")] == nil {
+		t.Fatalf("unexpected profiles %v", ratings.Profiles)
+	}
+
+	// the archive rates the same as the live tournament
+	rated, err := RateArchive(store, archive.Query{}, 0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rated.Games != ratings.Games {
+		t.Errorf("archive rated %d games, tournament %d", rated.Games, ratings.Games)
+	}
+}
@@ -0,0 +1,279 @@
+// Package training turns archived games into per-player episodes for
+// training and evaluating player models (see cmd/export).
+//
+// An episode is one player's game: the events the engine delivered to that
+// player, in order, interleaved with what the player did and thought, and the
+// outcome for the player's faction. Observations are filtered with the same
+// events.Viewer rules the engine uses for live delivery and resyncs, so an
+// episode holds nothing the player couldn't have seen.
+//
+// Actions come from the game's journal: the commands the engine accepted, each
+// stamped with the last seq published before it. An action goes in after that
+// event, so it is placed where the player took it, in the order the engine
+// took it. Games archived without a journal are skipped.
+package training
+
+import (
+	"bufio"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+)
+
+// Step kinds
+const (
+	StepObservation = "observation" // an event delivered to the player
+	StepAction      = "action"      // something the player did
+	StepThought     = "thought"     // the player's private reasoning
+)
+
+// Action types, the same as in events.ThoughtLinked
+const (
+	ActionChat        = events.ActionChat
+	ActionMafiaChat   = events.ActionMafiaChat
+	ActionVote        = events.ActionVote
+	ActionNightAction = events.ActionNightAction
+)
+
+// Episode is one player's game.
+type Episode struct {
+	GameID   string `json:"game_id"`
+	Seed     int64  `json:"seed"`
+	Ruleset  string `json:"ruleset"`
+	PlayerID string `json:"player_id"`
+	Name     string `json:"name"`
+	Seat     int    `json:"seat"`
+	Role     string `json:"role"`
+	Faction  string `json:"faction"`
+	Trait    string `json:"trait,omitempty"`
+	Model    string `json:"model,omitempty"`
+
+	Steps   []Step  `json:"steps"`
+	Outcome Outcome `json:"outcome"`
+}
+
+// Step is one observation, action or thought. Round and phase are those the
+// step happened in.
+type Step struct {
+	Kind  string `json:"kind"`
+	Seq   int64  `json:"seq,omitempty"` // of the event; for actions the last seq published before it
+	At    int64  `json:"at,omitempty"`  // actions: when the engine took it, Unix ms
+	Round int    `json:"round,omitempty"`
+	Phase string `json:"phase,omitempty"`
+
+	Event   json.RawMessage `json:"event,omitempty"` // observations: the event as published
+	Action  *Action         `json:"action,omitempty"`
+	Thought string          `json:"thought,omitempty"`
+}
+
+// Action is something the player did.
+type Action struct {
+	Type    string `json:"type"`
+	Role    string `json:"role,omitempty"`   // night actions
+	Target  string `json:"target,omitempty"` // votes and night actions; empty vote = abstain
+	Message string `json:"message,omitempty"`
+}
+
+// Outcome is how the game ended for the player.
+type Outcome struct {
+	Winner   string `json:"winner,omitempty"` // empty if the game had no winner
+	Won      bool   `json:"won"`
+	Survived bool   `json:"survived"`
+	// Reward is 1 if the player's faction won, -1 if it lost and 0 without a winner
+	Reward float64 `json:"reward"`
+}
+
+// Episodes builds one episode per player of an archived game, in seat order,
+// from its events and its journal entries (see engine.Journal.Entries).
+func Episodes(game *archive.Game, evs []json.RawMessage, journal []engine.JournalEntry) ([]*Episode, error) {
+	decoded := make([]events.Event, len(evs))
+	for i, raw := range evs {
+		value, err := events.DeserializeEngineEvent(raw)
+		if err != nil {
+			return nil, fmt.Errorf("game %s event %d: %w", game.ID, i, err)
+		}
+		ev, ok := value.(events.Event)
+		if !ok {
+			return nil, fmt.Errorf("game %s event %d: unknown event type: %T", game.ID, i, value)
+		}
+		decoded[i] = ev
+	}
+
+	episodes := make([]*Episode, 0, len(game.Players))
+	for _, player := range game.Players {
+		episodes = append(episodes, episode(game, player, evs, decoded, journal))
+	}
+	return episodes, nil
+}
+
+func episode(game *archive.Game, player archive.Player, raw []json.RawMessage, evs []events.Event, journal []engine.JournalEntry) *Episode {
+	role, _ := domain.ParseRole(player.Role)
+	faction := role.Faction().String()
+	ep := &Episode{
+		GameID:   game.ID,
+		Seed:     game.Seed,
+		Ruleset:  game.Ruleset,
+		PlayerID: player.ID,
+		Name:     player.Name,
+		Seat:     player.Seat,
+		Role:     player.Role,
+		Faction:  faction,
+		Trait:    player.Trait,
+		Model:    player.Model,
+		Steps:    []Step{},
+		Outcome:  outcome(game.Winner, faction, player.Alive),
+	}
+	viewer := events.Viewer{PlayerID: player.ID, Faction: faction}
+
+	var actions []engine.JournalEntry
+	for _, entry := range journal {
+		if entry.PlayerID == player.ID && action(entry) != nil {
+			actions = append(actions, entry)
+		}
+	}
+
+	// the player's actions taken before the event with seq go in first
+	var round int
+	var phase string
+	before := func(seq int64) {
+		for len(actions) > 0 && (seq == 0 || actions[0].Seq < seq) {
+			entry := actions[0]
+			actions = actions[1:]
+			ep.Steps = append(ep.Steps, Step{
+				Kind: StepAction, Seq: entry.Seq, At: entry.Timestamp, Round: round, Phase: phase, Action: action(entry),
+			})
+		}
+	}
+
+	for i, ev := range evs {
+		header := ev.Header()
+		if header.Seq > 0 { // observer events have none
+			before(header.Seq)
+		}
+		round, phase = header.Round, header.Phase
+
+		switch e := ev.(type) {
+		case *events.AllChatMessage:
+			if e.SenderID == player.ID {
+				continue // the chat action
+			}
+		case *events.MafiaChatMessage:
+			if e.SenderID == player.ID {
+				continue
+			}
+		case *events.PlayerThoughts:
+			if e.SenderID == player.ID {
+				ep.Steps = append(ep.Steps, Step{
+					Kind: StepThought, Seq: header.Seq, Round: header.Round, Phase: header.Phase, Thought: e.Thought,
+				})
+			}
+			continue
+		}
+
+		if viewer.CanSee(ev) {
+			ep.Steps = append(ep.Steps, Step{
+				Kind: StepObservation, Seq: header.Seq, Round: header.Round, Phase: header.Phase, Event: raw[i],
+			})
+		}
+	}
+	before(0)
+	return ep
+}
+
+// action returns the player action a journal entry records, nil for other commands.
+func action(entry engine.JournalEntry) *Action {
+	switch entry.Kind {
+	case engine.EntryChat:
+		return &Action{Type: ActionChat, Message: entry.Text}
+	case engine.EntryMafiaChat:
+		return &Action{Type: ActionMafiaChat, Message: entry.Text}
+	case engine.EntryVote:
+		return &Action{Type: ActionVote, Target: entry.TargetID}
+	case engine.EntryNightAction:
+		return &Action{Type: ActionNightAction, Role: entry.Role, Target: entry.TargetID}
+	}
+	return nil
+}
+
+func outcome(winner, faction string, alive bool) Outcome {
+	o := Outcome{Winner: winner, Survived: alive}
+	switch winner {
+	case "":
+	case faction:
+		o.Won, o.Reward = true, 1
+	default:
+		o.Reward = -1
+	}
+	return o
+}
+
+// Filter picks the episodes to export.
+type Filter struct {
+	Model   string // only seats played by this model profile
+	Faction string // only seats of this faction
+	WonOnly bool   // only players whose faction won
+}
+
+// Matches reports whether the episode passes the filter.
+func (f Filter) Matches(ep *Episode) bool {
+	switch {
+	case f.Model != "" && ep.Model != f.Model:
+		return false
+	case f.Faction != "" && ep.Faction != f.Faction:
+		return false
+	case f.WonOnly && !ep.Outcome.Won:
+		return false
+	}
+	return true
+}
+
+// Export writes the episodes of the archived games matching the query as
+// JSONL, one episode per line, and returns how many it wrote. Games archived
+// without a journal are skipped.
+func Export(w io.Writer, store *archive.Store, q archive.Query, filter Filter) (int, error) {
+	games, err := store.Find(q)
+	if err != nil {
+		return 0, err
+	}
+
+	out := bufio.NewWriter(w)
+	encoder := json.NewEncoder(out)
+	written := 0
+	for _, game := range games {
+		journal, err := store.Journal(game.ID)
+		if errors.Is(err, archive.ErrNotFound) {
+			continue
+		}
+		if err != nil {
+			return written, fmt.Errorf("game %s: %w", game.ID, err)
+		}
+		entries, err := journal.Entries()
+		if err != nil {
+			return written, fmt.Errorf("game %s: %w", game.ID, err)
+		}
+		evs, err := store.Events(game.ID)
+		if err != nil {
+			return written, fmt.Errorf("game %s: %w", game.ID, err)
+		}
+		episodes, err := Episodes(game, evs, entries)
+		if err != nil {
+			return written, err
+		}
+		for _, ep := range episodes {
+			if !filter.Matches(ep) {
+				continue
+			}
+			if err := encoder.Encode(ep); err != nil {
+				return written, err
+			}
+			written++
+		}
+	}
+	return written, out.Flush()
+}
@@ -0,0 +1,228 @@
+package training
+
+import (
+	"bytes"
+	"encoding/json"
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/archive"
+	"mafia-engine/internal/config"
+	"mafia-engine/internal/engine"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/sim"
+)
+
+func raw(lines ...string) []json.RawMessage {
+	evs := make([]json.RawMessage, len(lines))
+	for i, line := range lines {
+		evs[i] = json.RawMessage(line)
+	}
+	return evs
+}
+
+// a (mafia) kills d on night 1 while e, also mafia, stays quiet; the village
+// lynches a on day 1. Ballots and thoughts are observer events without a seq.
+func testGame() (*archive.Game, []json.RawMessage, []engine.JournalEntry) {
+	game := &archive.Game{ID: "g1", Winner: "village", Players: []archive.Player{
+		{ID: "a", Name: "Alice", Seat: 1, Role: "mafia", Model: "m1"},
+		{ID: "b", Name: "Bob", Seat: 2, Role: "doctor", Alive: true, Model: "m2"},
+		{ID: "c", Name: "Cleo", Seat: 3, Role: "sheriff", Alive: true, Model: "m2"},
+		{ID: "d", Name: "Dan", Seat: 4, Role: "villager", Model: "m2"},
+		{ID: "e", Name: "Eve", Seat: 5, Role: "mafia", Alive: true, Model: "m1"},
+	}}
+	evs := raw(
+		`{"type":"role_assigned","seq":1,"player_id":"a","role":"mafia"}`,
+		`{"type":"role_assigned","seq":2,"player_id":"b","role":"doctor"}`,
+		`{"type":"role_assigned","seq":3,"player_id":"c","role":"sheriff"}`,
+		`{"type":"role_assigned","seq":4,"player_id":"d","role":"villager"}`,
+		`{"type":"role_assigned","seq":5,"player_id":"e","role":"mafia"}`,
+		`{"type":"phase_changed","seq":6,"round":1,"phase":"night","old_phase":"waiting","new_phase":"night"}`,
+		`{"type":"mafia_chat","seq":7,"round":1,"phase":"night","sender":"a","message":"dan"}`,
+		`{"type":"player_thoughts","round":1,"phase":"night","sender":"c","thought":"check alice"}`,
+		`{"type":"phase_changed","seq":8,"round":1,"phase":"day","old_phase":"night","new_phase":"day"}`,
+		`{"type":"player_eliminated","seq":9,"round":1,"phase":"day","player_id":"d","reason":"killed_by_mafia"}`,
+		`{"type":"investigation_result","seq":10,"round":1,"phase":"day","player_id":"c","target":"a","faction":"mafia"}`,
+		`{"type":"all_chat","seq":11,"round":1,"phase":"day","sender":"c","message":"alice is mafia"}`,
+		`{"type":"phase_changed","seq":12,"round":1,"phase":"voting","old_phase":"day","new_phase":"voting"}`,
+		`{"type":"vote_result","round":1,"phase":"voting","votes":[{"voter":"a","target":"c"},{"voter":"b","target":"a"},{"voter":"c","target":"a"}],"eliminated":"a"}`,
+		`{"type":"phase_changed","seq":13,"round":2,"phase":"night","old_phase":"voting","new_phase":"night"}`,
+		`{"type":"player_eliminated","seq":14,"round":2,"phase":"night","player_id":"a","reason":"voted_out"}`,
+		`{"type":"game_ended","seq":15,"round":2,"phase":"ended","winner":"village"}`,
+	)
+	journal := []engine.JournalEntry{
+		{Kind: engine.EntryPhaseChange, Seq: 5, Phase: "night"},
+		{Kind: engine.EntryMafiaChat, Seq: 6, Timestamp: 1000, PlayerID: "a", Text: "dan"},
+		{Kind: engine.EntryThought, Seq: 7, PlayerID: "c", Text: "check alice"},
+		{Kind: engine.EntryNightAction, Seq: 7, Timestamp: 2000, PlayerID: "c", Role: "sheriff", TargetID: "a"},
+		{Kind: engine.EntryNightAction, Seq: 7, Timestamp: 2100, PlayerID: "a", Role: "mafia", TargetID: "d"},
+		{Kind: engine.EntryNightAction, Seq: 7, Timestamp: 2200, PlayerID: "b", Role: "doctor", TargetID: "b"},
+		{Kind: engine.EntryPhaseChange, Seq: 7, Phase: "day"},
+		{Kind: engine.EntryChat, Seq: 10, Timestamp: 3000, PlayerID: "c", Text: "alice is mafia"},
+		{Kind: engine.EntryPhaseChange, Seq: 11, Phase: "voting"},
+		{Kind: engine.EntryVote, Seq: 12, Timestamp: 4000, PlayerID: "c", TargetID: "a"},
+		{Kind: engine.EntryVote, Seq: 12, Timestamp: 4100, PlayerID: "a", TargetID: "c"},
+		{Kind: engine.EntryVote, Seq: 12, Timestamp: 4200, PlayerID: "c", TargetID: "b"}, // changed their mind
+		{Kind: engine.EntryVote, Seq: 12, Timestamp: 4300, PlayerID: "b", TargetID: "a"},
+		{Kind: engine.EntryVote, Seq: 12, Timestamp: 4400, PlayerID: "c", TargetID: "a"},
+	}
+	return game, evs, journal
+}
+
+// summary lists the steps: observed event types, "action>target" and "thought".
+func summary(ep *Episode) string {
+	var parts []string
+	for _, step := range ep.Steps {
+		switch step.Kind {
+		case StepObservation:
+			var header events.BaseEvent
+			_ = json.Unmarshal(step.Event, &header)
+			parts = append(parts, header.Type)
+		case StepAction:
+			parts = append(parts, step.Action.Type+">"+step.Action.Target)
+		case StepThought:
+			parts = append(parts, "thought")
+		}
+	}
+	return strings.Join(parts, " ")
+}
+
+func TestEpisodes(t *testing.T) {
+	game, evs, journal := testGame()
+	episodes, err := Episodes(game, evs, journal)
+	if err != nil {
+		t.Fatalf("Episodes failed: %v", err)
+	}
+	if len(episodes) != 5 {
+		t.Fatalf("got %d episodes, want 5", len(episodes))
+	}
+
+	want := map[string]string{
+		// the mafia sees its own role only, chats with the mafia and is lynched
+		"a": "role_assigned phase_changed mafia_chat> night_action>d phase_changed player_eliminated all_chat " +
+			"phase_changed vote>c phase_changed player_eliminated game_ended",
+		// the sheriff's investigation and thought are its own; its chat is an action,
+		// its votes are every ballot it cast, in order
+		"c": "role_assigned phase_changed thought night_action>a phase_changed player_eliminated investigation_result chat> " +
+			"phase_changed vote>a vote>b vote>a phase_changed player_eliminated game_ended",
+		// the villager is killed on night 1 and never acts; ballots are observers only
+		"d": "role_assigned phase_changed phase_changed player_eliminated all_chat " +
+			"phase_changed phase_changed player_eliminated game_ended",
+		// the other mafia player didn't choose the kill
+		"e": "role_assigned phase_changed mafia_chat phase_changed player_eliminated all_chat " +
+			"phase_changed phase_changed player_eliminated game_ended",
+	}
+	for _, ep := range episodes {
+		if steps, ok := want[ep.PlayerID]; ok && steps != summary(ep) {
+			t.Errorf("%s steps:\n got %s\nwant %s", ep.PlayerID, summary(ep), steps)
+		}
+	}
+
+	alice, cleo := episodes[0], episodes[2]
+	if alice.Outcome != (Outcome{Winner: "village", Reward: -1}) {
+		t.Errorf("mafia outcome %+v", alice.Outcome)
+	}
+	if cleo.Outcome != (Outcome{Winner: "village", Won: true, Survived: true, Reward: 1}) {
+		t.Errorf("sheriff outcome %+v", cleo.Outcome)
+	}
+	if cleo.Faction != "village" || cleo.Name != "Cleo" || cleo.Steps[2].Thought != "check alice" {
+		t.Errorf("unexpected sheriff episode %+v", cleo)
+	}
+	if check := cleo.Steps[3]; check.Seq != 7 || check.At != 2000 || check.Round != 1 || check.Phase != "night" {
+		t.Errorf("unexpected night action step %+v", check)
+	}
+}
+
+// Every observation in a real game must pass the live visibility rules.
+func TestExport_NoHiddenInformation(t *testing.T) {
+	cfg, err := config.LoadConfig()
+	if err != nil {
+		t.Fatal(err)
+	}
+	store, err := archive.Open(filepath.Join(t.TempDir(), "archive.db"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	for seed := int64(1); seed <= 5; seed++ {
+		result, err := sim.Run(cfg, sim.Game{Seed: seed, Players: 7, Strategies: []string{"random"}})
+		if err != nil {
+			t.Fatal(err)
+		}
+		game := archive.NewGame(result.State, result.Rules, result.Events)
+		if err := store.Put(game, result.Events); err != nil {
+			t.Fatal(err)
+		}
+		if seed == 5 {
+			continue // archived without a journal: no actions to export
+		}
+		if err := store.PutJournal(game.ID, result.Journal); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	var out bytes.Buffer
+	n, err := Export(&out, store, archive.Query{}, Filter{})
+	if err != nil {
+		t.Fatalf("Export failed: %v", err)
+	}
+	if n != 28 {
+		t.Fatalf("exported %d episodes, want 28", n)
+	}
+
+	actions := make(map[string]int) // game -> action steps exported
+	decoder := json.NewDecoder(&out)
+	for decoder.More() {
+		var ep Episode
+		if err := decoder.Decode(&ep); err != nil {
+			t.Fatal(err)
+		}
+		viewer := events.Viewer{PlayerID: ep.PlayerID, Faction: ep.Faction}
+		for _, step := range ep.Steps {
+			if step.Kind == StepAction {
+				actions[ep.GameID]++
+			}
+			if step.Kind != StepObservation {
+				continue
+			}
+			ev, err := events.DeserializeEngineEvent(step.Event)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if !viewer.CanSee(ev) {
+				t.Fatalf("%s %s observed hidden event %s", ep.GameID, ep.PlayerID, step.Event)
+			}
+		}
+	}
+
+	// every action the engine took is in exactly one episode
+	for id, n := range actions {
+		journal, err := store.Journal(id)
+		if err != nil {
+			t.Fatal(err)
+		}
+		entries, _ := journal.Entries()
+		taken := 0
+		for _, entry := range entries {
+			if action(entry) != nil {
+				taken++
+			}
+		}
+		if n != taken {
+			t.Errorf("%s: exported %d actions, the engine took %d", id, n, taken)
+		}
+	}
+
+	out.Reset()
+	if _, err := Export(&out, store, archive.Query{}, Filter{Faction: "mafia", WonOnly: true}); err != nil {
+		t.Fatal(err)
+	}
+	for _, line := range strings.Split(strings.TrimSpace(out.String()), "\n") {
+		var ep Episode
+		if line != "" && (json.Unmarshal([]byte(line), &ep) != nil || ep.Faction != "mafia" || !ep.Outcome.Won) {
+			t.Errorf("filter kept %.80s", line)
+		}
+	}
+}
@@ -0,0 +1,139 @@
+package transcript
+
+import (
+	"fmt"
+	"html/template"
+	"io"
+	"strings"
+)
+
+// Output formats
+const (
+	FormatMarkdown = "markdown"
+	FormatHTML     = "html"
+)
+
+// Extension returns the file extension for a format ("md", "html").
+func Extension(format string) string {
+	if format == FormatHTML {
+		return "html"
+	}
+	return "md"
+}
+
+// Render writes the document in the given format.
+func Render(w io.Writer, doc *Document, format string) error {
+	switch format {
+	case FormatMarkdown:
+		return renderMarkdown(w, doc)
+	case FormatHTML:
+		return htmlTemplate.Execute(w, doc)
+	default:
+		return fmt.Errorf("unknown format %q (want %s or %s)", format, FormatMarkdown, FormatHTML)
+	}
+}
+
+// Title is the heading of the transcript.
+func (d *Document) Title() string {
+	if d.View == ViewOmniscient {
+		return fmt.Sprintf("Game %s (omniscient view)", d.GameID)
+	}
+	return fmt.Sprintf("Game %s (as seen by %s)", d.GameID, d.View)
+}
+
+func renderMarkdown(w io.Writer, doc *Document) error {
+	var sb strings.Builder
+	fmt.Fprintf(&sb, "# %s\n", doc.Title())
+	for _, section := range doc.Sections {
+		fmt.Fprintf(&sb, "\n## %s\n\n", section.Title)
+		if len(section.Lines) == 0 {
+			sb.WriteString("_Nothing happened._\n")
+		}
+		for _, line := range section.Lines {
+			sb.WriteString("- " + markdownLine(line) + "\n")
+		}
+	}
+
+	if len(doc.Reveal) > 0 {
+		sb.WriteString("\n## Final reveal\n\n")
+		fmt.Fprintf(&sb, "Winner: **%s**\n\n", doc.Winner)
+		sb.WriteString("| Seat | Player | Role | Trait | Survived |\n")
+		sb.WriteString("|---|---|---|---|---|\n")
+		for _, player := range doc.Reveal {
+			role := player.Role
+			if player.Pinned {
+				role += " (set up)"
+			}
+			fmt.Fprintf(&sb, "| %d | %s | %s | %s | %s |\n",
+				player.Seat, player.Name, role, player.Trait, yesNo(player.Alive))
+		}
+	}
+
+	_, err := io.WriteString(w, sb.String())
+	return err
+}
+
+func markdownLine(line Line) string {
+	text := oneLine(line.Text)
+	switch line.Kind {
+	case LineChat:
+		return fmt.Sprintf("**%s:** %s", line.Speaker, text)
+	case LineMafiaChat:
+		return fmt.Sprintf("**%s** _(mafia chat)_: %s", line.Speaker, text)
+	case LinePrivate:
+		return "_" + text + "_"
+	case LineThought:
+		if line.Then != "" {
+			return fmt.Sprintf("**%s** _(thinks)_: %s _→ %s_", line.Speaker, text, line.Then)
+		}
+		return fmt.Sprintf("**%s** _(thinks)_: %s", line.Speaker, text)
+	default:
+		return text
+	}
+}
+
+// oneLine keeps a multi-line chat message inside its list item.
+func oneLine(text string) string {
+	return strings.Join(strings.Fields(text), " ")
+}
+
+func yesNo(b bool) string {
+	if b {
+		return "yes"
+	}
+	return "no"
+}
+
+var htmlTemplate = template.Must(template.New("transcript").Funcs(template.FuncMap{"yesNo": yesNo}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>{{.Title}}</title>
+<style>
+body { font-family: sans-serif; max-width: 50em; margin: 2em auto; line-height: 1.5; }
+ul { list-style: none; padding-left: 0; }
+li { padding: 0.1em 0.4em; }
+.mafia_chat { background: #fbe9e9; }
+.private { color: #555; font-style: italic; }
+.vote { color: #345; }
+.thought { color: #555; background: #f4f4f4; }
+table { border-collapse: collapse; }
+td, th { border: 1px solid #ccc; padding: 0.2em 0.6em; text-align: left; }
+</style>
+</head>
+<body>
+<h1>{{.Title}}</h1>
+{{range .Sections}}<h2>{{.Title}}</h2>
+<ul>
+{{range .Lines}}<li class="{{.Kind}}">{{if .Speaker}}<b>{{.Speaker}}{{if eq .Kind "mafia_chat"}} (mafia chat){{end}}{{if eq .Kind "thought"}} (thinks){{end}}:</b> {{end}}{{.Text}}{{if .Then}} <i>→ {{.Then}}</i>{{end}}</li>
+{{else}}<li><i>Nothing happened.</i></li>
+{{end}}</ul>
+{{end}}{{if .Reveal}}<h2>Final reveal</h2>
+<p>Winner: <b>{{.Winner}}</b></p>
+<table>
+<tr><th>Seat</th><th>Player</th><th>Role</th><th>Trait</th><th>Survived</th></tr>
+{{range .Reveal}}<tr><td>{{.Seat}}</td><td>{{.Name}}</td><td>{{.Role}}{{if .Pinned}} (set up){{end}}</td><td>{{.Trait}}</td><td>{{yesNo .Alive}}</td></tr>
+{{end}}</table>
+{{end}}</body>
+</html>
+`))
@@ -0,0 +1,423 @@
+// Package transcript turns an engine event stream into a readable game
+// transcript: rounds, phases, chat, votes, deaths and the final reveal.
+//
+// A transcript is written from one point of view. The omniscient view shows
+// every event, including mafia chat and the night actions revealed at game
+// end; a player's view only shows what that player could see, by the same
+// events.Viewer rules the engine uses for resyncs (see cmd/transcript).
+package transcript
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"strings"
+
+	"mafia-engine/internal/domain"
+	"mafia-engine/internal/events"
+	"mafia-engine/internal/replay"
+)
+
+// ViewOmniscient names the view that sees everything.
+const ViewOmniscient = "omniscient"
+
+// Line kinds
+const (
+	LineEvent     = "event"      // public game event (phase, death, result)
+	LineChat      = "chat"       // public chat message
+	LineMafiaChat = "mafia_chat" // mafia-only chat message
+	LineVote      = "vote"       // one ballot
+	LinePrivate   = "private"    // something only the viewer (or an observer) knows
+	LineThought   = "thought"    // a player's reasoning (observers only)
+)
+
+// Document is a transcript ready to render.
+type Document struct {
+	GameID   string
+	View     string // ViewOmniscient or the player's name
+	Sections []Section
+	Winner   string   // empty if the game didn't end
+	Reveal   []Reveal // final roles, seat order
+}
+
+// Section is one phase of the game (or the setup before it).
+type Section struct {
+	Title string
+	Lines []Line
+}
+
+// Line is one thing that happened. Speaker is set for chat and thoughts.
+type Line struct {
+	Kind    string
+	Speaker string
+	Text    string
+	Then    string // thoughts: the action the thought preceded, if any
+}
+
+// Reveal is one player's role, as revealed at game end.
+type Reveal struct {
+	Seat   int
+	Name   string
+	Role   string
+	Trait  string
+	Alive  bool
+	Pinned bool
+}
+
+// Read decodes engine events from JSONL: one event per line (e.g. a dump of
+// the engine events topic) or a replay recording, of which the messages the
+// engine published are used.
+func Read(r io.Reader) ([]events.Event, error) {
+	scanner := bufio.NewScanner(r)
+	scanner.Buffer(make([]byte, 0, 64*1024), 16*1024*1024)
+
+	var evs []events.Event
+	line := 0
+	for scanner.Scan() {
+		line++
+		data := scanner.Bytes()
+		if len(strings.TrimSpace(string(data))) == 0 {
+			continue
+		}
+
+		var probe struct {
+			Kind  string          `json:"kind"`
+			Value json.RawMessage `json:"value"`
+		}
+		if err := json.Unmarshal(data, &probe); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		if probe.Kind != "" {
+			if probe.Kind != replay.KindOutbound {
+				continue // recording header, inbound actions, bootstrap
+			}
+			data = probe.Value
+		}
+
+		decoded, err := events.DeserializeEngineEvent(data)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		ev, ok := decoded.(events.Event)
+		if !ok {
+			return nil, fmt.Errorf("line %d: unknown event type: %T", line, decoded)
+		}
+		evs = append(evs, ev)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	return evs, nil
+}
+
+// Games returns the game IDs in the events, in order of appearance.
+func Games(evs []events.Event) []string {
+	var ids []string
+	seen := make(map[string]bool)
+	for _, ev := range evs {
+		id := ev.Header().GameID
+		if !seen[id] {
+			seen[id] = true
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// ForGame returns the events of one game.
+func ForGame(evs []events.Event, gameID string) []events.Event {
+	var game []events.Event
+	for _, ev := range evs {
+		if ev.Header().GameID == gameID {
+			game = append(game, ev)
+		}
+	}
+	return game
+}
+
+// Players returns the game's players from its first roster, in seat order.
+func Players(evs []events.Event) []events.RosterEntry {
+	for _, ev := range evs {
+		if roster, ok := ev.(*events.Roster); ok {
+			return roster.Players
+		}
+	}
+	return nil
+}
+
+// ViewerFor returns the point of view of the player with this name or ID.
+// The faction comes from the player's own role assignment.
+func ViewerFor(evs []events.Event, player string) (events.Viewer, error) {
+	var id string
+	for _, entry := range Players(evs) {
+		if entry.ID == player || entry.Name == player {
+			id = entry.ID
+			break
+		}
+	}
+	if id == "" {
+		return events.Viewer{}, fmt.Errorf("player %q is not in the game", player)
+	}
+
+	viewer := events.Viewer{PlayerID: id}
+	for _, ev := range evs {
+		if assigned, ok := ev.(*events.RoleAssigned); ok && assigned.PlayerID == id {
+			role, _ := domain.ParseRole(assigned.Role)
+			viewer.Faction = role.Faction().String()
+		}
+	}
+	return viewer, nil
+}
+
+// Build writes the transcript of one game as seen by the viewer.
+func Build(evs []events.Event, viewer events.Viewer) *Document {
+	b := &builder{
+		viewer: viewer,
+		names:  make(map[string]string),
+		nights: make(map[int]events.NightReveal),
+		then:   make(map[int64]*events.ThoughtLinked),
+		doc:    &Document{View: ViewOmniscient},
+	}
+	if len(evs) > 0 {
+		b.doc.GameID = evs[0].Header().GameID
+	}
+
+	// Names are public; the night actions are used once they are revealed,
+	// and a thought is shown with the action it led to
+	for _, ev := range evs {
+		switch e := ev.(type) {
+		case *events.Roster:
+			for _, entry := range e.Players {
+				b.names[entry.ID] = entry.Name
+			}
+		case *events.GameEnded:
+			for _, night := range e.Nights {
+				b.nights[night.Round] = night
+			}
+		case *events.ThoughtLinked:
+			for _, id := range e.ThoughtIDs {
+				b.then[id] = e
+			}
+		}
+	}
+	if !viewer.Omniscient {
+		b.doc.View = b.name(viewer.PlayerID)
+	}
+
+	b.section("Setup")
+	for _, ev := range evs {
+		if viewer.CanSee(ev) {
+			b.add(ev)
+		}
+	}
+	return b.doc
+}
+
+type builder struct {
+	viewer events.Viewer
+	names  map[string]string
+	nights map[int]events.NightReveal
+	then   map[int64]*events.ThoughtLinked // thought ID -> action it preceded
+	doc    *Document
+	roster bool // the opening roster was listed
+}
+
+func (b *builder) add(ev events.Event) {
+	switch e := ev.(type) {
+	case *events.GameStarted:
+		text := fmt.Sprintf("The game starts with %d players.", len(e.Players))
+		if e.Pinned {
+			text += " Some roles were set up in advance."
+		}
+		b.line(LineEvent, text)
+
+	case *events.Roster:
+		if b.roster {
+			return // re-sent after every death
+		}
+		b.roster = true
+		names := make([]string, 0, len(e.Players))
+		for _, entry := range e.Players {
+			names = append(names, entry.Name)
+		}
+		b.line(LineEvent, "Players: "+strings.Join(names, ", ")+".")
+
+	case *events.RoleAssigned:
+		text := fmt.Sprintf("%s role: %s", b.possessive(e.PlayerID), e.Role)
+		if e.Trait != "" {
+			text += fmt.Sprintf(" (%s)", e.Trait)
+		}
+		b.line(LinePrivate, text+".")
+
+	case *events.FactionRevealed:
+		if b.viewer.Omniscient {
+			return // the roles already say it
+		}
+		mates := make([]string, 0, len(e.Members))
+		for _, member := range e.Members {
+			mates = append(mates, member.Name)
+		}
+		b.line(LinePrivate, fmt.Sprintf("Your %s partners: %s.", e.Faction, strings.Join(mates, ", ")))
+
+	case *events.PhaseChanged:
+		if b.viewer.Omniscient && e.OldPhase == domain.PhaseNight.String() {
+			b.nightActions(e.Round)
+		}
+		b.section(sectionTitle(e.NewPhase, e.Round))
+
+	case *events.AllChatMessage:
+		b.chat(LineChat, e.SenderID, e.Message)
+
+	case *events.MafiaChatMessage:
+		b.chat(LineMafiaChat, e.SenderID, e.Message)
+
+	case *events.PlayerThoughts:
+		current := &b.doc.Sections[len(b.doc.Sections)-1]
+		current.Lines = append(current.Lines, Line{
+			Kind: LineThought, Speaker: b.name(e.SenderID), Text: e.Thought, Then: b.actionText(b.then[e.ThoughtID]),
+		})
+
+	case *events.VoteResult:
+		for _, ballot := range e.Votes {
+			b.line(LineVote, fmt.Sprintf("%s votes for %s.", b.name(ballot.VoterID), b.name(ballot.TargetID)))
+		}
+		switch {
+		case len(e.Votes) == 0:
+			b.line(LineEvent, "Nobody voted.")
+		case e.Eliminated == "":
+			b.line(LineEvent, "The vote is tied, nobody is eliminated.")
+		default:
+			b.line(LineEvent, eliminationText(b.name(e.Eliminated), reasonVotedOut))
+		}
+
+	case *events.PlayerEliminated:
+		if e.Reason == reasonVotedOut && b.viewer.Omniscient {
+			return // told with the vote result, which closes the voting phase
+		}
+		b.line(LineEvent, eliminationText(b.name(e.PlayerID), e.Reason))
+
+	case *events.InvestigationResult:
+		b.line(LinePrivate, fmt.Sprintf("%s investigation: %s is %s.",
+			b.possessive(e.PlayerID), b.name(e.TargetID), e.Faction))
+
+	case *events.GameEnded:
+		// the game ends as the next phase begins; that phase never happens
+		if last := len(b.doc.Sections) - 1; len(b.doc.Sections[last].Lines) == 0 {
+			b.doc.Sections = b.doc.Sections[:last]
+		}
+		if b.doc.Sections[len(b.doc.Sections)-1].Title != sectionTitle(domain.PhaseEnded.String(), 0) {
+			b.section(sectionTitle(domain.PhaseEnded.String(), 0))
+		}
+		b.doc.Winner = e.Winner
+		b.line(LineEvent, fmt.Sprintf("The %s wins.", e.Winner))
+		for _, player := range e.Players {
+			b.doc.Reveal = append(b.doc.Reveal, Reveal{
+				Seat:   player.Seat,
+				Name:   player.Name,
+				Role:   player.Role,
+				Trait:  player.Trait,
+				Alive:  player.Alive,
+				Pinned: player.Pinned,
+			})
+		}
+	}
+}
+
+// actionText describes the action a thought led to ("" if none).
+func (b *builder) actionText(linked *events.ThoughtLinked) string {
+	if linked == nil {
+		return ""
+	}
+	switch linked.Action {
+	case events.ActionVote:
+		return "votes for " + b.name(linked.TargetID)
+	case events.ActionNightAction:
+		return "targets " + b.name(linked.TargetID) + " at night"
+	case events.ActionChat:
+		return "speaks"
+	case events.ActionMafiaChat:
+		return "speaks in the mafia chat"
+	default:
+		return linked.Action
+	}
+}
+
+// nightActions lists the secret actions of the night that just ended.
+func (b *builder) nightActions(round int) {
+	night, ok := b.nights[round]
+	if !ok {
+		return
+	}
+	if night.MafiaTarget != "" {
+		b.line(LinePrivate, fmt.Sprintf("The mafia targets %s.", b.name(night.MafiaTarget)))
+	}
+	if night.DoctorTarget != "" {
+		b.line(LinePrivate, fmt.Sprintf("The doctor protects %s.", b.name(night.DoctorTarget)))
+	}
+	if night.SheriffTarget != "" {
+		b.line(LinePrivate, fmt.Sprintf("The sheriff investigates %s.", b.name(night.SheriffTarget)))
+	}
+}
+
+func (b *builder) section(title string) {
+	b.doc.Sections = append(b.doc.Sections, Section{Title: title})
+}
+
+func (b *builder) line(kind, text string) {
+	current := &b.doc.Sections[len(b.doc.Sections)-1]
+	current.Lines = append(current.Lines, Line{Kind: kind, Text: text})
+}
+
+func (b *builder) chat(kind, senderID, message string) {
+	current := &b.doc.Sections[len(b.doc.Sections)-1]
+	current.Lines = append(current.Lines, Line{Kind: kind, Speaker: b.name(senderID), Text: message})
+}
+
+// name returns the player's name, or the ID if the roster didn't list them.
+func (b *builder) name(id string) string {
+	if name, ok := b.names[id]; ok {
+		return name
+	}
+	return id
+}
+
+// possessive is "Your" for the viewer and "Alice's" for anyone else.
+func (b *builder) possessive(id string) string {
+	if !b.viewer.Omniscient && id == b.viewer.PlayerID {
+		return "Your"
+	}
+	return b.name(id) + "'s"
+}
+
+func sectionTitle(phase string, round int) string {
+	switch phase {
+	case domain.PhaseNight.String():
+		return fmt.Sprintf("Night %d", round)
+	case domain.PhaseDay.String():
+		return fmt.Sprintf("Day %d", round)
+	case domain.PhaseVoting.String():
+		return fmt.Sprintf("Vote %d", round)
+	case domain.PhaseEnded.String():
+		return "Game over"
+	default:
+		return fmt.Sprintf("%s %d", phase, round)
+	}
+}
+
+// PlayerEliminated reasons
+const (
+	reasonKilled   = "killed_by_mafia"
+	reasonVotedOut = "voted_out"
+)
+
+func eliminationText(name, reason string) string {
+	switch reason {
+	case reasonKilled:
+		return name + " was killed during the night."
+	case reasonVotedOut:
+		return name + " was voted out."
+	default:
+		return fmt.Sprintf("%s was eliminated (%s).", name, reason)
+	}
+}
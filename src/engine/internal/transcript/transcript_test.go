@@ -0,0 +1,171 @@
+package transcript
+
+import (
+	"bytes"
+	"strings"
+	"testing"
+
+	"mafia-engine/internal/events"
+)
+
+func base(eventType string) events.BaseEvent {
+	return events.BaseEvent{GameID: "g1", Type: eventType}
+}
+
+// testGame: Alice (mafia) kills Dan on night 1, Cleo (sheriff) finds her,
+// the village votes her out (Bob after thinking it over).
+func testGame() []events.Event {
+	roster := []events.RosterEntry{
+		{ID: "p1", Name: "Alice", Seat: 1, Alive: true},
+		{ID: "p2", Name: "Bob", Seat: 2, Alive: true},
+		{ID: "p3", Name: "Cleo", Seat: 3, Alive: true},
+		{ID: "p4", Name: "Dan", Seat: 4, Alive: true},
+	}
+	night := base(events.TypePhaseChanged)
+	night.Round = 1
+	day := base(events.TypePhaseChanged)
+	day.Round = 1
+	voting := base(events.TypePhaseChanged)
+	voting.Round = 1
+
+	return []events.Event{
+		&events.GameStarted{BaseEvent: base(events.TypeGameStarted), Players: []string{"p1", "p2", "p3", "p4"}},
+		&events.Roster{BaseEvent: base(events.TypeRoster), Players: roster},
+		&events.RoleAssigned{BaseEvent: base(events.TypeRoleAssigned), PlayerID: "p1", Role: "mafia"},
+		&events.RoleAssigned{BaseEvent: base(events.TypeRoleAssigned), PlayerID: "p2", Role: "villager"},
+		&events.RoleAssigned{BaseEvent: base(events.TypeRoleAssigned), PlayerID: "p3", Role: "sheriff"},
+		&events.RoleAssigned{BaseEvent: base(events.TypeRoleAssigned), PlayerID: "p4", Role: "villager"},
+		&events.PhaseChanged{BaseEvent: night, OldPhase: "waiting", NewPhase: "night"},
+		&events.MafiaChatMessage{BaseEvent: base(events.TypeMafiaChatMessage), SenderID: "p1", Message: "Dan goes tonight"},
+		&events.PhaseChanged{BaseEvent: day, OldPhase: "night", NewPhase: "day"},
+		&events.PlayerEliminated{BaseEvent: base(events.TypePlayerEliminated), PlayerID: "p4", Reason: "killed_by_mafia"},
+		&events.Roster{BaseEvent: base(events.TypeRoster), Players: roster},
+		&events.InvestigationResult{BaseEvent: base(events.TypeInvestigation), PlayerID: "p3", TargetID: "p1", Faction: "mafia"},
+		&events.AllChatMessage{BaseEvent: base(events.TypeAllChatMessage), SenderID: "p3", Message: "It was <b>Alice</b>"},
+		&events.PhaseChanged{BaseEvent: voting, OldPhase: "day", NewPhase: "voting"},
+		&events.PlayerThoughts{BaseEvent: base(events.TypePlayerThoughts), SenderID: "p2", ThoughtID: 1, Thought: "Cleo sounds sure"},
+		&events.ThoughtLinked{BaseEvent: base(events.TypeThoughtLinked), PlayerID: "p2", ThoughtIDs: []int64{1}, Action: events.ActionVote, TargetID: "p1"},
+		&events.VoteResult{BaseEvent: base(events.TypeVoteResult), Eliminated: "p1", Votes: []events.Ballot{
+			{VoterID: "p2", TargetID: "p1"}, {VoterID: "p3", TargetID: "p1"},
+		}},
+		&events.PhaseChanged{BaseEvent: base(events.TypePhaseChanged), OldPhase: "voting", NewPhase: "ended"},
+		&events.PlayerEliminated{BaseEvent: base(events.TypePlayerEliminated), PlayerID: "p1", Reason: "voted_out"},
+		&events.GameEnded{BaseEvent: base(events.TypeGameEnded), Winner: "village",
+			Players: []events.PlayerReveal{
+				{ID: "p1", Name: "Alice", Seat: 1, Role: "mafia"},
+				{ID: "p2", Name: "Bob", Seat: 2, Role: "villager", Alive: true},
+				{ID: "p3", Name: "Cleo", Seat: 3, Role: "sheriff", Alive: true, Pinned: true},
+				{ID: "p4", Name: "Dan", Seat: 4, Role: "villager"},
+			},
+			Nights: []events.NightReveal{{Round: 1, MafiaTarget: "p4", SheriffTarget: "p1", Killed: "p4"}},
+		},
+	}
+}
+
+func render(t *testing.T, doc *Document, format string) string {
+	t.Helper()
+	var buf bytes.Buffer
+	if err := Render(&buf, doc, format); err != nil {
+		t.Fatalf("Render failed: %v", err)
+	}
+	return buf.String()
+}
+
+func TestBuild_Omniscient(t *testing.T) {
+	out := render(t, Build(testGame(), events.Viewer{Omniscient: true}), FormatMarkdown)
+
+	for _, want := range []string{
+		"# Game g1 (omniscient view)",
+		"## Night 1",
+		"_Alice's role: mafia._",
+		"**Alice** _(mafia chat)_: Dan goes tonight",
+		"_The mafia targets Dan._",
+		"_The sheriff investigates Alice._",
+		"Dan was killed during the night.",
+		"Bob votes for Alice.",
+		"**Bob** _(thinks)_: Cleo sounds sure _→ votes for Alice_",
+		"Alice was voted out.",
+		"| 3 | Cleo | sheriff (set up) |  | yes |",
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	if strings.Count(out, "Players: ") != 1 {
+		t.Error("the roster should only be listed once")
+	}
+}
+
+func TestBuild_PlayerView(t *testing.T) {
+	evs := testGame()
+	viewer, err := ViewerFor(evs, "Bob")
+	if err != nil {
+		t.Fatalf("ViewerFor failed: %v", err)
+	}
+	if viewer.PlayerID != "p2" || viewer.Faction != "village" {
+		t.Fatalf("unexpected viewer %+v", viewer)
+	}
+
+	out := render(t, Build(evs, viewer), FormatMarkdown)
+	for _, want := range []string{"(as seen by Bob)", "_Your role: villager._", "Alice was voted out.", "Winner: **village**"} {
+		if !strings.Contains(out, want) {
+			t.Errorf("missing %q in:\n%s", want, out)
+		}
+	}
+	for _, hidden := range []string{"Dan goes tonight", "Alice's role", "The mafia targets", "investigation", "Cleo sounds sure", "votes for"} {
+		if strings.Contains(out, hidden) {
+			t.Errorf("Bob should not see %q:\n%s", hidden, out)
+		}
+	}
+
+	// the sheriff sees their own result
+	sheriff, _ := ViewerFor(evs, "p3")
+	if out := render(t, Build(evs, sheriff), FormatMarkdown); !strings.Contains(out, "_Your investigation: Alice is mafia._") {
+		t.Errorf("sheriff should see the investigation:\n%s", out)
+	}
+
+	if _, err := ViewerFor(evs, "Zed"); err == nil {
+		t.Error("expected error for unknown player")
+	}
+}
+
+func TestRender_HTMLEscapesChat(t *testing.T) {
+	out := render(t, Build(testGame(), events.Viewer{Omniscient: true}), FormatHTML)
+	if strings.Contains(out, "<b>Alice</b>") {
+		t.Error("chat must be escaped")
+	}
+	if !strings.Contains(out, "It was &lt;b&gt;Alice&lt;/b&gt;") {
+		t.Errorf("expected escaped chat in:\n%s", out)
+	}
+	if err := Render(&bytes.Buffer{}, &Document{}, "pdf"); err == nil {
+		t.Error("expected error for unknown format")
+	}
+}
+
+func TestRead_EventsAndRecordings(t *testing.T) {
+	input := strings.Join([]string{
+		`{"kind":"header","at_ms":0,"header":{"version":1}}`,
+		`{"kind":"in","at_ms":5,"value":{"type":"vote_submitted","voter":"p1","target":"p2"}}`,
+		`{"kind":"out","at_ms":6,"value":{"game_id":"g1","type":"game_started","players":["p1"]}}`,
+		``,
+		`{"game_id":"g2","type":"all_chat","sender":"p1","message":"hi"}`,
+	}, "\n")
+
+	evs, err := Read(strings.NewReader(input))
+	if err != nil {
+		t.Fatalf("Read failed: %v", err)
+	}
+	if len(evs) != 2 {
+		t.Fatalf("expected 2 events, got %d", len(evs))
+	}
+	if games := Games(evs); len(games) != 2 || games[0] != "g1" {
+		t.Errorf("unexpected games %v", games)
+	}
+	if got := ForGame(evs, "g2"); len(got) != 1 {
+		t.Errorf("expected 1 event for g2, got %d", len(got))
+	}
+
+	if _, err := Read(strings.NewReader(`{"type":"nope"}`)); err == nil {
+		t.Error("expected error for unknown event type")
+	}
+}